@@ -0,0 +1,266 @@
+// Package airlock models the prop's airlock door. The door cycles
+// Sealed → Depressurizing → Open → Pressurizing → Sealed when its button is
+// pressed, but only while enough batteries are powered to run the pumps.
+package airlock
+
+import (
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// State represents the airlock door state
+type State int
+
+const (
+	Sealed State = iota
+	Depressurizing
+	Open
+	Pressurizing
+)
+
+// String returns a string representation of the State
+func (s State) String() string {
+	switch s {
+	case Sealed:
+		return "Sealed"
+	case Depressurizing:
+		return "Depressurizing"
+	case Open:
+		return "Open"
+	case Pressurizing:
+		return "Pressurizing"
+	default:
+		return "Unknown"
+	}
+}
+
+// Config holds configuration parameters for the airlock
+type Config struct {
+	DepressurizeDuration time.Duration // time from Sealed to Open
+	PressurizeDuration   time.Duration // time from Open to Sealed
+	OpenDuration         time.Duration // time the door stays open before closing itself, 0 to wait for the button
+	MinPoweredBatteries  int           // batteries that must be powered to cycle the door
+	Clock                battery.Clock // time source, battery.SimulationClock if nil so door timing follows the time scale
+}
+
+// DefaultConfig returns a configuration with sensible defaults
+func DefaultConfig() Config {
+	return Config{
+		DepressurizeDuration: 10 * time.Second,
+		PressurizeDuration:   10 * time.Second,
+		OpenDuration:         30 * time.Second,
+		MinPoweredBatteries:  3,
+	}
+}
+
+// Info holds the current airlock properties
+type Info struct {
+	State            State
+	Progress         float64 // 0.0 to 1.0 through a timed state, 0 when Sealed
+	PoweredBatteries int
+	Powered          bool // Enough batteries are powered to cycle the door
+}
+
+// AirLock is the airlock state machine
+type AirLock struct {
+	mu     sync.RWMutex
+	config Config
+	state  State
+
+	// Inputs
+	buttonPressed    bool
+	buttonRequest    bool // Latched on a button press, consumed by the state machine
+	poweredBatteries int
+
+	// State timing
+	stateStartTime time.Time
+
+	// Called after each state change, outside the lock
+	onTransition func(from, to State)
+
+	// Ticker for updates
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewAirLock creates a sealed airlock with the specified configuration
+func NewAirLock(config Config) *AirLock {
+	if config.DepressurizeDuration < 0 {
+		config.DepressurizeDuration = 0
+	}
+	if config.PressurizeDuration < 0 {
+		config.PressurizeDuration = 0
+	}
+	if config.OpenDuration < 0 {
+		config.OpenDuration = 0
+	}
+	if config.Clock == nil {
+		config.Clock = battery.SimulationClock
+	}
+
+	a := &AirLock{
+		config:         config,
+		state:          Sealed,
+		stateStartTime: config.Clock.Now(),
+		stopTicker:     make(chan struct{}),
+	}
+	a.startTicker()
+	return a
+}
+
+// SetButton sets the door button input; each press requests the next door movement
+func (a *AirLock) SetButton(pressed bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if pressed && !a.buttonPressed {
+		a.buttonRequest = true
+	}
+	a.buttonPressed = pressed
+}
+
+// SetPoweredBatteries sets how many batteries are currently able to power the airlock
+func (a *AirLock) SetPoweredBatteries(count int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.poweredBatteries = count
+}
+
+// OnTransition sets a function called after every state change
+func (a *AirLock) OnTransition(fn func(from, to State)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onTransition = fn
+}
+
+// Stop stops the airlock's internal ticker
+func (a *AirLock) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		close(a.stopTicker)
+		if a.ticker != nil {
+			a.ticker.Stop()
+		}
+		a.running = false
+	}
+}
+
+// GetInfo returns a summary of the current airlock state
+func (a *AirLock) GetInfo() Info {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	info := Info{
+		State:            a.state,
+		PoweredBatteries: a.poweredBatteries,
+		Powered:          a.isPowered(),
+	}
+
+	elapsed := a.config.Clock.Now().Sub(a.stateStartTime)
+	switch a.state {
+	case Depressurizing:
+		info.Progress = progress(elapsed, a.config.DepressurizeDuration)
+	case Open:
+		info.Progress = progress(elapsed, a.config.OpenDuration)
+	case Pressurizing:
+		info.Progress = progress(elapsed, a.config.PressurizeDuration)
+	}
+	return info
+}
+
+// progress returns the fraction of duration that has elapsed, capped at 1
+func progress(elapsed, duration time.Duration) float64 {
+	if duration <= 0 {
+		return 1
+	}
+	return min(float64(elapsed)/float64(duration), 1)
+}
+
+// isPowered reports whether enough batteries are powered (must be called with mutex locked)
+func (a *AirLock) isPowered() bool {
+	return a.poweredBatteries >= a.config.MinPoweredBatteries
+}
+
+// setState sets the state and restarts the state timer (must be called with mutex locked)
+func (a *AirLock) setState(newState State) (from State, changed bool) {
+	from = a.state
+	if from == newState {
+		return from, false
+	}
+	a.state = newState
+	a.stateStartTime = a.config.Clock.Now()
+	return from, true
+}
+
+// startTicker begins the internal state machine ticker
+func (a *AirLock) startTicker() {
+	if a.running {
+		return
+	}
+
+	a.running = true
+	a.ticker = time.NewTicker(100 * time.Millisecond)
+
+	go func() {
+		for {
+			select {
+			case <-a.ticker.C:
+				a.updateStateMachine()
+			case <-a.stopTicker:
+				return
+			}
+		}
+	}()
+}
+
+// updateStateMachine implements the state machine logic
+func (a *AirLock) updateStateMachine() {
+	a.mu.Lock()
+
+	elapsed := a.config.Clock.Now().Sub(a.stateStartTime)
+	request := a.buttonRequest
+	a.buttonRequest = false
+
+	next := a.state
+	switch a.state {
+	case Sealed:
+		// A press only opens the door while the pumps have power
+		if request && a.isPowered() {
+			next = Depressurizing
+		}
+
+	case Depressurizing:
+		// Losing power or pressing again aborts and re-seals the door
+		if !a.isPowered() || request {
+			next = Pressurizing
+		} else if elapsed >= a.config.DepressurizeDuration {
+			next = Open
+		}
+
+	case Open:
+		timedOut := a.config.OpenDuration > 0 && elapsed >= a.config.OpenDuration
+		if request || timedOut || !a.isPowered() {
+			next = Pressurizing
+		}
+
+	case Pressurizing:
+		// Pressurizing always completes so the door can never be left unsealed
+		if elapsed >= a.config.PressurizeDuration {
+			next = Sealed
+		}
+	}
+
+	from, changed := a.setState(next)
+	to := a.state
+	onTransition := a.onTransition
+	a.mu.Unlock()
+
+	if changed && onTransition != nil {
+		onTransition(from, to)
+	}
+}
@@ -0,0 +1,206 @@
+// Package audio plays tone sequences on a buzzer in response to battery events
+package audio
+
+import (
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Note is a single tone in a sequence, a zero Frequency is a rest
+type Note struct {
+	Frequency uint32 // Hz
+	Duration  time.Duration
+}
+
+// Sequence is a series of notes played back to back
+type Sequence []Note
+
+// Config holds volume, enable, and the sequences played for battery events
+type Config struct {
+	Enabled       bool
+	Volume        uint8    // 0-100
+	Disconnecting Sequence // A battery started disconnecting
+	Dead          Sequence // A battery died
+	AllCharged    Sequence // Every battery is charged
+}
+
+// DefaultConfig returns an enabled configuration with a set of alarm sequences
+func DefaultConfig() Config {
+	return Config{
+		Enabled: true,
+		Volume:  50,
+		Disconnecting: Sequence{
+			{Frequency: 880, Duration: 100 * time.Millisecond},
+			{Frequency: 0, Duration: 50 * time.Millisecond},
+			{Frequency: 660, Duration: 150 * time.Millisecond},
+		},
+		Dead: Sequence{
+			{Frequency: 440, Duration: 300 * time.Millisecond},
+			{Frequency: 0, Duration: 100 * time.Millisecond},
+			{Frequency: 440, Duration: 300 * time.Millisecond},
+			{Frequency: 0, Duration: 100 * time.Millisecond},
+			{Frequency: 220, Duration: 800 * time.Millisecond},
+		},
+		AllCharged: Sequence{
+			{Frequency: 523, Duration: 120 * time.Millisecond},
+			{Frequency: 659, Duration: 120 * time.Millisecond},
+			{Frequency: 784, Duration: 120 * time.Millisecond},
+			{Frequency: 1047, Duration: 300 * time.Millisecond},
+		},
+	}
+}
+
+// Player plays sequences on a ToneGenerator from its own goroutine
+type Player struct {
+	mu     sync.Mutex
+	tone   peripheral.ToneGenerator
+	config Config
+	queue  chan Sequence
+
+	allCharged bool // Whether the watched batteries were all charged at the last transition
+
+	stop    chan struct{}
+	running bool
+}
+
+// NewPlayer creates a player on tone and starts its playback goroutine
+func NewPlayer(tone peripheral.ToneGenerator, config Config) *Player {
+	if config.Volume > 100 {
+		config.Volume = 100
+	}
+
+	p := &Player{
+		tone:   tone,
+		config: config,
+		queue:  make(chan Sequence, 4),
+		stop:   make(chan struct{}),
+	}
+	p.start()
+	return p
+}
+
+// SetEnabled turns sound on or off; queued sequences are skipped while disabled
+func (p *Player) SetEnabled(enabled bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config.Enabled = enabled
+}
+
+// SetVolume sets the playback volume (0-100)
+func (p *Player) SetVolume(volume uint8) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.config.Volume = min(volume, 100)
+}
+
+// Play queues a sequence without blocking; it is dropped if the queue is full
+func (p *Player) Play(seq Sequence) {
+	if len(seq) == 0 {
+		return
+	}
+	select {
+	case p.queue <- seq:
+	default:
+	}
+}
+
+// Watch plays the configured sequences on state transitions of the batteries
+func (p *Player) Watch(batteries []*battery.Battery) {
+	for _, bat := range batteries {
+		bat.OnTransition(func(t battery.StateTransition) {
+			p.handleTransition(t, batteries)
+		})
+	}
+}
+
+// Stop silences the buzzer and stops the playback goroutine
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.running {
+		close(p.stop)
+		p.running = false
+	}
+}
+
+// handleTransition maps a battery transition to a sequence
+func (p *Player) handleTransition(t battery.StateTransition, batteries []*battery.Battery) {
+	p.mu.Lock()
+	config := p.config
+	p.mu.Unlock()
+
+	switch t.To {
+	case battery.Disconnecting:
+		p.Play(config.Disconnecting)
+	case battery.Dead:
+		p.Play(config.Dead)
+	}
+
+	// All-charged fires once when the last battery becomes charged
+	allCharged := true
+	for _, bat := range batteries {
+		if bat.GetInfo().State != battery.Charged {
+			allCharged = false
+			break
+		}
+	}
+
+	p.mu.Lock()
+	becameCharged := allCharged && !p.allCharged
+	p.allCharged = allCharged
+	p.mu.Unlock()
+
+	if becameCharged {
+		p.Play(config.AllCharged)
+	}
+}
+
+// start launches the playback goroutine
+func (p *Player) start() {
+	p.running = true
+
+	go func() {
+		defer p.tone.Off()
+
+		for {
+			select {
+			case <-p.stop:
+				return
+			case seq := <-p.queue:
+				if !p.playSequence(seq) {
+					return
+				}
+			}
+		}
+	}()
+}
+
+// playSequence plays each note in turn, returning false if the player was stopped
+func (p *Player) playSequence(seq Sequence) bool {
+	for _, note := range seq {
+		p.mu.Lock()
+		enabled, volume := p.config.Enabled, p.config.Volume
+		p.mu.Unlock()
+
+		if !enabled {
+			break
+		}
+
+		p.tone.Tone(note.Frequency, volume)
+
+		timer := time.NewTimer(note.Duration)
+		select {
+		case <-p.stop:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+	}
+
+	p.tone.Off()
+	return true
+}
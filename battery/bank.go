@@ -0,0 +1,183 @@
+package battery
+
+import (
+	"sync"
+	"time"
+)
+
+// BankEventKind identifies what changed across a bank of batteries
+type BankEventKind int
+
+const (
+	AliveChanged BankEventKind = iota // The number of batteries that aren't dead changed
+	AllDead                           // The last live battery died
+	AllCharged                        // Every battery reached Charged
+)
+
+// String returns a string representation of the BankEventKind
+func (k BankEventKind) String() string {
+	switch k {
+	case AliveChanged:
+		return "AliveChanged"
+	case AllDead:
+		return "AllDead"
+	case AllCharged:
+		return "AllCharged"
+	default:
+		return "Unknown"
+	}
+}
+
+// BankEvent is a change in the aggregate state of a bank
+type BankEvent struct {
+	Kind        BankEventKind
+	Alive       int     // Batteries that aren't dead after the change
+	TotalCharge float32 // Mean battery level, 0-100
+	At          time.Time
+}
+
+// Bank groups batteries so callers can gate behavior on their aggregate state,
+// e.g. the airlock needing at least three live batteries
+type Bank struct {
+	mu         sync.Mutex
+	batteries  []*Battery
+	alive      int
+	allCharged bool
+	channels   []chan<- BankEvent
+	callbacks  []func(BankEvent)
+}
+
+// NewBank creates a bank over batteries and starts following their transitions
+func NewBank(batteries []*Battery) *Bank {
+	b := &Bank{batteries: batteries}
+	b.alive = b.AliveCount()
+	b.allCharged = b.AllCharged()
+	for _, bat := range batteries {
+		bat.OnTransition(b.handleTransition)
+	}
+	return b
+}
+
+// Batteries returns the batteries in the bank
+func (b *Bank) Batteries() []*Battery {
+	return b.batteries
+}
+
+// Len returns the number of batteries in the bank
+func (b *Bank) Len() int {
+	return len(b.batteries)
+}
+
+// TotalCharge returns the mean battery level across the bank, 0-100
+func (b *Bank) TotalCharge() float32 {
+	if len(b.batteries) == 0 {
+		return 0
+	}
+	var total float32
+	for _, bat := range b.batteries {
+		total += bat.GetInfo().BatteryLevel
+	}
+	return total / float32(len(b.batteries))
+}
+
+// AliveCount returns the number of batteries that aren't dead
+func (b *Bank) AliveCount() int {
+	alive := 0
+	for _, bat := range b.batteries {
+		if bat.GetInfo().State != Dead {
+			alive++
+		}
+	}
+	return alive
+}
+
+// AtLeastAlive returns whether at least n batteries aren't dead
+func (b *Bank) AtLeastAlive(n int) bool {
+	return b.AliveCount() >= n
+}
+
+// AllDead returns whether every battery in the bank is dead
+func (b *Bank) AllDead() bool {
+	return len(b.batteries) > 0 && b.AliveCount() == 0
+}
+
+// AllCharged returns whether every battery in the bank is Charged
+func (b *Bank) AllCharged() bool {
+	if len(b.batteries) == 0 {
+		return false
+	}
+	for _, bat := range b.batteries {
+		if bat.GetInfo().State != Charged {
+			return false
+		}
+	}
+	return true
+}
+
+// Subscribe registers a channel that receives every bank event.
+// Sends never block; events are dropped if the channel is full, so use a buffered channel.
+func (b *Bank) Subscribe(ch chan<- BankEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.channels = append(b.channels, ch)
+}
+
+// Unsubscribe removes a channel previously registered with Subscribe
+func (b *Bank) Unsubscribe(ch chan<- BankEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for i, subscriber := range b.channels {
+		if subscriber == ch {
+			b.channels = append(b.channels[:i], b.channels[i+1:]...)
+			return
+		}
+	}
+}
+
+// OnEvent registers a callback run for every bank event.
+// Callbacks run on the goroutine of the battery that changed and should return quickly.
+func (b *Bank) OnEvent(callback func(BankEvent)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.callbacks = append(b.callbacks, callback)
+}
+
+// handleTransition recomputes the aggregate state after a battery transition
+// and delivers any resulting bank events
+func (b *Bank) handleTransition(t StateTransition) {
+	alive := b.AliveCount()
+	allCharged := b.AllCharged()
+	totalCharge := b.TotalCharge()
+
+	b.mu.Lock()
+	var events []BankEvent
+	if alive != b.alive {
+		events = append(events, BankEvent{Kind: AliveChanged, Alive: alive, TotalCharge: totalCharge, At: t.At})
+		if alive == 0 {
+			events = append(events, BankEvent{Kind: AllDead, Alive: alive, TotalCharge: totalCharge, At: t.At})
+		}
+	}
+	if allCharged && !b.allCharged {
+		events = append(events, BankEvent{Kind: AllCharged, Alive: alive, TotalCharge: totalCharge, At: t.At})
+	}
+	b.alive = alive
+	b.allCharged = allCharged
+	channels := append([]chan<- BankEvent(nil), b.channels...)
+	callbacks := make([]func(BankEvent), len(b.callbacks))
+	copy(callbacks, b.callbacks)
+	b.mu.Unlock()
+
+	for _, event := range events {
+		for _, ch := range channels {
+			select {
+			case ch <- event:
+			default:
+				// Subscriber isn't keeping up, drop the event
+			}
+		}
+		for _, callback := range callbacks {
+			callback(event)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package battery
 
 import (
+	"maps"
+	"math"
 	"sync"
 	"time"
 )
 
-// SystemState represents the five possible battery system states
+// SystemState represents the possible battery system states
 type SystemState int
 
 const (
@@ -14,6 +16,7 @@ const (
 	Draining
 	Dead
 	Charging
+	Idle // Neither draining nor on the charger, the level holds
 )
 
 // String returns a string representation of the SystemState
@@ -29,7 +32,12 @@ func (s SystemState) String() string {
 		return "Dead"
 	case Charging:
 		return "Charging"
+	case Idle:
+		return "Idle"
 	default:
+		if name, ok := customStateName(s); ok {
+			return name
+		}
 		return "Unknown"
 	}
 }
@@ -39,19 +47,70 @@ type BatteryInfo struct {
 	State                          SystemState
 	BatteryLevel                   float32
 	ChargedOverride                bool
+	ForcedDead                     bool // Held Dead by ForceDead
+	Paused                         bool // Frozen by Pause
 	IsDraining                     bool
+	IsCharging                     bool // Charger input, always true without Config.ChargerInput
 	DrainRate                      time.Duration
 	ChargeRate                     time.Duration
 	DisconnectingDuration          time.Duration
 	LastUpdateAt                   time.Time
 	DisconnectingDurationRemaining time.Duration // Only valid when in Disconnecting state
+	MaxCapacity                    float32       // Highest level the battery can charge to, reduced by wear
+	Health                         float32       // MaxCapacity as a fraction of a new battery, 0.0 to 1.0
+	Cycles                         float32       // Full drain cycles so far, partial drains count proportionally
+	TimeToEmpty                    time.Duration // Time to drain to 0% at the current rate and curve, including any disconnect countdown; Forever if it never drains
+	TimeToFull                     time.Duration // Time to charge to MaxCapacity at the charge rate and curve
+	Temperature                    float32       // °C, only modelled with Config.Thermal
+	Heat                           float32       // Temperature from ambient (0.0) to thermal shutdown (1.0), 0 without Config.Thermal
+}
+
+// ETA returns the time left until the battery is empty while it is draining or
+// disconnecting, or full while charging; ok is false in the other states or
+// when it will never get there
+func (i BatteryInfo) ETA() (eta time.Duration, ok bool) {
+	switch i.State {
+	case Draining, Disconnecting:
+		return i.TimeToEmpty, i.TimeToEmpty != Forever
+	case Charging:
+		return i.TimeToFull, true
+	default:
+		return 0, false
+	}
 }
 
 // Config holds configuration parameters for battery creation
 type Config struct {
-	DrainRate             time.Duration // time to fully drain from 100% to 0%
-	ChargeRate            time.Duration // time to fully charge from 0% to 100%
-	DisconnectingDuration time.Duration // time to stay in disconnecting state
+	DrainRate             time.Duration                 // time to fully drain from 100% to 0% (before any curve)
+	ChargeRate            time.Duration                 // time to fully charge from 0% to 100% (before any curve)
+	DisconnectingDuration time.Duration                 // time to stay in disconnecting state
+	DrainCurve            RateCurve                     // optional drain rate multiplier by level, nil for linear
+	ChargeCurve           RateCurve                     // optional charge rate multiplier by level, nil for linear
+	WearPerCycle          float32                       // capacity percentage points lost per full drain cycle, 0 disables wear
+	MinCapacity           float32                       // capacity never wears below this, DefaultMinCapacity if zero
+	OverrideTimeout       time.Duration                 // a held ChargedOverride releases control back to the state machine after this long, 0 holds it while set
+	MinDwell              map[SystemState]time.Duration // minimum time in a state before the draining input can move it on, nil for none
+	InputHysteresis       time.Duration                 // the draining input must hold a new value this long before it is acted on, 0 acts at once
+	ChargerInput          bool                          // charging needs SetIsCharging(true), e.g. a dock switch; otherwise a battery charges whenever it isn't draining
+	Rules                 []Rule                        // transition table, DefaultRules if nil; see WithRules
+	Thermal               ThermalConfig                 // optional heat model and Overheated state, off if zero; see WithThermal
+	Clock                 Clock                         // time source, SimulationClock if nil
+}
+
+// DefaultOverrideTimeout is how long a held reset keeps a battery forced to Charged
+const DefaultOverrideTimeout = 2 * time.Second
+
+// DefaultInputHysteresis filters a connect switch being flicked back and forth
+const DefaultInputHysteresis = 200 * time.Millisecond
+
+// DefaultMinDwell returns dwell times that stop a battery ping-ponging between
+// charging and draining when its connect switch is toggled rapidly
+func DefaultMinDwell() map[SystemState]time.Duration {
+	return map[SystemState]time.Duration{
+		Draining: time.Second,
+		Dead:     time.Second,
+		Charging: time.Second,
+	}
 }
 
 // DefaultBatteryConfig returns a configuration with sensible defaults
@@ -81,23 +140,109 @@ func StandardBatteryConfig() Config {
 	}
 }
 
+// WithDrainSpeed returns a copy of the config that drains factor times as fast,
+// e.g. 2 halves the drain time
+func (c Config) WithDrainSpeed(factor float64) Config {
+	if factor > 0 {
+		c.DrainRate = time.Duration(float64(c.DrainRate) / factor)
+	}
+	return c
+}
+
+// WithChargeSpeed returns a copy of the config that charges factor times as fast
+func (c Config) WithChargeSpeed(factor float64) Config {
+	if factor > 0 {
+		c.ChargeRate = time.Duration(float64(c.ChargeRate) / factor)
+	}
+	return c
+}
+
+// WithSwitchFiltering returns a copy of the config that rides out a connect switch
+// flicked back and forth, with DefaultInputHysteresis and DefaultMinDwell
+func (c Config) WithSwitchFiltering() Config {
+	c.InputHysteresis = DefaultInputHysteresis
+	c.MinDwell = DefaultMinDwell()
+	return c
+}
+
+// WithOverrideTimeout returns a copy of the config whose held ChargedOverride
+// releases control back to the state machine after timeout
+func (c Config) WithOverrideTimeout(timeout time.Duration) Config {
+	c.OverrideTimeout = timeout
+	return c
+}
+
+// WithChargerInput returns a copy of the config whose batteries only charge
+// while SetIsCharging is asserted, resting Idle otherwise
+func (c Config) WithChargerInput() Config {
+	c.ChargerInput = true
+	return c
+}
+
+// KnobMultiplier maps a knob position (0-100) to a rate multiplier,
+// from half speed at 0 through 1 at the middle to double speed at 100
+func KnobMultiplier(percentage int) float32 {
+	return float32(math.Exp2(float64(max(0, min(100, percentage))-50) / 50))
+}
+
+// NewBatteries creates one battery per config, so each slot can behave differently
+func NewBatteries(configs []Config) []*Battery {
+	batteries := make([]*Battery, len(configs))
+	for i, config := range configs {
+		batteries[i] = NewBattery(config)
+	}
+	return batteries
+}
+
 // Battery represents a battery with state machine based on three inputs and time
 type Battery struct {
 	mu                    sync.RWMutex
 	state                 SystemState
 	batteryLevel          float32       // 0-100 percentage
 	chargedOverride       bool          // Input 1
-	isDraining            bool          // Input 2
+	overrideTimeout       time.Duration // Held override auto-clears after this, 0 never
+	overrideSince         time.Time     // When the override was last asserted
+	overrideExpired       bool          // Override timed out and is ignored until the input is released
+	isDraining            bool          // Input 2, after hysteresis
+	isCharging            bool          // Charger input, only used with chargerInput
+	chargerInput          bool          // Charging needs the charger input rather than just a disconnected drain
+	drainInput            bool          // Input 2 as last set
+	drainInputSince       time.Time     // When the draining input last changed
+	inputHysteresis       time.Duration // How long a new draining input must hold
+	minDwell              map[SystemState]time.Duration
+	forcedDead            bool          // Held Dead by ForceDead until a charged override
 	drainRate             time.Duration // Input 3: time to fully drain
+	drainMultiplier       float32       // Live scale on the drain rate, e.g. from a difficulty knob
 	chargeRate            time.Duration // time to fully charge
 	disconnectingDuration time.Duration // time to stay in disconnecting state
+	drainCurve            RateCurve     // nil for linear draining
+	chargeCurve           RateCurve     // nil for linear charging
+	rules                 []Rule        // Transition table
+
+	// Heat
+	thermal     ThermalConfig
+	temperature float32 // °C
+
+	// Wear
+	maxCapacity  float32 // Highest level the battery charges to
+	minCapacity  float32 // Floor for maxCapacity
+	wearPerCycle float32 // Capacity lost per full drain cycle
+	cycles       float32 // Full drain cycles so far
 
 	// State timing
 	lastUpdateAt           time.Time
+	stateSince             time.Time // When the current state was entered
 	disconnectingStartTime time.Time
+	paused                 bool      // Frozen by Pause, levels and state timers don't advance
+	pausedAt               time.Time // When Pause was called
+
+	// Recent state transitions and their subscribers
+	history *History
+	events  eventBus
 
-	// Ticker for updates
-	ticker     *time.Ticker
+	// Time source and ticker for updates
+	clock      Clock
+	ticker     Ticker
 	stopTicker chan struct{}
 	running    bool
 }
@@ -114,16 +259,43 @@ func NewBattery(config Config) *Battery {
 	if config.DisconnectingDuration < 0 {
 		config.DisconnectingDuration = 0
 	}
+	if config.MinCapacity <= 0 {
+		config.MinCapacity = DefaultMinCapacity
+	}
+	if config.Clock == nil {
+		config.Clock = SimulationClock
+	}
+	if config.Rules == nil {
+		config.Rules = DefaultRules()
+	}
+	if config.Thermal.enabled() {
+		config.Rules = append(thermalRules(config.Thermal), config.Rules...)
+	}
 
 	b := &Battery{
 		state:                 Charged,
 		batteryLevel:          100,
 		chargedOverride:       false,
+		overrideTimeout:       max(config.OverrideTimeout, 0),
 		isDraining:            false,
 		drainRate:             config.DrainRate,
+		drainMultiplier:       1,
+		inputHysteresis:       max(config.InputHysteresis, 0),
+		chargerInput:          config.ChargerInput,
+		minDwell:              maps.Clone(config.MinDwell),
 		chargeRate:            config.ChargeRate,
 		disconnectingDuration: config.DisconnectingDuration,
-		lastUpdateAt:          time.Now(),
+		drainCurve:            config.DrainCurve,
+		chargeCurve:           config.ChargeCurve,
+		rules:                 append([]Rule(nil), config.Rules...),
+		maxCapacity:           100,
+		minCapacity:           min(config.MinCapacity, 100),
+		wearPerCycle:          max(config.WearPerCycle, 0),
+		thermal:               config.Thermal,
+		temperature:           config.Thermal.Ambient,
+		lastUpdateAt:          config.Clock.Now(),
+		clock:                 config.Clock,
+		history:               NewHistory(DefaultHistorySize),
 		stopTicker:            make(chan struct{}),
 	}
 	b.startTicker()
@@ -131,24 +303,118 @@ func NewBattery(config Config) *Battery {
 }
 
 // SetChargedOverride sets the charged override input
-// When true, battery level is set to 100 and state transitions to Charged
+// When true, battery level is set to its (possibly worn) capacity, it cools to ambient and state transitions to Charged.
+// With an OverrideTimeout, an override held longer than the timeout releases control back
+// to the state machine until the input is set false again.
 func (b *Battery) SetChargedOverride(override bool) {
+	defer b.events.dispatch()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	b.chargedOverride = override
-	if override {
-		b.batteryLevel = 100.0
-		b.setState(Charged)
+	if !override {
+		// Let the state machine determine the next state on the next tick
+		b.chargedOverride = false
+		b.overrideExpired = false
+		return
 	}
-	// If turning off override, let the state machine determine next state on next tick
+	if b.overrideExpired {
+		return
+	}
+
+	if !b.chargedOverride {
+		b.overrideSince = b.clock.Now()
+	}
+	b.chargedOverride = true
+	b.forcedDead = false
+	b.batteryLevel = b.maxCapacity
+	b.temperature = b.thermal.Ambient
+	b.setState(Charged)
+}
+
+// ForceDead empties the battery and holds it Dead whatever its inputs, e.g. for an
+// emergency abort, until a charged override resets it
+func (b *Battery) ForceDead() {
+	defer b.events.dispatch()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.forcedDead = true
+	b.chargedOverride = false
+	b.batteryLevel = 0
+	b.setState(Dead)
 }
 
-// SetIsDraining sets the draining input
+// SetIsDraining sets the draining input. With an InputHysteresis the state
+// machine only acts on a new value once it has held that long.
 func (b *Battery) SetIsDraining(draining bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	b.isDraining = draining
+
+	if draining != b.drainInput {
+		b.drainInput = draining
+		b.drainInputSince = b.clock.Now()
+	}
+	if b.inputHysteresis == 0 {
+		b.isDraining = draining
+	}
+}
+
+// SetIsCharging sets the charger input, e.g. a dock switch. It only has an effect
+// with Config.ChargerInput; a disconnected battery off the charger rests Idle.
+func (b *Battery) SetIsCharging(charging bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.isCharging = charging
+}
+
+// SetRateMultiplier scales how fast the battery drains while running, e.g. 2 drains twice as fast
+func (b *Battery) SetRateMultiplier(f float32) {
+	if f < 0 {
+		f = 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.drainMultiplier = f
+}
+
+// Pause freezes the battery, e.g. while the game master briefs players: its level stops
+// changing and state timers such as the disconnecting countdown stop running.
+// Inputs are still accepted and take effect after Resume.
+func (b *Battery) Pause() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.paused {
+		return
+	}
+	b.paused = true
+	b.pausedAt = b.clock.Now()
+}
+
+// Resume continues a paused battery from where it was frozen
+func (b *Battery) Resume() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.paused {
+		return
+	}
+	b.paused = false
+
+	// Shift every timer by the time spent paused so none of it counts
+	frozen := b.clock.Now().Sub(b.pausedAt)
+	b.lastUpdateAt = b.lastUpdateAt.Add(frozen)
+	b.stateSince = b.stateSince.Add(frozen)
+	b.disconnectingStartTime = b.disconnectingStartTime.Add(frozen)
+	b.drainInputSince = b.drainInputSince.Add(frozen)
+	b.overrideSince = b.overrideSince.Add(frozen)
+}
+
+// IsPaused returns whether the battery is frozen by Pause
+func (b *Battery) IsPaused() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.paused
 }
 
 // Stop stops the battery's internal ticker and operations
@@ -165,15 +431,25 @@ func (b *Battery) Stop() {
 	}
 }
 
-// setState sets the internal state and updates timing (must be called with mutex locked)
+// setState sets the internal state, updates timing and queues a transition event
+// (must be called with mutex locked)
 func (b *Battery) setState(newState SystemState) {
 	if b.state != newState {
+		transition := StateTransition{
+			From:  b.state,
+			To:    newState,
+			Level: b.batteryLevel,
+			At:    b.clock.Now(),
+		}
+		b.history.Add(transition)
+		b.events.queue(transition)
 		b.state = newState
-		b.lastUpdateAt = time.Now()
+		b.lastUpdateAt = b.clock.Now()
+		b.stateSince = b.lastUpdateAt
 
 		// Special handling for disconnecting state
 		if newState == Disconnecting {
-			b.disconnectingStartTime = time.Now()
+			b.disconnectingStartTime = b.clock.Now()
 		}
 	}
 }
@@ -185,12 +461,12 @@ func (b *Battery) startTicker() {
 	}
 
 	b.running = true
-	b.ticker = time.NewTicker(100 * time.Millisecond) // Update every 100ms
+	b.ticker = b.clock.NewTicker(100 * time.Millisecond) // Update every 100ms
 
 	go func() {
 		for {
 			select {
-			case <-b.ticker.C:
+			case <-b.ticker.C():
 				b.updateStateMachine()
 			case <-b.stopTicker:
 				return
@@ -201,78 +477,74 @@ func (b *Battery) startTicker() {
 
 // updateStateMachine implements the state machine logic
 func (b *Battery) updateStateMachine() {
+	defer b.events.dispatch()
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	now := time.Now()
+	if b.paused {
+		return
+	}
+
+	now := b.clock.Now()
 	deltaMinutes := now.Sub(b.lastUpdateAt).Minutes()
 
-	// Rule 1: ChargedOverride always forces Charged state with 100% battery
+	// Rule 1: ChargedOverride forces Charged state with a full battery until it times out
+	if b.chargedOverride && b.overrideTimeout > 0 && now.Sub(b.overrideSince) >= b.overrideTimeout {
+		b.chargedOverride = false
+		b.overrideExpired = true
+	}
 	if b.chargedOverride {
-		b.batteryLevel = 100.0
+		b.batteryLevel = b.maxCapacity
+		b.temperature = b.thermal.Ambient
 		b.setState(Charged)
+		b.lastUpdateAt = now
 		return
 	}
 
-	// State machine transitions based on current state
-	switch b.state {
-	case Charged:
-		// if in ChargedState and IsDraining == true transition to Disconnecting
-		if b.isDraining {
-			b.setState(Disconnecting)
-		}
+	// A forced Dead ignores the other inputs until the override above clears it
+	if b.forcedDead {
+		b.batteryLevel = 0
+		b.setState(Dead)
+		b.lastUpdateAt = now
+		return
+	}
 
-	case Disconnecting:
-		// if in Disconnecting, after DisconnectingDuration has elapsed then transition to Draining
-		disconnectingElapsed := now.Sub(b.disconnectingStartTime)
-		if disconnectingElapsed >= b.disconnectingDuration {
-			b.setState(Draining)
-		}
+	// Accept a draining input that has held past the hysteresis
+	if b.isDraining != b.drainInput && now.Sub(b.drainInputSince) >= b.inputHysteresis {
+		b.isDraining = b.drainInput
+	}
 
+	// Move the level for the current state, then let the transition table pick the next state
+	var drained float32
+	switch b.state {
 	case Draining:
-		// if in Draining, then reduce BatteryLevel by drainRate
-		drainPercentPerMinute := 100.0 / b.drainRate.Minutes()
-		drainAmount := drainPercentPerMinute * deltaMinutes
-		newLevel := float64(b.batteryLevel) - drainAmount
-
-		if newLevel <= 0 {
-			// if in Draining and BatteryLevel reaches 0 then transition to Dead
-			b.batteryLevel = 0.0
-			b.setState(Dead)
-		} else {
-			b.batteryLevel = float32(newLevel)
-
-			// if in Draining and isDraining is set to false then transition to Charging
-			if !b.isDraining {
-				b.setState(Charging)
-			}
-		}
-
-	case Dead:
-		// Dead state - can only exit via ChargedOverride or if isDraining becomes false
-		if !b.isDraining {
-			b.setState(Charging)
-		}
+		// Reduce BatteryLevel by drainRate, shaped by the drain curve
+		drainPercentPerMinute := 100.0 / b.drainRate.Minutes() * rateMultiplier(b.drainCurve, b.batteryLevel) * float64(b.drainMultiplier)
+		newLevel := max(float64(b.batteryLevel)-drainPercentPerMinute*deltaMinutes, 0)
+		drained = b.batteryLevel - float32(newLevel)
+		b.addWear(drained)
+		b.batteryLevel = float32(newLevel)
 
 	case Charging:
-		// if in Charging, then increment battery level by charge rate
-		chargePercentPerMinute := 100.0 / b.chargeRate.Minutes()
-		chargeAmount := chargePercentPerMinute * deltaMinutes
-		newLevel := float64(b.batteryLevel) + chargeAmount
-
-		if newLevel >= 100 {
-			// if in Charging and battery level reaches 100 then transition to Charged
-			b.batteryLevel = 100.0
-			b.setState(Charged)
-		} else {
-			b.batteryLevel = float32(newLevel)
-
-			// If isDraining becomes true while charging, transition to Disconnecting
-			if b.isDraining {
-				b.setState(Disconnecting)
-			}
-		}
+		// Increment battery level by charge rate, shaped by the charge curve
+		chargePercentPerMinute := 100.0 / b.chargeRate.Minutes() * rateMultiplier(b.chargeCurve, b.batteryLevel)
+		newLevel := float64(b.batteryLevel) + chargePercentPerMinute*deltaMinutes
+		b.batteryLevel = float32(min(newLevel, float64(b.maxCapacity)))
 	}
+	b.updateTemperature(drained, deltaMinutes)
+
+	inState := now.Sub(b.stateSince)
+	b.setState(nextState(b.rules, Snapshot{
+		State:                 b.state,
+		Level:                 b.batteryLevel,
+		MaxCapacity:           b.maxCapacity,
+		Draining:              b.isDraining,
+		Charging:              b.charging(),
+		InState:               inState,
+		Dwelled:               inState >= b.minDwell[b.state],
+		DisconnectingDuration: b.disconnectingDuration,
+		Temperature:           b.temperature,
+	}))
 
 	b.lastUpdateAt = now
 }
@@ -286,20 +558,133 @@ func (b *Battery) GetInfo() BatteryInfo {
 		State:                 b.state,
 		BatteryLevel:          b.batteryLevel,
 		ChargedOverride:       b.chargedOverride,
+		ForcedDead:            b.forcedDead,
+		Paused:                b.paused,
 		IsDraining:            b.isDraining,
+		IsCharging:            b.charging(),
 		DrainRate:             b.drainRate,
 		ChargeRate:            b.chargeRate,
 		DisconnectingDuration: b.disconnectingDuration,
 		LastUpdateAt:          b.lastUpdateAt,
+		MaxCapacity:           b.maxCapacity,
+		Health:                b.maxCapacity / 100,
+		Cycles:                b.cycles,
+		Temperature:           b.temperature,
+		Heat:                  b.thermal.heat(b.temperature),
 	}
 
 	// Add state-specific information
 	if b.state == Disconnecting {
-		elapsed := time.Since(b.disconnectingStartTime)
+		// The countdown stands still while paused
+		now := b.clock.Now()
+		if b.paused {
+			now = b.pausedAt
+		}
+		elapsed := now.Sub(b.disconnectingStartTime)
 		remaining := b.disconnectingDuration - elapsed
 		remaining = max(remaining, 0)
 		info.DisconnectingDurationRemaining = remaining
 	}
 
+	// Times are in the battery clock's time, so they follow the time scale
+	info.TimeToEmpty = levelTime(b.batteryLevel, 0, b.drainRate, b.drainCurve, float64(b.drainMultiplier))
+	if b.state == Disconnecting && info.TimeToEmpty != Forever {
+		info.TimeToEmpty += info.DisconnectingDurationRemaining
+	}
+	info.TimeToFull = levelTime(b.batteryLevel, max(b.maxCapacity, b.batteryLevel), b.chargeRate, b.chargeCurve, 1)
+
 	return info
 }
+
+// History returns the recorded state transitions, oldest first
+func (b *Battery) History() []StateTransition {
+	return b.history.Entries()
+}
+
+// Restore sets the battery level and state directly, e.g. when resuming a show
+// after a power loss. Timers restart from now.
+func (b *Battery) Restore(level float32, state SystemState) {
+	defer b.events.dispatch()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.batteryLevel = min(max(level, 0), b.maxCapacity)
+	b.setState(state)
+
+	now := b.clock.Now()
+	b.lastUpdateAt = now
+	b.stateSince = now
+	if state == Disconnecting {
+		b.disconnectingStartTime = now
+	}
+}
+
+// SetLevel sets the battery level (clamped to 0-capacity) for scripted scenarios,
+// transitioning state where the new level requires it:
+//   - reaching 0 while Disconnecting, Draining or Idle goes Dead
+//   - reaching capacity while Charging or Idle goes Charged
+//   - rising above 0 while Dead resumes Draining, or Charging (Idle off the charger)
+//   - dropping below capacity while Charged goes Disconnecting, or Charging (Idle off the charger)
+//
+// While ChargedOverride is set the next tick forces the level back to capacity.
+func (b *Battery) SetLevel(pct float32) {
+	defer b.events.dispatch()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.applyLevel(pct)
+}
+
+// AddCharge raises the battery level by pct percentage points
+func (b *Battery) AddCharge(pct float32) {
+	defer b.events.dispatch()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.applyLevel(b.batteryLevel + pct)
+}
+
+// Drain lowers the battery level by pct percentage points
+func (b *Battery) Drain(pct float32) {
+	defer b.events.dispatch()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.applyLevel(b.batteryLevel - pct)
+}
+
+// applyLevel sets the level and makes any transition it implies (must be called with mutex locked)
+func (b *Battery) applyLevel(pct float32) {
+	b.batteryLevel = min(max(pct, 0), b.maxCapacity)
+
+	switch {
+	case b.batteryLevel <= 0 && (b.state == Disconnecting || b.state == Draining || b.state == Idle):
+		b.setState(Dead)
+	case b.batteryLevel >= b.maxCapacity && (b.state == Charging || b.state == Idle):
+		b.setState(Charged)
+	case b.batteryLevel > 0 && b.state == Dead:
+		if b.isDraining {
+			b.setState(Draining)
+		} else {
+			b.setState(b.restingState())
+		}
+	case b.batteryLevel < b.maxCapacity && b.state == Charged:
+		if b.isDraining {
+			b.setState(Disconnecting)
+		} else {
+			b.setState(b.restingState())
+		}
+	}
+}
+
+// charging returns whether the battery is on the charger, which is always the
+// case without a charger input (must be called with mutex locked)
+func (b *Battery) charging() bool {
+	return !b.chargerInput || b.isCharging
+}
+
+// restingState is where a battery that isn't draining goes: Charging when on the
+// charger, Idle otherwise (must be called with mutex locked)
+func (b *Battery) restingState() SystemState {
+	if b.charging() {
+		return Charging
+	}
+	return Idle
+}
@@ -0,0 +1,580 @@
+package battery
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testTick is how far step advances the clock before each state machine update
+const testTick = 100 * time.Millisecond
+
+// testConfig drains and charges 1% a second, disconnects in a second and acts on
+// every input at once, so tests only exercise the behavior they set up
+func testConfig() Config {
+	return Config{
+		DrainRate:             100 * time.Second,
+		ChargeRate:            100 * time.Second,
+		DisconnectingDuration: time.Second,
+	}
+}
+
+// newTestBattery creates a battery on a fake clock. Its own ticker is stopped so
+// the state machine only runs from step, making every test deterministic.
+func newTestBattery(t *testing.T, config Config) (*Battery, *FakeClock) {
+	t.Helper()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	config.Clock = clock
+	b := NewBattery(config)
+	b.Stop()
+	return b, clock
+}
+
+// step advances the clock by d, running the state machine every testTick
+func step(b *Battery, clock *FakeClock, d time.Duration) {
+	for elapsed := time.Duration(0); elapsed < d; elapsed += testTick {
+		clock.Advance(testTick)
+		b.updateStateMachine()
+	}
+}
+
+// expectState fails the test unless the battery is in want
+func expectState(t *testing.T, b *Battery, want SystemState) {
+	t.Helper()
+	if got := b.GetInfo().State; got != want {
+		t.Fatalf("state = %v, want %v", got, want)
+	}
+}
+
+// expectLevel fails the test unless the battery level is within 0.01 of want
+func expectLevel(t *testing.T, b *Battery, want float32) {
+	t.Helper()
+	if got := b.GetInfo().BatteryLevel; math.Abs(float64(got-want)) > 0.01 {
+		t.Fatalf("level = %v, want %v", got, want)
+	}
+}
+
+// startDraining connects a charged battery and runs it through the disconnect countdown
+func startDraining(t *testing.T, b *Battery, clock *FakeClock) {
+	t.Helper()
+	b.SetIsDraining(true)
+	step(b, clock, testTick)
+	expectState(t, b, Disconnecting)
+	step(b, clock, time.Second)
+	expectState(t, b, Draining)
+}
+
+func TestNewBatteryStartsCharged(t *testing.T) {
+	b, _ := newTestBattery(t, testConfig())
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+}
+
+func TestChargedStaysChargedWithoutInput(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+}
+
+func TestChargedToDisconnectingToDraining(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+
+	b.SetIsDraining(true)
+	step(b, clock, testTick)
+	expectState(t, b, Disconnecting)
+
+	// The level holds through the countdown
+	step(b, clock, 500*time.Millisecond)
+	expectState(t, b, Disconnecting)
+	expectLevel(t, b, 100)
+
+	step(b, clock, 500*time.Millisecond)
+	expectState(t, b, Draining)
+}
+
+func TestDisconnectingCountdownRemaining(t *testing.T) {
+	config := testConfig()
+	config.DisconnectingDuration = 10 * time.Second
+	b, clock := newTestBattery(t, config)
+
+	b.SetIsDraining(true)
+	step(b, clock, testTick)
+	step(b, clock, 4*time.Second)
+	if got := b.GetInfo().DisconnectingDurationRemaining; got != 6*time.Second {
+		t.Fatalf("remaining = %v, want 6s", got)
+	}
+}
+
+func TestDrainingFollowsDrainRate(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Draining)
+	expectLevel(t, b, 90)
+}
+
+func TestDrainingToDeadClampsAtZero(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+
+	// Well past empty
+	step(b, clock, 150*time.Second)
+	expectState(t, b, Dead)
+	expectLevel(t, b, 0)
+
+	// Dead holds at 0 while still connected
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Dead)
+	expectLevel(t, b, 0)
+}
+
+func TestDrainingToCharging(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+
+	// The tick that saw the input still drained
+	step(b, clock, 5*time.Second)
+	expectLevel(t, b, 94.9)
+}
+
+func TestDrainingToIdleOffCharger(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig().WithChargerInput())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Idle)
+
+	// Idle holds its level
+	level := b.GetInfo().BatteryLevel
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Idle)
+	expectLevel(t, b, level)
+}
+
+func TestDeadToCharging(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 101*time.Second)
+	expectState(t, b, Dead)
+
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+
+	step(b, clock, 10*time.Second)
+	expectLevel(t, b, 10)
+}
+
+func TestDeadStaysDeadOffCharger(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig().WithChargerInput())
+	startDraining(t, b, clock)
+	step(b, clock, 101*time.Second)
+	expectState(t, b, Dead)
+
+	b.SetIsDraining(false)
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Dead)
+
+	b.SetIsCharging(true)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+}
+
+func TestIdleToDisconnecting(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig().WithChargerInput())
+	startDraining(t, b, clock)
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Idle)
+
+	b.SetIsDraining(true)
+	step(b, clock, testTick)
+	expectState(t, b, Disconnecting)
+}
+
+func TestIdleToCharging(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig().WithChargerInput())
+	startDraining(t, b, clock)
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Idle)
+
+	b.SetIsCharging(true)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+}
+
+func TestChargingToChargedClampsAtCapacity(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+
+	// Well past full
+	step(b, clock, 30*time.Second)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+}
+
+func TestChargingToDisconnecting(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+	b.SetIsDraining(false)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+
+	b.SetIsDraining(true)
+	step(b, clock, testTick)
+	expectState(t, b, Disconnecting)
+}
+
+func TestChargingToIdle(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig().WithChargerInput())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+	b.SetIsDraining(false)
+	b.SetIsCharging(true)
+	step(b, clock, testTick)
+	expectState(t, b, Charging)
+
+	b.SetIsCharging(false)
+	step(b, clock, testTick)
+	expectState(t, b, Idle)
+}
+
+func TestChargedOverrideMidDrain(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 50*time.Second)
+	expectLevel(t, b, 50)
+
+	// The override snaps to full and holds Charged while the battery is still connected
+	b.SetChargedOverride(true)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+
+	// Releasing it hands back to the state machine, which starts the countdown again
+	b.SetChargedOverride(false)
+	step(b, clock, testTick)
+	expectState(t, b, Disconnecting)
+	step(b, clock, time.Second)
+	expectState(t, b, Draining)
+}
+
+func TestChargedOverrideToggledRepeatedlyMidDrain(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+
+	for i := 0; i < 5; i++ {
+		step(b, clock, 5*time.Second)
+		b.SetChargedOverride(true)
+		step(b, clock, testTick)
+		expectState(t, b, Charged)
+		expectLevel(t, b, 100)
+		b.SetChargedOverride(false)
+		step(b, clock, testTick)
+		expectState(t, b, Disconnecting)
+		step(b, clock, time.Second)
+		expectState(t, b, Draining)
+	}
+}
+
+func TestChargedOverrideTimeout(t *testing.T) {
+	config := testConfig()
+	config.OverrideTimeout = 2 * time.Second
+	b, clock := newTestBattery(t, config)
+	startDraining(t, b, clock)
+
+	b.SetChargedOverride(true)
+	step(b, clock, time.Second)
+	expectState(t, b, Charged)
+
+	// Held past the timeout the override is ignored, so the connected battery drains again
+	step(b, clock, 1100*time.Millisecond)
+	expectState(t, b, Disconnecting)
+	b.SetChargedOverride(true)
+	step(b, clock, time.Second)
+	expectState(t, b, Draining)
+
+	// Releasing and pressing again re-arms it
+	b.SetChargedOverride(false)
+	b.SetChargedOverride(true)
+	expectState(t, b, Charged)
+}
+
+func TestForceDead(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+
+	b.ForceDead()
+	expectState(t, b, Dead)
+	expectLevel(t, b, 0)
+	if !b.GetInfo().ForcedDead {
+		t.Fatal("ForcedDead not reported")
+	}
+
+	// Held Dead even though the battery would charge
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Dead)
+	expectLevel(t, b, 0)
+	b.SetLevel(50)
+	step(b, clock, testTick)
+	expectState(t, b, Dead)
+	expectLevel(t, b, 0)
+
+	// Only the charged override clears it
+	b.SetChargedOverride(true)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 100)
+	b.SetChargedOverride(false)
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Charged)
+	if b.GetInfo().ForcedDead {
+		t.Fatal("ForcedDead still reported after the override")
+	}
+}
+
+func TestForceDeadMidDrain(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	b.SetChargedOverride(true)
+	b.SetChargedOverride(false)
+
+	b.ForceDead()
+	expectState(t, b, Dead)
+	b.SetIsDraining(false)
+	step(b, clock, 10*time.Second)
+	expectState(t, b, Dead)
+}
+
+func TestSetLevelTransitions(t *testing.T) {
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, b *Battery, clock *FakeClock)
+		charger  bool
+		draining bool
+		level    float32
+		want     SystemState
+	}{
+		{
+			name:  "charged to charging below capacity",
+			level: 50,
+			want:  Charging,
+		},
+		{
+			name:    "charged to idle below capacity off the charger",
+			charger: true,
+			level:   50,
+			want:    Idle,
+		},
+		{
+			name:     "charged to disconnecting below capacity while connected",
+			draining: true,
+			level:    50,
+			want:     Disconnecting,
+		},
+		{
+			name:  "charged stays charged at capacity",
+			level: 100,
+			want:  Charged,
+		},
+		{
+			name:     "draining to dead at zero",
+			setup:    startDraining,
+			draining: true,
+			level:    0,
+			want:     Dead,
+		},
+		{
+			name:     "draining keeps draining above zero",
+			setup:    startDraining,
+			draining: true,
+			level:    30,
+			want:     Draining,
+		},
+		{
+			name: "dead to draining above zero while connected",
+			setup: func(t *testing.T, b *Battery, clock *FakeClock) {
+				startDraining(t, b, clock)
+				step(b, clock, 101*time.Second)
+			},
+			draining: true,
+			level:    20,
+			want:     Draining,
+		},
+		{
+			name: "dead to charging above zero",
+			setup: func(t *testing.T, b *Battery, clock *FakeClock) {
+				startDraining(t, b, clock)
+				step(b, clock, 101*time.Second)
+				b.SetIsDraining(false)
+			},
+			level: 20,
+			want:  Charging,
+		},
+		{
+			name: "charging to charged at capacity",
+			setup: func(t *testing.T, b *Battery, clock *FakeClock) {
+				startDraining(t, b, clock)
+				step(b, clock, 10*time.Second)
+				b.SetIsDraining(false)
+				step(b, clock, testTick)
+			},
+			level: 100,
+			want:  Charged,
+		},
+		{
+			name: "idle to dead at zero",
+			setup: func(t *testing.T, b *Battery, clock *FakeClock) {
+				startDraining(t, b, clock)
+				b.SetIsDraining(false)
+				step(b, clock, testTick)
+			},
+			charger: true,
+			level:   0,
+			want:    Dead,
+		},
+		{
+			name: "disconnecting to dead at zero",
+			setup: func(t *testing.T, b *Battery, clock *FakeClock) {
+				b.SetIsDraining(true)
+				step(b, clock, testTick)
+			},
+			draining: true,
+			level:    0,
+			want:     Dead,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := testConfig()
+			if tt.charger {
+				config = config.WithChargerInput()
+			}
+			b, clock := newTestBattery(t, config)
+			if tt.setup != nil {
+				tt.setup(t, b, clock)
+			}
+			b.SetIsDraining(tt.draining)
+
+			b.SetLevel(tt.level)
+			expectState(t, b, tt.want)
+			expectLevel(t, b, tt.level)
+		})
+	}
+}
+
+func TestSetLevelClamps(t *testing.T) {
+	b, _ := newTestBattery(t, testConfig())
+
+	b.SetLevel(150)
+	expectLevel(t, b, 100)
+	b.SetLevel(-20)
+	expectLevel(t, b, 0)
+}
+
+func TestAddChargeAndDrainClamp(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+
+	b.Drain(30)
+	expectLevel(t, b, 70)
+	b.AddCharge(50)
+	expectLevel(t, b, 100)
+	b.Drain(250)
+	expectLevel(t, b, 0)
+	expectState(t, b, Dead)
+}
+
+func TestLevelClampsToWornCapacity(t *testing.T) {
+	config := testConfig()
+	config.WearPerCycle = 20
+	b, clock := newTestBattery(t, config)
+	startDraining(t, b, clock)
+	step(b, clock, 101*time.Second)
+	expectState(t, b, Dead)
+
+	b.SetIsDraining(false)
+	step(b, clock, 200*time.Second)
+	expectState(t, b, Charged)
+	expectLevel(t, b, 80)
+	b.SetLevel(100)
+	expectLevel(t, b, 80)
+}
+
+func TestMinDwellHoldsState(t *testing.T) {
+	config := testConfig()
+	config.MinDwell = map[SystemState]time.Duration{Draining: 2 * time.Second}
+	b, clock := newTestBattery(t, config)
+	startDraining(t, b, clock)
+
+	b.SetIsDraining(false)
+	step(b, clock, time.Second)
+	expectState(t, b, Draining)
+	step(b, clock, time.Second)
+	expectState(t, b, Charging)
+}
+
+func TestInputHysteresisFiltersFlicker(t *testing.T) {
+	config := testConfig()
+	config.InputHysteresis = 500 * time.Millisecond
+	b, clock := newTestBattery(t, config)
+
+	// A brief flick is ignored
+	b.SetIsDraining(true)
+	step(b, clock, 200*time.Millisecond)
+	b.SetIsDraining(false)
+	step(b, clock, time.Second)
+	expectState(t, b, Charged)
+
+	// A held input is acted on
+	b.SetIsDraining(true)
+	step(b, clock, 600*time.Millisecond)
+	expectState(t, b, Disconnecting)
+}
+
+func TestPauseFreezesLevelAndCountdown(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 10*time.Second)
+
+	b.Pause()
+	step(b, clock, 30*time.Second)
+	expectLevel(t, b, 90)
+
+	b.Resume()
+	step(b, clock, 10*time.Second)
+	expectLevel(t, b, 80)
+}
+
+func TestTransitionsRecordedInHistory(t *testing.T) {
+	b, clock := newTestBattery(t, testConfig())
+	startDraining(t, b, clock)
+	step(b, clock, 101*time.Second)
+
+	want := []SystemState{Disconnecting, Draining, Dead}
+	history := b.History()
+	if len(history) != len(want) {
+		t.Fatalf("history has %d transitions, want %d", len(history), len(want))
+	}
+	for i, transition := range history {
+		if transition.To != want[i] {
+			t.Errorf("transition %d to %v, want %v", i, transition.To, want[i])
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package battery
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the battery's source of time, injectable through Config so the
+// state machine can be driven deterministically
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// SystemClock is the real time clock
+var SystemClock Clock = systemClock{}
+
+// SimulationClock is the real time clock sped up or slowed down by SetTimeScale.
+// Batteries use it when Config.Clock is nil, so one call changes the pace of
+// the whole scenario, e.g. for rehearsals.
+var SimulationClock = &ScaledClock{base: SystemClock, scale: 1}
+
+// SetTimeScale sets the pace of SimulationClock, 2 runs the simulation twice as fast
+func SetTimeScale(scale float64) {
+	SimulationClock.SetScale(scale)
+}
+
+// TimeScale returns the pace of SimulationClock
+func TimeScale() float64 {
+	return SimulationClock.Scale()
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTicker(d time.Duration) Ticker {
+	return systemTicker{time.NewTicker(d)}
+}
+
+type systemTicker struct {
+	ticker *time.Ticker
+}
+
+func (t systemTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t systemTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// ScaledClock runs another clock at an adjustable rate. Tickers still fire at
+// the base clock's rate; only the time read from Now is scaled.
+type ScaledClock struct {
+	mu       sync.Mutex
+	base     Clock
+	scale    float64
+	baseMark time.Time // Base time when the scale last changed
+	simMark  time.Time // Scaled time when the scale last changed
+}
+
+// NewScaledClock creates a clock running at scale times the base clock
+func NewScaledClock(base Clock, scale float64) *ScaledClock {
+	c := &ScaledClock{base: base}
+	c.SetScale(scale)
+	return c
+}
+
+// Now returns the scaled time
+func (c *ScaledClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now()
+}
+
+// now returns the scaled time (must be called with mutex locked)
+func (c *ScaledClock) now() time.Time {
+	baseNow := c.base.Now()
+	if c.baseMark.IsZero() {
+		// Scaled time starts out equal to base time
+		c.baseMark = baseNow
+		c.simMark = baseNow
+	}
+	return c.simMark.Add(time.Duration(float64(baseNow.Sub(c.baseMark)) * c.scale))
+}
+
+// NewTicker creates a ticker on the base clock
+func (c *ScaledClock) NewTicker(d time.Duration) Ticker {
+	return c.base.NewTicker(d)
+}
+
+// SetScale changes the rate without making the scaled time jump; negative scales are treated as 0 (paused)
+func (c *ScaledClock) SetScale(scale float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.simMark = c.now()
+	c.baseMark = c.base.Now()
+	c.scale = max(scale, 0)
+}
+
+// Scale returns the current rate
+func (c *ScaledClock) Scale() float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.scale
+}
+
+// FakeClock is a manually advanced clock; tickers fire only from Advance
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	tickers []*fakeTicker
+}
+
+// NewFakeClock creates a fake clock reading start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+// Now returns the fake current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// NewTicker creates a ticker that fires as Advance moves past each period
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t := &fakeTicker{
+		clock:  c,
+		period: d,
+		next:   c.now.Add(d),
+		ch:     make(chan time.Time, 1),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Advance moves the clock forward, firing due tickers. Like time.Ticker a
+// ticker whose previous tick hasn't been received drops ticks, so advance in
+// steps no longer than the tick period to see every one.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	for _, t := range c.tickers {
+		for t.period > 0 && !t.next.After(c.now) {
+			select {
+			case t.ch <- t.next:
+			default:
+			}
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+type fakeTicker struct {
+	clock  *FakeClock
+	period time.Duration
+	next   time.Time
+	ch     chan time.Time
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.ch
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	for i, ticker := range t.clock.tickers {
+		if ticker == t {
+			t.clock.tickers = append(t.clock.tickers[:i], t.clock.tickers[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package battery
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// RateCurve returns a multiplier for the drain or charge rate at a battery level (0-100).
+// A multiplier of 1 is the configured rate, 2 is twice as fast, 0.5 half as fast.
+type RateCurve func(level float32) float64
+
+// CurvePoint is a point on a piecewise rate curve
+type CurvePoint struct {
+	Level      float32 // Battery level 0-100
+	Multiplier float64 // Rate multiplier at this level
+}
+
+// minCurveMultiplier keeps a curve from stalling the battery forever
+const minCurveMultiplier = 0.01
+
+// PiecewiseCurve returns a curve that interpolates linearly between points.
+// Levels outside the points use the multiplier of the nearest point.
+func PiecewiseCurve(points ...CurvePoint) RateCurve {
+	sorted := make([]CurvePoint, len(points))
+	copy(sorted, points)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Level < sorted[j].Level
+	})
+
+	return func(level float32) float64 {
+		if len(sorted) == 0 {
+			return 1
+		}
+		if level <= sorted[0].Level {
+			return sorted[0].Multiplier
+		}
+		for i := 1; i < len(sorted); i++ {
+			if level <= sorted[i].Level {
+				lo, hi := sorted[i-1], sorted[i]
+				t := float64((level - lo.Level) / (hi.Level - lo.Level))
+				return lo.Multiplier + t*(hi.Multiplier-lo.Multiplier)
+			}
+		}
+		return sorted[len(sorted)-1].Multiplier
+	}
+}
+
+// LithiumDrainCurve drains steadily through the middle and drops quickly below 20%
+func LithiumDrainCurve() RateCurve {
+	return PiecewiseCurve(
+		CurvePoint{Level: 0, Multiplier: 3},
+		CurvePoint{Level: 20, Multiplier: 2},
+		CurvePoint{Level: 30, Multiplier: 0.8},
+		CurvePoint{Level: 90, Multiplier: 0.8},
+		CurvePoint{Level: 100, Multiplier: 1.5},
+	)
+}
+
+// TaperChargeCurve charges quickly when low and slowly tapers off near 100%
+func TaperChargeCurve() RateCurve {
+	return PiecewiseCurve(
+		CurvePoint{Level: 0, Multiplier: 1.3},
+		CurvePoint{Level: 80, Multiplier: 1},
+		CurvePoint{Level: 100, Multiplier: 0.25},
+	)
+}
+
+// rateMultiplier evaluates a curve, treating a nil curve as linear
+func rateMultiplier(curve RateCurve, level float32) float64 {
+	if curve == nil {
+		return 1
+	}
+	return max(curve(level), minCurveMultiplier)
+}
+
+// Forever is the time to empty of a battery that isn't draining at all, e.g. with a rate multiplier of 0
+const Forever = time.Duration(math.MaxInt64)
+
+// levelStep is the level span, in percent, over which levelTime treats a curve as constant
+const levelStep = 1
+
+// levelTime returns how long the level takes to move from one level to another
+// when fullRate moves it 100% at a multiplier of 1, shaped by curve and scaled by multiplier
+func levelTime(from, to float32, fullRate time.Duration, curve RateCurve, multiplier float64) time.Duration {
+	if from == to {
+		return 0
+	}
+	if multiplier <= 0 {
+		return Forever
+	}
+
+	lo, hi := min(from, to), max(from, to)
+	var total float64 // In units of fullRate
+	for level := lo; level < hi; level += levelStep {
+		span := min(hi-level, levelStep)
+		total += float64(span) / 100 / rateMultiplier(curve, level+span/2)
+	}
+	return time.Duration(total * float64(fullRate) / multiplier)
+}
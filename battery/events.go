@@ -0,0 +1,80 @@
+package battery
+
+import (
+	"sync"
+)
+
+// eventBus fans state transitions out to subscribed channels and callbacks
+type eventBus struct {
+	mu        sync.Mutex
+	channels  []chan<- StateTransition
+	callbacks []func(StateTransition)
+	pending   []StateTransition
+}
+
+// Subscribe registers a channel that receives every state transition.
+// Sends never block the state machine; events are dropped if the channel is full,
+// so use a buffered channel.
+func (b *Battery) Subscribe(ch chan<- StateTransition) {
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
+	b.events.channels = append(b.events.channels, ch)
+}
+
+// Unsubscribe removes a channel previously registered with Subscribe
+func (b *Battery) Unsubscribe(ch chan<- StateTransition) {
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
+
+	for i, subscriber := range b.events.channels {
+		if subscriber == ch {
+			b.events.channels = append(b.events.channels[:i], b.events.channels[i+1:]...)
+			return
+		}
+	}
+}
+
+// OnTransition registers a callback run for every state transition.
+// Callbacks run on the battery's goroutine after its lock is released, so they
+// may call back into the battery but should return quickly.
+func (b *Battery) OnTransition(callback func(StateTransition)) {
+	b.events.mu.Lock()
+	defer b.events.mu.Unlock()
+	b.events.callbacks = append(b.events.callbacks, callback)
+}
+
+// queue records a transition for delivery by dispatch
+func (e *eventBus) queue(t StateTransition) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending = append(e.pending, t)
+}
+
+// dispatch delivers queued transitions to all subscribers.
+// Must be called without the battery mutex held.
+func (e *eventBus) dispatch() {
+	e.mu.Lock()
+	if len(e.pending) == 0 {
+		e.mu.Unlock()
+		return
+	}
+	pending := e.pending
+	e.pending = nil
+	channels := append([]chan<- StateTransition(nil), e.channels...)
+	callbacks := make([]func(StateTransition), len(e.callbacks))
+	copy(callbacks, e.callbacks)
+	e.mu.Unlock()
+
+	for _, t := range pending {
+		for _, ch := range channels {
+			select {
+			case ch <- t:
+			default:
+				// Subscriber isn't keeping up, drop the event
+			}
+		}
+		for _, callback := range callbacks {
+			callback(t)
+		}
+	}
+}
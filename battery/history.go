@@ -0,0 +1,67 @@
+package battery
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultHistorySize is the number of state transitions each battery remembers
+const DefaultHistorySize = 64
+
+// StateTransition records a single battery state change
+type StateTransition struct {
+	From  SystemState
+	To    SystemState
+	Level float32 // Battery level at the time of the transition
+	At    time.Time
+}
+
+// History is a fixed-size ring buffer of state transitions
+type History struct {
+	mu      sync.RWMutex
+	entries []StateTransition
+	next    int
+	count   int
+}
+
+// NewHistory creates a history that keeps the most recent size transitions
+func NewHistory(size int) *History {
+	if size <= 0 {
+		size = DefaultHistorySize
+	}
+	return &History{
+		entries: make([]StateTransition, size),
+	}
+}
+
+// Add records a transition, overwriting the oldest entry when full
+func (h *History) Add(t StateTransition) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries[h.next] = t
+	h.next = (h.next + 1) % len(h.entries)
+	if h.count < len(h.entries) {
+		h.count++
+	}
+}
+
+// Entries returns a copy of the recorded transitions, oldest first
+func (h *History) Entries() []StateTransition {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	result := make([]StateTransition, h.count)
+	start := (h.next - h.count + len(h.entries)) % len(h.entries)
+	for i := 0; i < h.count; i++ {
+		result[i] = h.entries[(start+i)%len(h.entries)]
+	}
+	return result
+}
+
+// Len returns the number of recorded transitions
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.count
+}
@@ -0,0 +1,111 @@
+package battery
+
+import (
+	"sync"
+	"time"
+)
+
+// Snapshot is what a transition rule sees of a battery on each tick, after the
+// level has moved for the current state
+type Snapshot struct {
+	State                 SystemState
+	Level                 float32
+	MaxCapacity           float32
+	Draining              bool          // Draining input, once it has held past the InputHysteresis
+	Charging              bool          // On the charger, always true without Config.ChargerInput
+	InState               time.Duration // Time since the state was entered
+	Dwelled               bool          // InState has reached the state's MinDwell
+	DisconnectingDuration time.Duration
+	Temperature           float32 // °C, only modelled with Config.Thermal
+}
+
+// Condition decides whether a rule fires
+type Condition func(s Snapshot) bool
+
+// Rule moves a battery in state From to state To when its condition holds
+type Rule struct {
+	From SystemState
+	When Condition
+	To   SystemState
+}
+
+// DefaultRules returns the standard state machine. For each state the first
+// rule whose condition holds fires, at most one per tick. The charged override
+// and ForceDead act before the table and can't be overridden by it.
+func DefaultRules() []Rule {
+	return []Rule{
+		// A connected battery counts down before it starts draining
+		{From: Charged, When: func(s Snapshot) bool { return s.Draining && s.Dwelled }, To: Disconnecting},
+		{From: Disconnecting, When: func(s Snapshot) bool { return s.InState >= s.DisconnectingDuration }, To: Draining},
+
+		// Draining runs down to Dead, or rests once disconnected
+		{From: Draining, When: func(s Snapshot) bool { return s.Level <= 0 }, To: Dead},
+		{From: Draining, When: func(s Snapshot) bool { return !s.Draining && s.Dwelled && s.Charging }, To: Charging},
+		{From: Draining, When: func(s Snapshot) bool { return !s.Draining && s.Dwelled }, To: Idle},
+
+		// Dead only recovers on the charger, or by the charged override
+		{From: Dead, When: func(s Snapshot) bool { return !s.Draining && s.Dwelled && s.Charging }, To: Charging},
+
+		// Idle holds its level until connected again or put on the charger
+		{From: Idle, When: func(s Snapshot) bool { return s.Draining && s.Dwelled }, To: Disconnecting},
+		{From: Idle, When: func(s Snapshot) bool { return s.Charging && s.Dwelled }, To: Charging},
+
+		// Charging fills up to capacity unless connected or taken off the charger
+		{From: Charging, When: func(s Snapshot) bool { return s.Level >= s.MaxCapacity }, To: Charged},
+		{From: Charging, When: func(s Snapshot) bool { return s.Draining && s.Dwelled }, To: Disconnecting},
+		{From: Charging, When: func(s Snapshot) bool { return !s.Charging && s.Dwelled }, To: Idle},
+	}
+}
+
+// WithRules returns a copy of the config whose state machine checks rules before
+// the ones it already has (DefaultRules if none), e.g. to add a custom state:
+//
+//	overheated := battery.DefineState("Overheated")
+//	config = config.WithRules(
+//		battery.Rule{From: battery.Draining, When: tooHot, To: overheated},
+//		battery.Rule{From: overheated, When: cooled, To: battery.Idle},
+//	)
+func (c Config) WithRules(rules ...Rule) Config {
+	existing := c.Rules
+	if existing == nil {
+		existing = DefaultRules()
+	}
+	c.Rules = append(append([]Rule(nil), rules...), existing...)
+	return c
+}
+
+var (
+	customStatesMu sync.Mutex
+	customStates   []string // Names of states added by DefineState, in order after Idle
+)
+
+// DefineState adds a state for custom rules, returning it. Its String is name.
+// States other than Draining and Charging hold the battery's level.
+func DefineState(name string) SystemState {
+	customStatesMu.Lock()
+	defer customStatesMu.Unlock()
+	customStates = append(customStates, name)
+	return Idle + SystemState(len(customStates))
+}
+
+// customStateName returns the name of a state added by DefineState
+func customStateName(s SystemState) (string, bool) {
+	customStatesMu.Lock()
+	defer customStatesMu.Unlock()
+	i := int(s - Idle - 1)
+	if i < 0 || i >= len(customStates) {
+		return "", false
+	}
+	return customStates[i], true
+}
+
+// nextState returns the state the first matching rule moves the battery to, or
+// its current state if none match
+func nextState(rules []Rule, s Snapshot) SystemState {
+	for _, rule := range rules {
+		if rule.From == s.State && rule.When(s) {
+			return rule.To
+		}
+	}
+	return s.State
+}
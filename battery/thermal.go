@@ -0,0 +1,75 @@
+package battery
+
+// Overheated is a battery in thermal shutdown: it holds its level, neither
+// draining nor charging, until it cools to the resume temperature
+var Overheated = DefineState("Overheated")
+
+// ThermalConfig is an optional heat model adding a strategic layer to the puzzle:
+// draining a battery warms it, and one driven too hard shuts down until it has
+// cooled, so batteries have to be rotated rather than run flat one at a time
+type ThermalConfig struct {
+	Ambient        float32 // Resting temperature in °C, a new battery starts here
+	HeatPerPercent float32 // Rise in °C for each percentage point drained, 0 disables the model
+	CoolRate       float32 // Fall in °C per minute towards ambient while not draining, including on the charger
+	Shutdown       float32 // A draining battery reaching this goes Overheated
+	Resume         float32 // An overheated battery cools to this before it can charge or drain again
+}
+
+// DefaultThermalConfig overheats a battery after about half its charge is drained
+// in one go, and takes five minutes to cool from shutdown before it can be used again
+func DefaultThermalConfig() ThermalConfig {
+	return ThermalConfig{
+		Ambient:        20,
+		HeatPerPercent: 0.8,
+		CoolRate:       5,
+		Shutdown:       60,
+		Resume:         35,
+	}
+}
+
+// enabled returns whether the heat model is in use
+func (t ThermalConfig) enabled() bool {
+	return t.HeatPerPercent > 0
+}
+
+// heat returns temperature as a fraction from ambient (0.0) to shutdown (1.0)
+func (t ThermalConfig) heat(temperature float32) float32 {
+	if t.Shutdown <= t.Ambient {
+		return 0
+	}
+	return min(max((temperature-t.Ambient)/(t.Shutdown-t.Ambient), 0), 1)
+}
+
+// WithThermal returns a copy of the config whose batteries heat up while draining
+// and go Overheated at the thermal shutdown temperature
+func (c Config) WithThermal(thermal ThermalConfig) Config {
+	c.Thermal = thermal
+	return c
+}
+
+// thermalRules returns the transitions into and out of Overheated, checked before
+// the rest of the table so a hot battery shuts down whatever its inputs
+func thermalRules(t ThermalConfig) []Rule {
+	cooled := func(s Snapshot) bool { return s.Temperature <= t.Resume }
+	return []Rule{
+		{From: Draining, When: func(s Snapshot) bool { return s.Temperature >= t.Shutdown }, To: Overheated},
+
+		// Once cool, carry on as an Idle battery would
+		{From: Overheated, When: func(s Snapshot) bool { return cooled(s) && s.Draining && s.Dwelled }, To: Disconnecting},
+		{From: Overheated, When: func(s Snapshot) bool { return cooled(s) && !s.Draining && s.Charging }, To: Charging},
+		{From: Overheated, When: func(s Snapshot) bool { return cooled(s) && !s.Draining }, To: Idle},
+	}
+}
+
+// updateTemperature warms the battery by the level drained this tick, or cools it
+// towards ambient when it drained nothing (must be called with mutex locked)
+func (b *Battery) updateTemperature(drained float32, deltaMinutes float64) {
+	if !b.thermal.enabled() {
+		return
+	}
+	if drained > 0 {
+		b.temperature += drained * b.thermal.HeatPerPercent
+		return
+	}
+	b.temperature = max(b.temperature-b.thermal.CoolRate*float32(deltaMinutes), b.thermal.Ambient)
+}
@@ -0,0 +1,24 @@
+package battery
+
+// DefaultMinCapacity is the capacity a battery wears down to when Config.MinCapacity is unset
+const DefaultMinCapacity = 50
+
+// addWear records drained percentage points and reduces capacity accordingly
+// (must be called with mutex locked)
+func (b *Battery) addWear(drained float32) {
+	if drained <= 0 {
+		return
+	}
+	b.cycles += drained / 100
+	if b.wearPerCycle > 0 {
+		b.maxCapacity = max(b.maxCapacity-drained/100*b.wearPerCycle, b.minCapacity)
+	}
+}
+
+// ResetWear restores the battery to full capacity, e.g. after a prop refurbishment
+func (b *Battery) ResetWear() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.maxCapacity = 100
+	b.cycles = 0
+}
@@ -0,0 +1,43 @@
+//go:build !tinygo
+
+// Command framepng turns a strip frame dump captured from the serial console
+// ("capture dump") into a PNG with one row per frame. Lines that aren't frames,
+// such as the prompt or log output, are skipped.
+//
+//	go run ./cmd/framepng < serial.log > frames.png
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+func main() {
+	var frames []peripheral.Frame
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		frame, err := peripheral.ParseFrame(scanner.Text())
+		if err != nil {
+			continue
+		}
+		frames = append(frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "reading dump:", err)
+		os.Exit(1)
+	}
+	if len(frames) == 0 {
+		fmt.Fprintln(os.Stderr, "no frames found")
+		os.Exit(1)
+	}
+
+	if err := peripheral.WriteFramesPNG(os.Stdout, frames); err != nil {
+		fmt.Fprintln(os.Stderr, "writing PNG:", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "%d frames of %d pixels\n", len(frames), len(frames[0].Pixels))
+}
@@ -0,0 +1,98 @@
+//go:build !tinygo
+
+// Command sim runs the panel and battery logic on a laptop, rendering the LED
+// strip in the terminal and mapping keyboard keys onto the panel's buttons.
+package main
+
+import (
+	"context"
+	"fmt"
+	"image/color"
+	"os"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/airlock"
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/peripheral/sim"
+)
+
+func main() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	restore, err := sim.EnableRawMode()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "warning: could not enable raw terminal mode, press enter after each key:", err)
+	}
+	defer restore()
+
+	terminal := sim.NewTerminal(os.Stdout, 72, 10)
+	terminal.SetFooter("1-5: toggle battery connect  r: toggle reset  a: airlock  +/-: slider  q: quit")
+
+	neoPixel := peripheral.NeoPixel{NeoPixelDriver: terminal.NeoPixelWriter()}
+	neoPixel.SetColorAndPause(color.RGBA{0, 25, 0, 255}, 0)
+
+	numLEDs := 144
+	ledStrip := peripheral.NewColorLedStripWithWriter(numLEDs, terminal.StripWriter())
+
+	batteries := make([]*battery.Battery, 5)
+	for i := 0; i < 5; i++ {
+		batteries[i] = battery.NewBattery(battery.FastBatteryConfig())
+	}
+
+	// Keys 1-5 toggle the battery connect inputs, r toggles the reset button
+	keyboard := sim.NewKeyboard(os.Stdin)
+	batteryConnects := make([]peripheral.ButtonReader, 5)
+	for i := 0; i < 5; i++ {
+		batteryConnects[i] = keyboard.Button(byte('1' + i))
+	}
+	batteryResetButton := keyboard.Button('r')
+
+	slider := 50
+	keyboard.OnKey('+', func() {
+		slider = min(100, slider+10)
+		peripheral.SetSimulatedAnalogInput(slider)
+	})
+	keyboard.OnKey('-', func() {
+		slider = max(0, slider-10)
+		peripheral.SetSimulatedAnalogInput(slider)
+	})
+	keyboard.OnKey('q', cancel)
+
+	// a presses the airlock door button briefly
+	airLockButton := peripheral.NewMockButton()
+	keyboard.OnKey('a', func() {
+		airLockButton.SetPressed(true)
+		time.AfterFunc(200*time.Millisecond, func() {
+			airLockButton.SetPressed(false)
+		})
+	})
+	airLock := airlock.NewAirLock(airlock.DefaultConfig())
+	defer airLock.Stop()
+
+	panelConfig := panel.PanelConfig{
+		Batteries:          batteries,
+		LEDStrip:           ledStrip,
+		AirLockButton:      airLockButton,
+		AirLock:            airLock,
+		AirLockLEDs:        8,
+		BatteryResetButton: batteryResetButton,
+		BatteryConnects:    batteryConnects,
+		UpdateRate:         50 * time.Millisecond,
+	}
+	mainPanel, err := panel.NewPanel(panelConfig)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+	defer mainPanel.Stop()
+
+	go keyboard.Run(ctx)
+
+	select {
+	case <-ctx.Done():
+	case <-mainPanel.GetContext().Done():
+	}
+}
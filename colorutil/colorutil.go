@@ -0,0 +1,115 @@
+// Package colorutil provides the color math shared by the panel and patterns:
+// HSV conversion, interpolation, brightness scaling and palettes.
+package colorutil
+
+import (
+	"image/color"
+	"math"
+)
+
+// FromHSV converts hue (degrees, wrapped to 0-360), saturation and value
+// (0.0 to 1.0) to an opaque RGBA color
+func FromHSV(h, s, v float64) color.RGBA {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	s = clamp(s)
+	v = clamp(v)
+
+	chroma := v * s
+	x := chroma * (1 - math.Abs(math.Mod(h/60, 2)-1))
+	m := v - chroma
+
+	var r, g, b float64
+	switch {
+	case h < 60:
+		r, g, b = chroma, x, 0
+	case h < 120:
+		r, g, b = x, chroma, 0
+	case h < 180:
+		r, g, b = 0, chroma, x
+	case h < 240:
+		r, g, b = 0, x, chroma
+	case h < 300:
+		r, g, b = x, 0, chroma
+	default:
+		r, g, b = chroma, 0, x
+	}
+	return color.RGBA{R: toByte(r + m), G: toByte(g + m), B: toByte(b + m), A: 255}
+}
+
+// ToHSV converts a color to hue (degrees 0-360), saturation and value (0.0 to 1.0)
+func ToHSV(c color.RGBA) (h, s, v float64) {
+	r, g, b := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	maxC := max(r, g, b)
+	minC := min(r, g, b)
+	delta := maxC - minC
+
+	v = maxC
+	if maxC > 0 {
+		s = delta / maxC
+	}
+	if delta == 0 {
+		return 0, s, v
+	}
+
+	switch maxC {
+	case r:
+		h = 60 * math.Mod((g-b)/delta, 6)
+	case g:
+		h = 60 * ((b-r)/delta + 2)
+	default:
+		h = 60 * ((r-g)/delta + 4)
+	}
+	if h < 0 {
+		h += 360
+	}
+	return h, s, v
+}
+
+// Lerp linearly interpolates between two colors, t from 0.0 (a) to 1.0 (b)
+func Lerp(a, b color.RGBA, t float64) color.RGBA {
+	t = clamp(t)
+	lerp := func(x, y uint8) uint8 {
+		return uint8(math.Round(float64(x) + (float64(y)-float64(x))*t))
+	}
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: lerp(a.A, b.A)}
+}
+
+// Scale returns the color with each channel scaled by brightness (0.0 to 1.0)
+func Scale(c color.RGBA, brightness float64) color.RGBA {
+	brightness = clamp(brightness)
+	return color.RGBA{
+		R: uint8(math.Round(float64(c.R) * brightness)),
+		G: uint8(math.Round(float64(c.G) * brightness)),
+		B: uint8(math.Round(float64(c.B) * brightness)),
+		A: c.A,
+	}
+}
+
+// clamp limits a value to 0.0-1.0
+func clamp(x float64) float64 {
+	return min(max(x, 0), 1)
+}
+
+// toByte converts a 0.0-1.0 channel to 0-255
+func toByte(x float64) uint8 {
+	return uint8(math.Round(clamp(x) * 255))
+}
+
+// WithPeak returns the color scaled, up or down, so its brightest channel is peak,
+// keeping its hue; black stays black
+func WithPeak(c color.RGBA, peak uint8) color.RGBA {
+	brightest := max(c.R, c.G, c.B)
+	if brightest == 0 {
+		return color.RGBA{A: c.A}
+	}
+	factor := float64(peak) / float64(brightest)
+	return color.RGBA{
+		R: uint8(math.Round(min(float64(c.R)*factor, 255))),
+		G: uint8(math.Round(min(float64(c.G)*factor, 255))),
+		B: uint8(math.Round(min(float64(c.B)*factor, 255))),
+		A: c.A,
+	}
+}
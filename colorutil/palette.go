@@ -0,0 +1,48 @@
+package colorutil
+
+import "image/color"
+
+// Palette is a gradient through evenly spaced colors
+type Palette []color.RGBA
+
+// At samples the gradient, t from 0.0 (first color) to 1.0 (last color)
+func (p Palette) At(t float64) color.RGBA {
+	switch len(p) {
+	case 0:
+		return color.RGBA{}
+	case 1:
+		return p[0]
+	}
+
+	position := clamp(t) * float64(len(p)-1)
+	index := int(position)
+	if index >= len(p)-1 {
+		return p[len(p)-1]
+	}
+	return Lerp(p[index], p[index+1], position-float64(index))
+}
+
+// Palettes kept dim, in the same gamma corrected range as the panel
+var (
+	// WarningPalette pulses from dark red through red to amber
+	WarningPalette = Palette{
+		{R: 45, G: 0, B: 0, A: 255},
+		{R: 111, G: 0, B: 0, A: 255},
+		{R: 111, G: 80, B: 0, A: 255},
+	}
+
+	// SpacePalette runs from black through deep blue to starlight white
+	SpacePalette = Palette{
+		{R: 0, G: 0, B: 0, A: 255},
+		{R: 0, G: 0, B: 67, A: 255},
+		{R: 45, G: 35, B: 86, A: 255},
+		{R: 86, G: 86, B: 90, A: 255},
+	}
+
+	// ChargeLevelPalette maps a charge fraction from empty red through yellow to full green
+	ChargeLevelPalette = Palette{
+		{R: 111, G: 0, B: 0, A: 255},
+		{R: 111, G: 111, B: 0, A: 255},
+		{R: 0, G: 111, B: 0, A: 255},
+	}
+)
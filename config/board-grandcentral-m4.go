@@ -0,0 +1,91 @@
+//go:build tinygo && grandcentral_m4
+
+package config
+
+import (
+	"machine"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/dmx"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// boardDefault returns the wiring of the Adafruit Grand Central M4 in the prop
+func boardDefault() HardwareConfig {
+	return HardwareConfig{
+		NumLEDs: 144,
+		Strip:   peripheral.StripConfig{SPI: machine.SPI0, Frequency: peripheral.DefaultStripFrequency},
+
+		// Board reset button, pressed when low
+		ResetButton: peripheral.ButtonConfig{Pin: machine.D40, Pull: peripheral.PullUp, ActiveLow: true, Interrupt: true},
+
+		// Battery connect signals
+		BatteryConnects: []peripheral.ButtonConfig{
+			{Pin: machine.D30, Pull: peripheral.PullUp, ActiveLow: false},
+			{Pin: machine.D32, Pull: peripheral.PullUp, ActiveLow: false},
+			{Pin: machine.D34, Pull: peripheral.PullUp, ActiveLow: false},
+			{Pin: machine.D36, Pull: peripheral.PullUp, ActiveLow: false},
+			{Pin: machine.D38, Pull: peripheral.PullUp, ActiveLow: false},
+		},
+
+		// Airlock door button, pressed when low
+		AirLockButton: peripheral.ButtonConfig{Pin: machine.D42, Pull: peripheral.PullUp, ActiveLow: true, Interrupt: true},
+		AirLockLEDs:   0, // No airlock section on the strip
+
+		// No master kill switch fitted
+		AbortSwitch: peripheral.ButtonConfig{Pin: machine.NoPin},
+
+		// Reset+connect 1 held 3s runs a self-test, connects 1, 2, 3 in quick succession play the easter egg
+		Combos: OperatorCombos{SelfTestHold: 3 * time.Second, EasterEggWindow: 2 * time.Second},
+
+		// No pattern knob fitted
+		PatternKnob: peripheral.RotaryEncoderConfig{PinA: machine.NoPin, PinB: machine.NoPin, Button: peripheral.ButtonConfig{Pin: machine.NoPin}},
+
+		// No readout knob fitted
+		ReadoutKnob: peripheral.RotaryEncoderConfig{PinA: machine.NoPin, PinB: machine.NoPin, Button: peripheral.ButtonConfig{Pin: machine.NoPin}},
+
+		NeoPixelPin:  machine.PC24,
+		StatusLEDPin: machine.PC30,
+		AnalogInputs: []AnalogInput{
+			{Pin: machine.A0, Role: peripheral.AnalogPatternSpeed}, // The slider
+		},
+		BuzzerPin: machine.D12,
+		BuzzerPWM: machine.TCC1,
+
+		// No cabinet lighting fitted
+		CabinetLight: peripheral.PwmLightConfig{Pin: machine.NoPin},
+
+		// No door release relay fitted
+		Relay: peripheral.RelayConfig{Pin: machine.NoPin},
+
+		// No charge gauge fitted
+		Gauge: peripheral.ServoConfig{Pin: machine.NoPin},
+
+		// No oxygen dial fitted
+		OxygenDial: peripheral.StepperConfig{Driver: peripheral.NoStepper},
+
+		// Elevator button as prototyped (door switch on PB13), not fitted to the prop yet
+		Elevator: peripheral.ElevatorConfig{
+			Door:    peripheral.ButtonConfig{Pin: machine.NoPin, Pull: peripheral.PullDown},
+			BlueLED: machine.PC17,
+			RedLED:  machine.PC16,
+			PWM:     machine.TCC0,
+		},
+
+		// No mission clock ring fitted
+		TimerRingLEDs: 0,
+
+		// No microphone fitted
+		MicrophonePin: machine.NoPin,
+
+		// No DMX transceiver fitted; venues with one use an RGB par per battery from channel 1
+		DMX: dmx.PortConfig{UART: nil},
+		DMXFixtures: []dmx.Fixture{
+			{Section: 0, Channel: 1, Footprint: dmx.RGB},
+			{Section: 1, Channel: 4, Footprint: dmx.RGB},
+			{Section: 2, Channel: 7, Footprint: dmx.RGB},
+			{Section: 3, Channel: 10, Footprint: dmx.RGB},
+			{Section: 4, Channel: 13, Footprint: dmx.RGB},
+		},
+	}
+}
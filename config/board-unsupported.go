@@ -0,0 +1,11 @@
+//go:build tinygo && !feather_m4 && !grandcentral_m4
+
+package config
+
+// boardDefault stops the build on a board with no profile: the compile error
+// names the missing profile instead of just reporting boardDefault undefined.
+// See the package documentation for adding a profile, and add the new board's
+// tag to the build constraint above.
+func boardDefault() HardwareConfig {
+	return noHardwareProfileForThisBoard
+}
@@ -0,0 +1,125 @@
+//go:build tinygo
+
+// Package config describes how the controller is wired to a particular board.
+// Each supported board provides a default profile in a file selected by its
+// TinyGo target build tag, so retargeting only needs a new profile file.
+//
+// To add a board, copy board-feather-m4.go to board-<target>.go, change its
+// build constraint to the board's target tag (e.g. "tinygo && pygamer"), wire
+// boardDefault to the board's pins, and exclude the tag from the constraint in
+// board-unsupported.go. Building for a board without a profile fails with
+// "undefined: noHardwareProfileForThisBoard".
+package config
+
+import (
+	"machine"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/dmx"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// HardwareConfig holds every pin and count the controller depends on
+type HardwareConfig struct {
+	// LED strip
+	NumLEDs int                    // Total LEDs on the APA102 strip
+	Strip   peripheral.StripConfig // Hardware SPI bus, or software SPI pins, driving the strip
+
+	// Pixels the panel and patterns draw, scaled onto the NumLEDs fitted, e.g. 144
+	// on a 30-LED test rig; 0 draws NumLEDs directly
+	LogicalLEDs int
+	LogicalView peripheral.VirtualStripConfig // Mirroring and direction when LogicalLEDs is set
+
+	// Per-channel calibration matching this strip's batch to the others on the prop;
+	// the zero value leaves colors uncorrected
+	WhiteBalance peripheral.WhiteBalance
+
+	// Inputs
+	ResetButton     peripheral.ButtonConfig
+	BatteryConnects []peripheral.ButtonConfig
+	BatteryChargers []peripheral.ButtonConfig         // Optional charger dock per battery; when empty batteries charge whenever disconnected
+	DockSensors     []peripheral.MagneticSwitchConfig // Optional magnet sensor per charger dock, used instead of BatteryChargers
+	AirLockButton   peripheral.ButtonConfig           // Pin is machine.NoPin if the prop has no airlock door
+	AirLockLEDs     int                               // LEDs at the end of the strip showing the airlock, 0 if it has none
+	AbortSwitch     peripheral.ButtonConfig           // Master kill switch, Pin is machine.NoPin if not fitted
+
+	// Hidden operator gestures on the reset and battery connect inputs
+	Combos OperatorCombos
+
+	// Optional rotary encoder for operators, twisted to choose a pattern and pressed
+	// to start it; PinA is machine.NoPin if not fitted
+	PatternKnob peripheral.RotaryEncoderConfig
+
+	// Optional MCP23017 for extra inputs/outputs; buttons with Expander set are read through it
+	Expander peripheral.ExpanderConfig
+
+	// Optional MPR121 for capacitive touch pads; buttons with Touch set are read through it
+	Touch peripheral.TouchConfig
+
+	// Optional text status display, Bus is nil if not fitted; the OLED is used if both are set
+	OLED peripheral.OLEDConfig
+	TFT  peripheral.TFTConfig
+
+	// Optional HT16K33 seven-segment readout of battery percentages, Bus is nil if not fitted
+	SevenSegment peripheral.SevenSegmentConfig
+
+	// Optional rotary encoder beside the readout, twisted to step between rotating
+	// through all batteries and showing one; PinA is machine.NoPin if not fitted.
+	// It is separate from PatternKnob, which would otherwise take its turns.
+	ReadoutKnob peripheral.RotaryEncoderConfig
+
+	// Onboard indicators
+	NeoPixelPin  machine.Pin // Onboard WS2812 status pixel
+	StatusLEDPin machine.Pin // Onboard single-color LED blinked as a heartbeat
+
+	// Analog inputs on A0-A5, each assigned a role
+	AnalogInputs []AnalogInput
+
+	// Audio
+	BuzzerPin machine.Pin    // Piezo buzzer, machine.NoPin if not fitted
+	BuzzerPWM peripheral.PWM // PWM peripheral able to drive BuzzerPin
+
+	// Optional dimmable cabinet lighting, Pin is machine.NoPin if not fitted
+	CabinetLight peripheral.PwmLightConfig
+
+	// Optional relay or solenoid driver for a payoff such as a door release, Pin is machine.NoPin if not fitted
+	Relay peripheral.RelayConfig
+
+	// Optional servo needle gauge showing the bank's charge, Pin is machine.NoPin if not fitted
+	Gauge peripheral.ServoConfig
+
+	// Optional stepper-driven oxygen dial, Driver is peripheral.NoStepper if not fitted
+	OxygenDial peripheral.StepperConfig
+
+	// Optional elevator button with breathing lights, Door.Pin is machine.NoPin if not fitted
+	Elevator peripheral.ElevatorConfig
+
+	// Optional APA102 ring showing the mission clock on its own bus, TimerRingLEDs is 0 if not fitted
+	TimerRingLEDs int
+	TimerRing     peripheral.StripConfig
+
+	// Optional electret microphone breakout on an ADC pin, machine.NoPin if not fitted
+	MicrophonePin machine.Pin
+
+	// Optional DMX512 output through an RS485 transceiver, UART is nil if not fitted,
+	// and the fixtures it drives, each showing the color of one panel section
+	DMX         dmx.PortConfig
+	DMXFixtures []dmx.Fixture
+}
+
+// OperatorCombos times the hidden gestures an operator can make on the panel; 0 disables one
+type OperatorCombos struct {
+	SelfTestHold    time.Duration // Reset and the first battery connect held this long run a self-test
+	EasterEggWindow time.Duration // The first three battery connects pressed in order, each within this of the last, play the easter egg
+}
+
+// AnalogInput assigns an ADC pin to what it controls
+type AnalogInput struct {
+	Pin  machine.Pin
+	Role peripheral.AnalogRole
+}
+
+// Default returns the profile for the board the firmware is being built for
+func Default() HardwareConfig {
+	return boardDefault()
+}
@@ -0,0 +1,899 @@
+// Package console implements a line-based command console over a serial port
+// (USB CDC on the board) for bench testing and show operation without physical buttons.
+//
+// Example commands:
+//
+//	status
+//	battery 2 drain
+//	battery 2 stop
+//	battery 2 reset
+//	battery reset
+//	battery pause
+//	override 3 off
+//	pattern spin
+//	pattern fire cooling=70 delay=40
+//	panel pause
+//	panel win
+//	replay 20
+//	log 10
+//	timescale 2
+//	readout 3
+//	selftest
+//	whitebalance 1 1.0 0.85 0.9
+//	capture history 100
+//	capture dump
+//	inputs record
+//	inputs play
+//	timer start 45
+//	timer add 120
+//	relay on 5
+//	relay off
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/logger"
+	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/patterns"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/timer"
+)
+
+// Port is the serial connection the console talks over; machine.Serial satisfies it
+type Port interface {
+	Buffered() int
+	ReadByte() (byte, error)
+	Write(data []byte) (n int, err error)
+}
+
+// Command is a console command handler
+type Command struct {
+	Name  string
+	Usage string
+	Run   func(args []string) string
+}
+
+// Console reads command lines from a Port and dispatches them
+type Console struct {
+	mu       sync.Mutex
+	port     Port
+	commands map[string]Command
+	line     []byte
+
+	// Inputs driven by console commands
+	batteryInputs []*peripheral.MockButton
+	batteryResets []*peripheral.MockButton
+	resetInput    *peripheral.MockButton
+
+	// Targets for commands, nil until attached
+	panel          *panel.Panel
+	patternManager *patterns.PatternManager
+	readout        *panel.BatteryReadout
+	strips         []*peripheral.ColorLedStrip
+	inputRecorder  *peripheral.InputRecorder
+	inputReplayer  *peripheral.InputReplayer
+	loadedInputs   []peripheral.InputEvent // Recording loaded line by line with inputs add
+	timer          *timer.Timer
+	relay          *peripheral.Relay
+	logger         *logger.Logger
+}
+
+// New creates a console on port for a panel with numBatteries batteries
+func New(port Port, numBatteries int) *Console {
+	c := &Console{
+		port:          port,
+		commands:      make(map[string]Command),
+		batteryInputs: make([]*peripheral.MockButton, numBatteries),
+		batteryResets: make([]*peripheral.MockButton, numBatteries),
+		resetInput:    peripheral.NewMockButton(),
+	}
+	for i := range c.batteryInputs {
+		c.batteryInputs[i] = peripheral.NewMockButton()
+		c.batteryResets[i] = peripheral.NewMockButton()
+	}
+
+	c.Register(Command{Name: "help", Usage: "help", Run: c.runHelp})
+	c.Register(Command{Name: "status", Usage: "status", Run: c.runStatus})
+	c.Register(Command{Name: "battery", Usage: "battery <n> drain|stop|reset, battery reset|pause|resume", Run: c.runBattery})
+	c.Register(Command{Name: "pattern", Usage: "pattern <name> [key=value ...]|off", Run: c.runPattern})
+	c.Register(Command{Name: "panel", Usage: "panel pause|resume|abort|win|lose", Run: c.runPanel})
+	c.Register(Command{Name: "replay", Usage: "replay <seconds> [minutes of history]", Run: c.runReplay})
+	c.Register(Command{Name: "log", Usage: "log [count]", Run: c.runLog})
+	c.Register(Command{Name: "timescale", Usage: "timescale [factor]", Run: c.runTimeScale})
+	c.Register(Command{Name: "readout", Usage: "readout <n>|all", Run: c.runReadout})
+	c.Register(Command{Name: "selftest", Usage: "selftest", Run: c.runSelfTest})
+	c.Register(Command{Name: "inputs", Usage: "inputs record|stop|dump|play|clear, inputs add <ms> <input> <value>", Run: c.runInputs})
+	c.Register(Command{Name: "override", Usage: "override <n> on|off, override clear", Run: c.runOverride})
+	c.Register(Command{Name: "timer", Usage: "timer [start [minutes]|pause|resume|add <seconds>|reset [minutes]]", Run: c.runTimer})
+	c.Register(Command{Name: "theme", Usage: "theme <name> [max brightness]", Run: c.runTheme})
+	c.Register(Command{Name: "whitebalance", Usage: "whitebalance [<strip> <r> <g> <b>]", Run: c.runWhiteBalance})
+	c.Register(Command{Name: "relay", Usage: "relay [on [seconds]|off]", Run: c.runRelay})
+	c.Register(Command{Name: "capture", Usage: "capture now|dump [<strip>], capture history <frames>", Run: c.runCapture})
+	return c
+}
+
+// SetPanel attaches the panel that status and replay commands act on
+func (c *Console) SetPanel(p *panel.Panel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.panel = p
+}
+
+// SetPatterns attaches the pattern manager that starts patterns from the pattern registry
+func (c *Console) SetPatterns(pm *patterns.PatternManager) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.patternManager = pm
+}
+
+// SetReadout attaches the seven-segment readout the readout command selects batteries on
+func (c *Console) SetReadout(r *panel.BatteryReadout) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readout = r
+}
+
+// SetStrips attaches the physical strips the whitebalance and capture commands act on, numbered from 1
+func (c *Console) SetStrips(strips ...*peripheral.ColorLedStrip) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.strips = strips
+}
+
+// SetInputs attaches the recorder and replayer the inputs command drives
+func (c *Console) SetInputs(recorder *peripheral.InputRecorder, replayer *peripheral.InputReplayer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.inputRecorder = recorder
+	c.inputReplayer = replayer
+}
+
+// SetRelay attaches the payoff relay the relay command overrides
+func (c *Console) SetRelay(r *peripheral.Relay) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.relay = r
+}
+
+// SetTimer attaches the mission clock the timer command drives
+func (c *Console) SetTimer(t *timer.Timer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timer = t
+}
+
+// SetLogger attaches the logger whose recent entries the log command prints
+func (c *Console) SetLogger(l *logger.Logger) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logger = l
+}
+
+// BatteryInput returns the console-driven connect input for a battery (0-based)
+// Combine it with the physical input using peripheral.AnyPressed.
+func (c *Console) BatteryInput(batteryIndex int) peripheral.ButtonReader {
+	return c.batteryInputs[batteryIndex]
+}
+
+// BatteryResetInput returns the console-driven reset input for a single battery (0-based)
+func (c *Console) BatteryResetInput(batteryIndex int) peripheral.ButtonReader {
+	return c.batteryResets[batteryIndex]
+}
+
+// ResetInput returns the console-driven battery reset input
+func (c *Console) ResetInput() peripheral.ButtonReader {
+	return c.resetInput
+}
+
+// Register adds or replaces a command
+func (c *Console) Register(cmd Command) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.commands[cmd.Name] = cmd
+}
+
+// Run reads and executes commands until the context is cancelled
+func (c *Console) Run(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	c.write("tinyspacewalk console, type help\r\n> ")
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for c.port.Buffered() > 0 {
+				b, err := c.port.ReadByte()
+				if err != nil {
+					break
+				}
+				c.handleByte(b)
+			}
+		}
+	}
+}
+
+// handleByte echoes input and executes the line on enter
+func (c *Console) handleByte(b byte) {
+	switch b {
+	case '\r', '\n':
+		if len(c.line) == 0 {
+			return
+		}
+		c.write("\r\n")
+		output := c.Execute(string(c.line))
+		c.line = c.line[:0]
+		if output != "" {
+			c.write(strings.ReplaceAll(output, "\n", "\r\n") + "\r\n")
+		}
+		c.write("> ")
+	case 0x08, 0x7f: // Backspace and delete
+		if len(c.line) > 0 {
+			c.line = c.line[:len(c.line)-1]
+			c.write("\b \b")
+		}
+	default:
+		c.line = append(c.line, b)
+		c.port.Write([]byte{b})
+	}
+}
+
+// Execute runs a single command line and returns its output
+func (c *Console) Execute(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return ""
+	}
+
+	c.mu.Lock()
+	cmd, ok := c.commands[fields[0]]
+	c.mu.Unlock()
+
+	if !ok {
+		return "unknown command: " + fields[0]
+	}
+	return cmd.Run(fields[1:])
+}
+
+// write sends a string to the port
+func (c *Console) write(s string) {
+	c.port.Write([]byte(s))
+}
+
+// runHelp lists all registered commands
+func (c *Console) runHelp(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	usages := make([]string, 0, len(c.commands))
+	for _, cmd := range c.commands {
+		usages = append(usages, cmd.Usage)
+	}
+	sort.Strings(usages)
+	return strings.Join(usages, "\n")
+}
+
+// runStatus prints the state and level of every battery
+func (c *Console) runStatus(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+
+	var sb strings.Builder
+	for i, info := range p.GetAllBatteryInfo() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		fmt.Fprintf(&sb, "battery %d: %s %.1f%%", i+1, info.State, info.BatteryLevel)
+		if info.Paused {
+			sb.WriteString(" (paused)")
+		}
+		if pressed, ok := p.InputOverride(i); ok {
+			if pressed {
+				sb.WriteString(" (override on)")
+			} else {
+				sb.WriteString(" (override off)")
+			}
+		}
+	}
+	if info, ok := p.GetAirLockInfo(); ok {
+		fmt.Fprintf(&sb, "\nairlock: %s (%d batteries powered)", info.State, info.PoweredBatteries)
+	}
+	c.mu.Lock()
+	t := c.timer
+	c.mu.Unlock()
+	if t != nil {
+		info := t.GetInfo()
+		fmt.Fprintf(&sb, "\ntimer: %s %s", info.State, info)
+	}
+	fmt.Fprintf(&sb, "\npanel: %s", p.Status())
+	stats := p.Stats()
+	fmt.Fprintf(&sb, "\nframes: %d every %v, avg %v, max %v, %d dropped",
+		stats.Frames, stats.UpdateRate, stats.AvgFrameTime, stats.MaxFrameTime, stats.Dropped)
+	for _, failing := range p.Failing() {
+		fmt.Fprintf(&sb, "\nfailing: %s", failing)
+	}
+	return sb.String()
+}
+
+// runBattery drives the console battery inputs
+func (c *Console) runBattery(args []string) string {
+	if len(args) == 1 {
+		switch args[0] {
+		case "reset":
+			pressBriefly(c.resetInput)
+			return "resetting all batteries"
+		case "pause", "resume":
+			c.mu.Lock()
+			p := c.panel
+			c.mu.Unlock()
+			if p == nil {
+				return "no panel attached"
+			}
+			if args[0] == "pause" {
+				p.PauseAll()
+				return "batteries frozen"
+			}
+			p.ResumeAll()
+			return "batteries resumed"
+		}
+	}
+
+	if len(args) != 2 {
+		return "usage: battery <n> drain|stop|reset, battery reset|pause|resume"
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(c.batteryInputs) {
+		return fmt.Sprintf("battery must be 1-%d", len(c.batteryInputs))
+	}
+
+	switch args[1] {
+	case "drain":
+		c.batteryInputs[n-1].SetPressed(true)
+	case "stop":
+		c.batteryInputs[n-1].SetPressed(false)
+	case "reset":
+		pressBriefly(c.batteryResets[n-1])
+	default:
+		return "usage: battery <n> drain|stop|reset"
+	}
+	return fmt.Sprintf("battery %d %s", n, args[1])
+}
+
+// pressBriefly holds an input long enough for the panel to see it, then releases it
+func pressBriefly(input *peripheral.MockButton) {
+	input.SetPressed(true)
+	time.AfterFunc(time.Second, func() {
+		input.SetPressed(false)
+	})
+}
+
+// runPattern starts a registered pattern, tuned by key=value arguments, or stops the current one;
+// while a pattern runs, an attached panel is paused so the two don't fight over the strip
+func (c *Console) runPattern(args []string) string {
+	c.mu.Lock()
+	pm := c.patternManager
+	p := c.panel
+	c.mu.Unlock()
+
+	if pm == nil {
+		return "no pattern manager attached"
+	}
+	if len(args) < 1 {
+		return "usage: pattern <name> [key=value ...]|off"
+	}
+
+	if args[0] == "off" {
+		pm.StopPattern()
+		if p != nil {
+			p.Resume()
+		}
+		return "pattern stopped"
+	}
+
+	params := make(patterns.Params, len(args)-1)
+	for _, arg := range args[1:] {
+		key, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return "usage: pattern <name> [key=value ...]|off"
+		}
+		params[key] = value
+	}
+
+	pattern, err := patterns.Create(args[0], params)
+	if errors.Is(err, patterns.ErrUnknownPattern) {
+		return "patterns: " + strings.Join(patterns.Names(), ", ")
+	}
+	if err != nil {
+		return "error: " + err.Error()
+	}
+
+	if p != nil {
+		p.Pause()
+	}
+	if err := pm.StartPattern(pattern); err != nil {
+		// Nothing took the strip, so hand it back to the panel
+		if p != nil {
+			p.Resume()
+		}
+		return "error: " + err.Error()
+	}
+	return "pattern " + args[0]
+}
+
+// runPanel pauses, resumes or aborts the panel
+func (c *Console) runPanel(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	pm := c.patternManager
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+	if len(args) != 1 {
+		return "usage: panel pause|resume|abort|win|lose"
+	}
+
+	switch args[0] {
+	case "pause":
+		p.Pause()
+		return "panel paused"
+	case "resume":
+		p.Resume()
+		return "panel resumed"
+	case "abort":
+		p.Abort()
+		return "panel aborted, battery reset to recover"
+	case "win", "lose":
+		// The finale needs the strip to itself
+		if pm != nil {
+			pm.StopPattern()
+		}
+		p.PlayFinale(args[0] == "win")
+		return "playing " + args[0] + " finale"
+	default:
+		return "usage: panel pause|resume|abort|win|lose"
+	}
+}
+
+// runOverride holds a battery's connect input on or off over whatever the real
+// input reads, for testing installed hardware, or hands every input back
+func (c *Console) runOverride(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+	if len(args) == 1 && args[0] == "clear" {
+		p.ClearOverrides()
+		return "input overrides cleared"
+	}
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		return "usage: override <n> on|off, override clear"
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(c.batteryInputs) {
+		return fmt.Sprintf("battery must be 1-%d", len(c.batteryInputs))
+	}
+	p.SetInputOverride(n-1, args[1] == "on")
+	return fmt.Sprintf("battery %d connect held %s", n, args[1])
+}
+
+// runTheme restyles the panel with a built-in theme, optionally capping its brightness
+func (c *Console) runTheme(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return "usage: theme <name> [max brightness], themes: " + strings.Join(panel.ThemeNames(), ", ")
+	}
+
+	theme, err := panel.BuiltinTheme(args[0])
+	if err != nil {
+		return "themes: " + strings.Join(panel.ThemeNames(), ", ")
+	}
+	if len(args) == 2 {
+		limit, err := strconv.Atoi(args[1])
+		if err != nil || limit < 1 || limit > 255 {
+			return "max brightness must be 1-255"
+		}
+		theme.MaxBrightness = uint8(limit)
+	}
+	p.SetTheme(theme)
+	return "theme " + strings.Join(args, " ")
+}
+
+// runSelfTest runs the panel self-test, taking over the strip for a few seconds
+func (c *Console) runSelfTest(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+
+	result := p.SelfTest()
+	if len(result.Connected) > 0 {
+		return fmt.Sprintf("%v\nconnected throughout: %v", result, result.Connected)
+	}
+	return result.String()
+}
+
+// runReplay plays back recent battery history on the panel
+func (c *Console) runReplay(args []string) string {
+	c.mu.Lock()
+	p := c.panel
+	c.mu.Unlock()
+
+	if p == nil {
+		return "no panel attached"
+	}
+	if len(args) < 1 || len(args) > 2 {
+		return "usage: replay <seconds> [minutes of history]"
+	}
+
+	seconds, err := strconv.Atoi(args[0])
+	if err != nil || seconds <= 0 {
+		return "seconds must be a positive number"
+	}
+	minutes := 60
+	if len(args) == 2 {
+		minutes, err = strconv.Atoi(args[1])
+		if err != nil || minutes <= 0 {
+			return "minutes must be a positive number"
+		}
+	}
+
+	p.ReplayHistory(time.Duration(minutes)*time.Minute, time.Duration(seconds)*time.Second)
+	return fmt.Sprintf("replaying last %d minutes in %d seconds", minutes, seconds)
+}
+
+// runLog prints the most recent log entries
+func (c *Console) runLog(args []string) string {
+	c.mu.Lock()
+	l := c.logger
+	c.mu.Unlock()
+
+	if l == nil {
+		return "no logger attached"
+	}
+
+	entries := l.Entries()
+	if len(args) > 0 {
+		count, err := strconv.Atoi(args[0])
+		if err != nil || count < 1 {
+			return "usage: log [count]"
+		}
+		if count < len(entries) {
+			entries = entries[len(entries)-count:]
+		}
+	}
+
+	if len(entries) == 0 {
+		return "log is empty"
+	}
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// runTimeScale shows or sets the pace of the whole simulation, e.g. 2 for a double-speed rehearsal
+func (c *Console) runTimeScale(args []string) string {
+	if len(args) == 0 {
+		return fmt.Sprintf("time scale %.2g", battery.TimeScale())
+	}
+
+	scale, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || scale < 0 {
+		return "usage: timescale [factor]"
+	}
+	battery.SetTimeScale(scale)
+	return fmt.Sprintf("time scale %.2g", scale)
+}
+
+// runReadout selects the battery shown on the seven-segment readout
+func (c *Console) runReadout(args []string) string {
+	c.mu.Lock()
+	r := c.readout
+	c.mu.Unlock()
+
+	if r == nil {
+		return "no readout attached"
+	}
+	if len(args) != 1 {
+		return "usage: readout <n>|all"
+	}
+
+	if args[0] == "all" {
+		r.Select(panel.ReadoutAll)
+		return "readout rotating through all batteries"
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(c.batteryInputs) {
+		return fmt.Sprintf("battery must be 1-%d", len(c.batteryInputs))
+	}
+	r.Select(n - 1)
+	return fmt.Sprintf("readout showing battery %d", n)
+}
+
+// runWhiteBalance shows every strip's calibration, or sets one strip's red, green and blue
+// multipliers so it can be matched by eye against the others
+func (c *Console) runWhiteBalance(args []string) string {
+	c.mu.Lock()
+	strips := c.strips
+	c.mu.Unlock()
+
+	if len(strips) == 0 {
+		return "no strips attached"
+	}
+	if len(args) == 0 {
+		lines := make([]string, len(strips))
+		for i, strip := range strips {
+			wb := strip.WhiteBalance()
+			lines[i] = fmt.Sprintf("strip %d: r=%.2f g=%.2f b=%.2f", i+1, wb.R, wb.G, wb.B)
+		}
+		return strings.Join(lines, "\n")
+	}
+	if len(args) != 4 {
+		return "usage: whitebalance [<strip> <r> <g> <b>]"
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 || n > len(strips) {
+		return fmt.Sprintf("strip must be 1-%d", len(strips))
+	}
+	var factors [3]float32
+	for i, arg := range args[1:] {
+		f, err := strconv.ParseFloat(arg, 32)
+		if err != nil || f < 0 || f > 4 {
+			return "multipliers must be 0-4"
+		}
+		factors[i] = float32(f)
+	}
+
+	strips[n-1].SetWhiteBalance(peripheral.WhiteBalance{R: factors[0], G: factors[1], B: factors[2]})
+	return fmt.Sprintf("strip %d: r=%.2f g=%.2f b=%.2f", n, factors[0], factors[1], factors[2])
+}
+
+// runCapture prints a strip's current frame or its recorded history as hex rows,
+// one per frame, which cmd/framepng turns into a PNG, and sets how many frames
+// every strip keeps
+func (c *Console) runCapture(args []string) string {
+	c.mu.Lock()
+	strips := c.strips
+	c.mu.Unlock()
+
+	if len(strips) == 0 {
+		return "no strips attached"
+	}
+	if len(args) == 0 || len(args) > 2 {
+		return "usage: capture now|dump [<strip>], capture history <frames>"
+	}
+
+	if args[0] == "history" {
+		if len(args) != 2 {
+			return "usage: capture history <frames>"
+		}
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 || n > 1000 {
+			return "frames must be 0-1000"
+		}
+		for _, strip := range strips {
+			strip.SetFrameHistory(n)
+		}
+		if n == 0 {
+			return "frame history off"
+		}
+		return fmt.Sprintf("keeping the last %d frames", n)
+	}
+
+	strip := strips[0]
+	if len(args) == 2 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 1 || n > len(strips) {
+			return fmt.Sprintf("strip must be 1-%d", len(strips))
+		}
+		strip = strips[n-1]
+	}
+
+	switch args[0] {
+	case "now":
+		return strip.CaptureFrame().String()
+	case "dump":
+		frames := strip.FrameHistory()
+		if len(frames) == 0 {
+			return "no frames recorded, see capture history"
+		}
+		lines := make([]string, len(frames))
+		for i, frame := range frames {
+			lines[i] = frame.String()
+		}
+		return strings.Join(lines, "\n")
+	}
+	return "usage: capture now|dump [<strip>], capture history <frames>"
+}
+
+// runInputs records live input changes, dumps them, and replays them. A recording
+// captured from the serial output is loaded back by pasting its "inputs add" lines.
+func (c *Console) runInputs(args []string) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.inputRecorder == nil || c.inputReplayer == nil {
+		return "no input recorder attached"
+	}
+	if len(args) == 0 {
+		return "usage: inputs record|stop|dump|play|clear, inputs add <ms> <input> <value>"
+	}
+
+	switch args[0] {
+	case "record":
+		c.inputRecorder.StartRecording()
+		return "recording inputs"
+	case "stop":
+		if c.inputReplayer.IsPlaying() {
+			c.inputReplayer.Stop()
+			return "replay stopped"
+		}
+		events := c.inputRecorder.StopRecording()
+		return fmt.Sprintf("recorded %d input events", len(events))
+	case "dump":
+		events := c.inputRecorder.Events()
+		if len(events) == 0 {
+			return "no inputs recorded"
+		}
+		lines := make([]string, len(events))
+		for i, event := range events {
+			lines[i] = "inputs add " + event.String()
+		}
+		return strings.Join(lines, "\n")
+	case "add":
+		event, err := peripheral.ParseInputEvent(strings.Join(args[1:], " "))
+		if err != nil {
+			return err.Error()
+		}
+		c.loadedInputs = append(c.loadedInputs, event)
+		return ""
+	case "clear":
+		c.loadedInputs = nil
+		return "loaded inputs cleared"
+	case "play":
+		// Lines pasted with inputs add take precedence over the last recording
+		events := c.loadedInputs
+		if len(events) == 0 {
+			events = c.inputRecorder.Events()
+		}
+		if len(events) == 0 {
+			return "no inputs to replay"
+		}
+		if c.inputRecorder.IsRecording() {
+			return "stop recording before replaying"
+		}
+		if !c.inputReplayer.Play(events) {
+			return "replay already running"
+		}
+		return fmt.Sprintf("replaying %d input events", len(events))
+	default:
+		return "usage: inputs record|stop|dump|play|clear, inputs add <ms> <input> <value>"
+	}
+}
+
+// runRelay shows the payoff relay or overrides it by hand, e.g. to let the
+// players out early; it still releases itself after its max on time
+func (c *Console) runRelay(args []string) string {
+	c.mu.Lock()
+	r := c.relay
+	c.mu.Unlock()
+
+	if r == nil {
+		return "no relay attached"
+	}
+	if len(args) == 0 {
+		state := "released"
+		if r.IsEnergized() {
+			state = "energized"
+		}
+		return fmt.Sprintf("relay %s for %v, max on %v", state, time.Since(r.Since()).Round(time.Second), r.MaxOn())
+	}
+
+	switch args[0] {
+	case "on":
+		var d time.Duration
+		if len(args) > 1 {
+			seconds, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || seconds <= 0 {
+				return "seconds must be a positive number"
+			}
+			d = time.Duration(seconds * float64(time.Second))
+		}
+		r.EnergizeFor(d)
+		if d <= 0 || d > r.MaxOn() {
+			d = r.MaxOn()
+		}
+		return fmt.Sprintf("relay energized for %v", d)
+	case "off":
+		r.Release()
+		return "relay released"
+	}
+	return "usage: relay [on [seconds]|off]"
+}
+
+// runTimer shows or drives the mission clock; times added are in seconds and
+// may be negative to take time away
+func (c *Console) runTimer(args []string) string {
+	c.mu.Lock()
+	t := c.timer
+	c.mu.Unlock()
+
+	if t == nil {
+		return "no timer attached"
+	}
+	usage := "usage: timer [start [minutes]|pause|resume|add <seconds>|reset [minutes]]"
+
+	if len(args) == 0 {
+		info := t.GetInfo()
+		return fmt.Sprintf("timer %s %s", info.State, info)
+	}
+
+	switch args[0] {
+	case "start", "reset":
+		var duration time.Duration
+		if len(args) == 2 {
+			minutes, err := strconv.ParseFloat(args[1], 64)
+			if err != nil || minutes <= 0 {
+				return usage
+			}
+			duration = time.Duration(minutes * float64(time.Minute))
+		}
+		if args[0] == "reset" || duration > 0 {
+			t.Reset(duration)
+		}
+		if args[0] == "start" {
+			t.Start()
+		}
+	case "pause":
+		t.Pause()
+	case "resume":
+		t.Resume()
+	case "add":
+		if len(args) != 2 {
+			return usage
+		}
+		seconds, err := strconv.Atoi(args[1])
+		if err != nil {
+			return usage
+		}
+		t.AddTime(time.Duration(seconds) * time.Second)
+	default:
+		return usage
+	}
+
+	info := t.GetInfo()
+	return fmt.Sprintf("timer %s %s", info.State, info)
+}
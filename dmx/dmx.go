@@ -0,0 +1,248 @@
+// Package dmx mirrors the panel onto DMX512 stage fixtures, so a venue's
+// lights can follow the batteries. Each fixture in the mapping table takes
+// the color of one panel section on its own block of channels.
+package dmx
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// Universe is the number of channels in a DMX512 universe
+const Universe = 512
+
+// minSlots is the fewest channels a frame may carry; shorter frames are padded
+const minSlots = 24
+
+// Port sends DMX frames, e.g. a UART driving an RS485 transceiver
+type Port interface {
+	SendBreak() error               // Break and mark-after-break starting a frame
+	Write(data []byte) (int, error) // Start code and channel values
+}
+
+// Footprint is the channel layout of a fixture
+type Footprint int
+
+const (
+	RGB       Footprint = iota // Red, green, blue
+	RGBW                       // Red, green, blue and white, with the white taken from the common part of the color
+	DimmerRGB                  // Master dimmer followed by red, green, blue
+	Dimmer                     // Single brightness channel, e.g. a par can with a fixed gel
+)
+
+// Channels returns the number of channels the footprint uses
+func (f Footprint) Channels() int {
+	switch f {
+	case RGBW, DimmerRGB:
+		return 4
+	case Dimmer:
+		return 1
+	default:
+		return 3
+	}
+}
+
+// String returns the footprint's name
+func (f Footprint) String() string {
+	switch f {
+	case RGB:
+		return "RGB"
+	case RGBW:
+		return "RGBW"
+	case DimmerRGB:
+		return "DimmerRGB"
+	case Dimmer:
+		return "Dimmer"
+	default:
+		return "Unknown"
+	}
+}
+
+// Fixture maps one panel section onto a fixture's channels
+type Fixture struct {
+	Section   int // Panel section (battery, 0-based) whose color the fixture shows
+	Channel   int // First DMX channel of the fixture, 1-512 as set on its address switches
+	Footprint Footprint
+}
+
+// Source provides the colors to mirror, e.g. a *panel.Panel
+type Source interface {
+	SectionColors() []color.RGBA
+}
+
+// Config configures a Bridge
+type Config struct {
+	Fixtures    []Fixture
+	RefreshRate time.Duration // Time between frames; fixtures hold their last frame for about a second
+
+	// Gain multiplies the section colors into DMX channel values, saturating at
+	// 255. The panel draws in full range, so 1 sends a full bar at full output;
+	// raise it to lift dim sections, e.g. a half empty bar averaging to half.
+	Gain float32
+}
+
+// DefaultConfig returns a bridge sending fixtures at 30 frames per second with unity gain
+func DefaultConfig(fixtures []Fixture) Config {
+	return Config{
+		Fixtures:    fixtures,
+		RefreshRate: 33 * time.Millisecond,
+		Gain:        1,
+	}
+}
+
+// Bridge sends the source's colors to the fixtures on a port
+type Bridge struct {
+	mu     sync.Mutex
+	port   Port
+	source Source
+	config Config
+	frame  []byte // Start code followed by the channel values
+	errors int
+
+	// Ticker for sending frames
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewBridge creates a bridge sending source's colors to port
+func NewBridge(port Port, source Source, config Config) *Bridge {
+	if config.RefreshRate <= 0 {
+		config.RefreshRate = DefaultConfig(nil).RefreshRate
+	}
+	if config.Gain <= 0 {
+		config.Gain = DefaultConfig(nil).Gain
+	}
+
+	// Only send as many channels as the fixtures use, for a faster frame rate
+	slots := minSlots
+	for _, f := range config.Fixtures {
+		slots = max(slots, f.Channel-1+f.Footprint.Channels())
+	}
+	slots = min(slots, Universe)
+
+	return &Bridge{
+		port:   port,
+		source: source,
+		config: config,
+		frame:  make([]byte, 1+slots),
+	}
+}
+
+// Start begins sending frames at the configured refresh rate
+func (b *Bridge) Start() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return
+	}
+	b.running = true
+	b.ticker = time.NewTicker(b.config.RefreshRate)
+	b.stopTicker = make(chan struct{})
+
+	ticker, stop := b.ticker, b.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				b.Send()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sending frames after blacking out the fixtures
+func (b *Bridge) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		close(b.stopTicker)
+		b.ticker.Stop()
+		b.running = false
+		clear(b.frame)
+		b.write()
+	}
+}
+
+// Send updates the frame from the source and sends it
+func (b *Bridge) Send() error {
+	colors := b.source.SectionColors()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, f := range b.config.Fixtures {
+		if f.Section < 0 || f.Section >= len(colors) {
+			continue
+		}
+		b.set(f, b.scale(colors[f.Section]))
+	}
+	return b.write()
+}
+
+// Frame returns a copy of the channel values last sent, channel 1 first
+func (b *Bridge) Frame() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.frame[1:]...)
+}
+
+// Errors returns the number of frames the port failed to send
+func (b *Bridge) Errors() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.errors
+}
+
+// scale applies the configured gain to a section color (must be called with mutex locked)
+func (b *Bridge) scale(c color.RGBA) color.RGBA {
+	if b.config.Gain == 1 {
+		return c
+	}
+	channel := func(v uint8) uint8 {
+		return uint8(min(float32(v)*b.config.Gain+0.5, 255))
+	}
+	return color.RGBA{R: channel(c.R), G: channel(c.G), B: channel(c.B), A: c.A}
+}
+
+// set writes a color into a fixture's channels, ignoring channels outside the frame
+// (must be called with mutex locked)
+func (b *Bridge) set(f Fixture, c color.RGBA) {
+	var values []byte
+	switch f.Footprint {
+	case RGBW:
+		w := min(c.R, c.G, c.B)
+		values = []byte{c.R - w, c.G - w, c.B - w, w}
+	case DimmerRGB:
+		values = []byte{255, c.R, c.G, c.B}
+	case Dimmer:
+		values = []byte{max(c.R, c.G, c.B)}
+	default:
+		values = []byte{c.R, c.G, c.B}
+	}
+
+	for i, v := range values {
+		slot := f.Channel + i // The start code is slot 0, so channel n is frame[n]
+		if slot >= 1 && slot < len(b.frame) {
+			b.frame[slot] = v
+		}
+	}
+}
+
+// write sends the frame to the port
+// (must be called with mutex locked)
+func (b *Bridge) write() error {
+	err := b.port.SendBreak()
+	if err == nil {
+		_, err = b.port.Write(b.frame)
+	}
+	if err != nil {
+		b.errors++
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+//go:build tinygo
+
+package dmx
+
+import (
+	"machine"
+	"time"
+)
+
+const (
+	baudRate       = 250000
+	breakTime      = 100 * time.Microsecond // At least 88µs
+	markAfterBreak = 12 * time.Microsecond  // At least 8µs
+)
+
+// PortConfig describes a UART wired to an RS485 transceiver, e.g. a MAX485
+type PortConfig struct {
+	UART   *machine.UART // nil if no transceiver is fitted
+	TX     machine.Pin
+	RX     machine.Pin
+	Enable machine.Pin // Transceiver driver enable, machine.NoPin if tied high
+}
+
+// UARTPort sends DMX frames on a UART
+type UARTPort struct {
+	config PortConfig
+}
+
+// NewUARTPort configures the UART and enables the transceiver's driver
+func NewUARTPort(config PortConfig) (*UARTPort, error) {
+	p := &UARTPort{config: config}
+	if err := p.configure(); err != nil {
+		return nil, err
+	}
+	if config.Enable != machine.NoPin {
+		config.Enable.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		config.Enable.High()
+	}
+	return p, nil
+}
+
+// SendBreak holds the line low for a break then high for the mark after break. The UART
+// can't send a break itself, so the TX pin is driven directly and handed back afterwards.
+func (p *UARTPort) SendBreak() error {
+	p.config.TX.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	p.config.TX.Low()
+	time.Sleep(breakTime)
+	p.config.TX.High()
+	time.Sleep(markAfterBreak)
+	return p.configure()
+}
+
+// Write sends the start code and channel values
+func (p *UARTPort) Write(data []byte) (int, error) {
+	return p.config.UART.Write(data)
+}
+
+// configure sets the UART up for DMX: 250k baud, 8 data bits, no parity, 2 stop bits
+func (p *UARTPort) configure() error {
+	err := p.config.UART.Configure(machine.UARTConfig{BaudRate: baudRate, TX: p.config.TX, RX: p.config.RX})
+	if err != nil {
+		return err
+	}
+	return p.config.UART.SetFormat(8, 2, machine.ParityNone)
+}
@@ -3,7 +3,8 @@ module github.com/christophergm/tinyspacewalk
 go 1.23.3
 
 require (
-	github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
-	golang.org/x/exp v0.0.0-20241217172543-b2144cdd0a67 // indirect
-	tinygo.org/x/drivers v0.29.0 // indirect
+	golang.org/x/exp v0.0.0-20241217172543-b2144cdd0a67
+	tinygo.org/x/drivers v0.29.0
 )
+
+require github.com/google/shlex v0.0.0-20191202100458-e7afc7fbc510 // indirect
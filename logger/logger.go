@@ -1,21 +1,216 @@
+// Package logger writes leveled log messages to a serial port, keeps the most
+// recent entries in memory for the console, and can blink the onboard NeoPixel
+// so problems are visible without a serial connection
 package logger
 
 import (
-	"github.com/chris/tinyspacewalk/peripheral"
+	"fmt"
+	"image/color"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelOff // Disables output or blinking when used as a threshold
 )
 
+// String returns the level name
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "OFF"
+	}
+}
+
+// DefaultBufferSize is the number of entries kept for the console
+const DefaultBufferSize = 32
+
+// Entry is a single log message
+type Entry struct {
+	Elapsed time.Duration // Time since the logger was created
+	Level   Level
+	Message string
+}
+
+// String formats an entry as a log line without a line ending
+func (e Entry) String() string {
+	return fmt.Sprintf("[%9.3f] %-5s %s", e.Elapsed.Seconds(), e.Level, e.Message)
+}
+
+// Config holds the logger configuration
+type Config struct {
+	Output     io.Writer            // Usually machine.Serial, nil to disable
+	Level      Level                // Minimum level written to Output and the buffer
+	Pixel      *peripheral.NeoPixel // Optional NeoPixel for blink codes
+	BlinkLevel Level                // Minimum level that blinks the NeoPixel
+	BufferSize int                  // Number of recent entries kept
+}
+
+// DefaultConfig returns a configuration logging Info and above, blinking on Warn and above
+func DefaultConfig(output io.Writer, pixel *peripheral.NeoPixel) Config {
+	return Config{
+		Output:     output,
+		Level:      LevelInfo,
+		Pixel:      pixel,
+		BlinkLevel: LevelWarn,
+		BufferSize: DefaultBufferSize,
+	}
+}
+
+// blinkCode is how a level is shown on the NeoPixel
+type blinkCode struct {
+	color  color.RGBA
+	blinks int
+}
+
+var blinkCodes = map[Level]blinkCode{
+	LevelDebug: {color.RGBA{B: 255, A: 255}, 1},
+	LevelInfo:  {color.RGBA{G: 255, A: 255}, 1},
+	LevelWarn:  {color.RGBA{R: 255, G: 160, A: 255}, 2},
+	LevelError: {color.RGBA{R: 255, A: 255}, 3},
+}
+
+// blinkInterval is the on and off time of each blink
+const blinkInterval = 150 * time.Millisecond
+
+// Logger writes leveled messages to an output and keeps recent entries
 type Logger struct {
-	pixel peripheral.NeoPixel
+	mu        sync.Mutex
+	config    Config
+	createdAt time.Time
+	blinking  bool
+
+	// Ring buffer of recent entries
+	entries []Entry
+	next    int
+	count   int
 }
 
-func NewLogger(pixel peripheral.NeoPixel) *Logger {
+// NewLogger creates a logger with the given configuration
+func NewLogger(config Config) *Logger {
+	if config.BufferSize <= 0 {
+		config.BufferSize = DefaultBufferSize
+	}
 	return &Logger{
-		pixel: pixel,
+		config:    config,
+		createdAt: time.Now(),
+		entries:   make([]Entry, config.BufferSize),
+	}
+}
+
+// SetLevel sets the minimum level that is logged
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config.Level = level
+}
+
+// SetPixel changes the NeoPixel used for blink codes, nil to stop blinking,
+// e.g. once a status indicator takes over the pixel
+func (l *Logger) SetPixel(pixel *peripheral.NeoPixel) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config.Pixel = pixel
+}
+
+// Debug logs a formatted message at debug level
+func (l *Logger) Debug(format string, args ...interface{}) {
+	l.log(LevelDebug, format, args...)
+}
+
+// Info logs a formatted message at info level
+func (l *Logger) Info(format string, args ...interface{}) {
+	l.log(LevelInfo, format, args...)
+}
+
+// Warn logs a formatted message at warning level
+func (l *Logger) Warn(format string, args ...interface{}) {
+	l.log(LevelWarn, format, args...)
+}
+
+// Error logs a formatted message at error level
+func (l *Logger) Error(format string, args ...interface{}) {
+	l.log(LevelError, format, args...)
+}
+
+// Entries returns the buffered entries, oldest first
+func (l *Logger) Entries() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]Entry, 0, l.count)
+	start := l.next - l.count
+	if start < 0 {
+		start += len(l.entries)
+	}
+	for i := 0; i < l.count; i++ {
+		result = append(result, l.entries[(start+i)%len(l.entries)])
+	}
+	return result
+}
+
+// log records, writes, and blinks an entry if its level passes the thresholds
+func (l *Logger) log(level Level, format string, args ...interface{}) {
+	l.mu.Lock()
+	if level < l.config.Level {
+		l.mu.Unlock()
+		return
+	}
+
+	entry := Entry{
+		Elapsed: time.Since(l.createdAt),
+		Level:   level,
+		Message: fmt.Sprintf(format, args...),
+	}
+
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % len(l.entries)
+	if l.count < len(l.entries) {
+		l.count++
+	}
+
+	output := l.config.Output
+	pixel := l.config.Pixel
+	blink := pixel != nil && level >= l.config.BlinkLevel && !l.blinking
+	if blink {
+		l.blinking = true
+	}
+	l.mu.Unlock()
+
+	if output != nil {
+		output.Write([]byte(entry.String() + "\r\n"))
+	}
+	if blink {
+		go l.blink(pixel, blinkCodes[level])
 	}
 }
 
-// func (l *Logger) Blink(color color.RGBA) {
-// 	if level >= l.level {
-// 		l.pixel.SetColor(0, 0, 255)
-// 	}
-// }
+// blink shows a blink code on the NeoPixel; codes arriving mid-blink are dropped
+func (l *Logger) blink(pixel *peripheral.NeoPixel, code blinkCode) {
+	for i := 0; i < code.blinks; i++ {
+		pixel.SetColorAndPause(code.color, int(blinkInterval/time.Millisecond))
+		pixel.SetColorAndPause(color.RGBA{}, int(blinkInterval/time.Millisecond))
+	}
+
+	l.mu.Lock()
+	l.blinking = false
+	l.mu.Unlock()
+}
@@ -0,0 +1,13 @@
+//go:build tinygo && !netcontrol
+
+package main
+
+import "context"
+
+// startNetwork warns that network services were switched on in a build without
+// the netcontrol tag, which leaves out WiFi, HTTP, MQTT and pixelnet entirely
+func startNetwork(ctx context.Context, n network) {
+	if n.enabled() {
+		n.Log.Warn("network services need a build with -tags netcontrol")
+	}
+}
@@ -0,0 +1,55 @@
+//go:build tinygo && netcontrol
+
+package main
+
+import (
+	"context"
+
+	"github.com/christophergm/tinyspacewalk/mqtt"
+	"github.com/christophergm/tinyspacewalk/netcontrol"
+	"github.com/christophergm/tinyspacewalk/pixelnet"
+)
+
+// WiFi credentials and MQTT broker address, set at build time with
+// -ldflags "-X main.wifiSSID=... -X main.wifiPassphrase=... -X main.mqttBroker=host:1883"
+var (
+	wifiSSID       string
+	wifiPassphrase string
+	mqttBroker     string
+)
+
+// startNetwork joins WiFi and starts the enabled network services in the background
+func startNetwork(ctx context.Context, n network) {
+	if !n.enabled() {
+		return
+	}
+	if err := netcontrol.Connect(wifiSSID, wifiPassphrase); err != nil {
+		n.Log.Warn("WiFi unavailable: %v", err)
+		return
+	}
+
+	if n.Control {
+		go func() {
+			err := netcontrol.NewHandler(n.Console, n.Panel).ListenAndServe(":80")
+			n.Log.Error("control server stopped: %v", err)
+		}()
+	}
+	if n.MQTT {
+		mqttBridge := mqtt.NewBridge(n.Console, n.Panel, mqtt.DefaultBridgeConfig(mqttBroker))
+		if n.Heartbeat != nil {
+			n.Heartbeat.OnBeat(mqttBridge.PublishHeartbeat)
+		}
+		go mqttBridge.Run(ctx)
+	}
+	if n.Pixels {
+		endpoint := pixelnet.NewEndpoint(n.Panel, n.Patterns, n.NumLEDs, pixelnet.DefaultConfig(pixelnet.ArtNet))
+		endpoint.OnLive = func(live bool) {
+			n.Log.Info("lighting desk live: %v", live)
+		}
+		go func() {
+			if err := endpoint.Run(ctx); err != nil {
+				n.Log.Error("pixel endpoint stopped: %v", err)
+			}
+		}()
+	}
+}
@@ -1,17 +1,32 @@
+//go:build tinygo
+
 package main
 
 import (
 	"context"
 	"image/color"
+	"strconv"
 	"time"
 
 	"machine"
 
 	"golang.org/x/exp/rand"
 
+	"github.com/christophergm/tinyspacewalk/airlock"
+	"github.com/christophergm/tinyspacewalk/audio"
 	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/config"
+	"github.com/christophergm/tinyspacewalk/console"
+	"github.com/christophergm/tinyspacewalk/dmx"
+	"github.com/christophergm/tinyspacewalk/logger"
+	"github.com/christophergm/tinyspacewalk/mission"
 	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/patterns"
 	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/recovery"
+	"github.com/christophergm/tinyspacewalk/storage"
+	"github.com/christophergm/tinyspacewalk/telemetry"
+	"github.com/christophergm/tinyspacewalk/timer"
 )
 
 var (
@@ -27,67 +42,237 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Board wiring - pins and LED counts for the target board
+	hw := config.Default()
+
 	// Configuration - set to true to use real GPIO pins instead of demo mode
 	useRealPins := true
-	runDemoAllBatteries := false   // Only used when useRealPins is false
-	runDemoRandomBatteries := true // Only used when useRealPins is false
-
-	var neoPixel peripheral.NeoPixel
-	var boardYellowLight peripheral.BoardYellowLight
+	demoScenario := "random-batteries" // Built-in demo (see panel.DemoNames), only used when useRealPins is false
+	runMission := false                // Run the scripted show timeline
+	streamTelemetry := false           // Write JSON telemetry lines over USB serial
+	serveNetControl := false           // Serve the HTTP control API (needs the netcontrol build tag and a WiFi board)
+	bridgeMQTT := false                // Connect to the escape-room MQTT broker (needs WiFi as above)
+	servePixels := false               // Let a lighting desk drive the strip over Art-Net (needs WiFi as above)
+	sendHeartbeat := false             // Publish health heartbeats on USB serial, and MQTT when bridged, for room control
+	randomSeed := int64(0)             // Fixed seed for repeatable animations and demos, e.g. when recording; 0 varies every boot
+	oxygenDialBattery := -1            // Battery (0-based) the oxygen dial follows when fitted; -1 follows the mission clock
 
 	pauseMilliseconds := 300
 
 	// Use simpler seed to avoid overflow on microcontroller
 	rand.Seed(uint64(time.Now().Unix()))
 
-	neoPixel = peripheral.NeoPixel{}
-	neoPixel.Configure()
+	// Onboard indicators, and later the strip and elevator, are configured and started
+	// together and stopped on the way out; failures are logged once the logger is up
+	peripherals := peripheral.NewPeripheralManager()
+	defer peripherals.Stop()
 
-	boardYellowLight = peripheral.BoardYellowLight{}
-	boardYellowLight.Configure()
-	boardYellowLight.StartBlink()
+	// Peripherals failing after startup, e.g. SPI or I2C errors, are reported to the panel
+	faults := make(chan peripheral.Fault, 8)
+	peripherals.ReportFaults(faults)
 
-	// Cleanup function for board yellow light
-	defer func() {
-		boardYellowLight.StopBlink()
-	}()
+	neoPixel := peripheral.NewNeoPixel(hw.NeoPixelPin)
+	peripherals.Add("neopixel", neoPixel)
+	peripherals.Add("status LED", peripheral.NewBoardYellowLight(hw.StatusLEDPin))
+	peripheralErr := peripherals.Start(ctx)
 
 	neoPixel.SetColorAndPause(Off, pauseMilliseconds)
 
-	// Initialize LED strip with new structure
-	numLEDs := 144
-	ledStrip := peripheral.NewColorLedStrip(numLEDs)
-	if err := ledStrip.Configure(); err != nil {
+	log := logger.NewLogger(logger.DefaultConfig(machine.Serial, neoPixel))
+	if peripheralErr != nil {
+		log.Error("%v", peripheralErr)
+	}
+	log.Info("tinyspacewalk starting")
+	recovery.SetHandler(func(err error) {
+		log.Error("%v", err)
+	})
+
+	// Initialize LED strip on the board's SPI bus (or software SPI pins)
+	ledStrip, err := peripheral.NewColorLedStripFromConfig(hw.NumLEDs, hw.Strip)
+	if err != nil {
+		log.Error("LED strip configuration failed: %v", err)
 		neoPixel.SetColorAndPause(Red, pauseMilliseconds)
 		return // Exit on configuration error
 	}
 
-	// Create five batteries with default configuration
-	batteries := make([]*battery.Battery, 5)
-	for i := 0; i < 5; i++ {
-		batteries[i] = battery.NewBattery(battery.FastBatteryConfig())
+	if !hw.WhiteBalance.IsZero() {
+		ledStrip.SetWhiteBalance(hw.WhiteBalance)
+	}
+	peripherals.Add("LED strip", ledStrip)
+	if err := peripherals.Start(ctx); err != nil {
+		log.Error("%v", err)
+	}
+
+	// The panel and patterns draw a fixed number of pixels, scaled onto the strip fitted
+	var drawStrip peripheral.LedStrip = ledStrip
+	if hw.LogicalLEDs > 0 && hw.LogicalLEDs != hw.NumLEDs {
+		drawStrip = peripheral.NewVirtualStrip(hw.LogicalLEDs, ledStrip, hw.LogicalView)
+	}
+
+	// Create five batteries, each draining at its own speed so the puzzle has a
+	// clear order: battery 1 drains twice as fast as battery 5
+	batteryConfigs := []battery.Config{
+		battery.FastBatteryConfig().WithDrainSpeed(2),
+		battery.FastBatteryConfig().WithDrainSpeed(1.75),
+		battery.FastBatteryConfig().WithDrainSpeed(1.5),
+		battery.FastBatteryConfig().WithDrainSpeed(1.25),
+		battery.FastBatteryConfig(),
+	}
+
+	// Players flick real connect switches and lean on the reset button, so the prop
+	// filters switch chatter and lets a held reset go after a moment
+	if useRealPins {
+		for i := range batteryConfigs {
+			batteryConfigs[i] = batteryConfigs[i].WithSwitchFiltering().WithOverrideTimeout(battery.DefaultOverrideTimeout)
+		}
+	}
+
+	// With charger docks fitted, a disconnected battery only charges on its dock
+	if useRealPins && (len(hw.BatteryChargers) > 0 || len(hw.DockSensors) > 0) {
+		for i := range batteryConfigs {
+			batteryConfigs[i] = batteryConfigs[i].WithChargerInput()
+		}
+	}
+	batteries := battery.NewBatteries(batteryConfigs)
+
+	// The bank tracks how many batteries are alive for the airlock and missions
+	batteryBank := battery.NewBank(batteries)
+
+	// Resume battery levels from flash after a power cycle, then keep saving them
+	batteryStore := storage.NewStore(machine.Flash, batteries)
+	if err := batteryStore.Restore(); err != nil && err != storage.ErrNoSnapshot {
+		log.Warn("battery restore failed: %v", err)
+		neoPixel.SetColorAndPause(Yellow, pauseMilliseconds)
+	}
+	batteryStore.Start(30 * time.Second)
+	defer batteryStore.Stop()
+
+	// Every input change can be recorded with timestamps, echoed to the serial port
+	// as console lines, and fed back later through mock buttons to reproduce
+	// problems that only show up during a live show
+	inputRecorder := peripheral.NewInputRecorder(peripheral.InputRecorderConfig{
+		MaxEvents: 512,
+		Output:    machine.Serial,
+		Prefix:    "inputs add ",
+	})
+	inputReplayer := peripheral.NewInputReplayer()
+
+	// Analog inputs by role: the pattern speed slider backs the pattern helpers,
+	// brightness and drain rate knobs take effect as they move
+	analogInputs := peripheral.NewAnalogInputs(peripheral.DefaultAnalogInputsConfig())
+	for _, input := range hw.AnalogInputs {
+		reader := peripheral.NewADCReader(input.Pin, peripheral.AnalogConfig{Smoothing: 0.5})
+		if input.Role == peripheral.AnalogPatternSpeed {
+			peripheral.SetAnalogInput(reader)
+		}
+		analogInputs.Assign(input.Role, reader)
 	}
+	applyAnalog := func(change peripheral.AnalogChange) {
+		switch change.Role {
+		case peripheral.AnalogBrightness:
+			ledStrip.SetBrightness(uint8(max(change.Percentage, 1) * 255 / 100))
+		}
+	}
+	analogInputs.OnChange(func(change peripheral.AnalogChange) {
+		inputRecorder.RecordAnalog(change.Role.String(), change.Percentage)
+		applyAnalog(change)
+	})
+	analogInputs.Start(50 * time.Millisecond)
+	defer analogInputs.Stop()
 
 	var batteryResetButton peripheral.ButtonReader
+	var batteryResetButtons []peripheral.ButtonReader
+	var airLockButton peripheral.ButtonReader
+	var abortSwitch peripheral.ButtonReader
+	var elevatorDoor peripheral.ButtonReader
 	var batteryConnects []peripheral.ButtonReader
+	var batteryChargers []peripheral.ButtonReader
 	var mockBatteryConnects []*peripheral.MockButton
 	var mockResetButton *peripheral.MockButton
 
 	if useRealPins {
-		// Configure real GPIO pins D0-D5
-		// D0: Board reset button
-		resetButton := peripheral.NewButton(machine.D40, true) // inverted - pressed when low
-		resetButton.Configure()
-		batteryResetButton = resetButton
+		// Optional GPIO expander for inputs beyond the board's spare pins
+		var expander *peripheral.GPIOExpander
+		if hw.Expander.Bus != nil {
+			var err error
+			if expander, err = peripheral.NewGPIOExpander(hw.Expander); err != nil {
+				log.Error("GPIO expander not responding: %v", err)
+			}
+		}
 
-		// D1-D5: Battery connect signals
-		batteryConnects = make([]peripheral.ButtonReader, 5)
-		pins := []machine.Pin{machine.D30, machine.D32, machine.D34, machine.D36, machine.D38}
+		// Optional capacitive touch controller for pads in place of mechanical buttons
+		var touch *peripheral.TouchSensor
+		if hw.Touch.Bus != nil {
+			var err error
+			if touch, err = peripheral.NewTouchSensorFromConfig(hw.Touch); err != nil {
+				log.Error("touch controller configuration failed: %v", err)
+			} else {
+				peripherals.Add("touch controller", touch)
+				if err := peripherals.Start(ctx); err != nil {
+					log.Error("%v", err)
+				}
+			}
+		}
+
+		// Configure real inputs from the board pin map
+		// Each input is debounced so contact bounce doesn't reach the panel
+		var debouncedInputs []*peripheral.DebouncedButton
+		defer func() {
+			for _, debounced := range debouncedInputs {
+				debounced.Stop()
+			}
+		}()
+		configureInput := func(name string, buttonConfig peripheral.ButtonConfig) peripheral.ButtonReader {
+			button, err := peripheral.ConfigureButton(buttonConfig, expander, touch)
+			if err != nil {
+				// Leave the input permanently released rather than stopping the show
+				log.Error("%s input: %v", name, err)
+				return peripheral.NewMockButton()
+			}
+			if reporter, ok := button.(peripheral.FaultReporter); ok {
+				reporter.ReportFaults(faults, name+" input")
+			}
+			debounced := peripheral.NewDebouncedButton(button, peripheral.DefaultDebounce)
+			debounced.Start(5 * time.Millisecond)
+			debouncedInputs = append(debouncedInputs, debounced)
+			return debounced
+		}
+
+		batteryResetButton = configureInput("reset", hw.ResetButton)
 
-		for i, pin := range pins {
-			button := peripheral.NewButton(pin, false) // inverted - pressed when low
-			button.Configure()
-			batteryConnects[i] = button
+		batteryConnects = make([]peripheral.ButtonReader, len(hw.BatteryConnects))
+		for i, buttonConfig := range hw.BatteryConnects {
+			batteryConnects[i] = configureInput("battery connect", buttonConfig)
+		}
+
+		batteryChargers = make([]peripheral.ButtonReader, len(hw.BatteryChargers))
+		for i, buttonConfig := range hw.BatteryChargers {
+			batteryChargers[i] = configureInput("battery charger", buttonConfig)
+		}
+
+		// Magnets in the batteries seated in their docks are sensed instead of dock switches
+		if len(hw.DockSensors) > 0 {
+			batteryChargers = make([]peripheral.ButtonReader, len(hw.DockSensors))
+			for i, sensorConfig := range hw.DockSensors {
+				dock := peripheral.NewMagneticSwitchFromConfig(sensorConfig)
+				peripherals.Add("battery "+strconv.Itoa(i+1)+" dock", dock)
+				batteryChargers[i] = dock
+			}
+			if err := peripherals.Start(ctx); err != nil {
+				log.Error("%v", err)
+			}
+		}
+
+		if hw.AirLockButton.Fitted() {
+			airLockButton = configureInput("airlock", hw.AirLockButton)
+		}
+
+		if hw.AbortSwitch.Fitted() {
+			abortSwitch = configureInput("abort", hw.AbortSwitch)
+		}
+
+		if hw.Elevator.Door.Fitted() {
+			elevatorDoor = configureInput("elevator door", hw.Elevator.Door)
 		}
 	} else {
 		// Create mock input handlers for demonstration
@@ -105,25 +290,392 @@ func main() {
 		}
 	}
 
+	// Operator gestures are only made on the physical inputs, never by remote or replayed presses
+	comboReset := batteryResetButton
+	comboConnects := append([]peripheral.ButtonReader(nil), batteryConnects...)
+
+	// Record the inputs as the prop sees them, before remote commands are mixed in
+	inputRecorder.AddButton("reset", batteryResetButton)
+	for i, connect := range batteryConnects {
+		inputRecorder.AddButton("connect"+strconv.Itoa(i+1), connect)
+	}
+	for i, charger := range batteryChargers {
+		inputRecorder.AddButton("charger"+strconv.Itoa(i+1), charger)
+	}
+	if airLockButton != nil {
+		inputRecorder.AddButton("airlock", airLockButton)
+	}
+	if abortSwitch != nil {
+		inputRecorder.AddButton("abort", abortSwitch)
+	}
+	inputRecorder.Start(10 * time.Millisecond)
+	defer inputRecorder.Stop()
+	defer inputReplayer.Stop()
+
+	// Serial console, mission engine and input replay - their inputs act alongside the
+	// physical ones. Demo mode keeps the raw mock buttons since the demos drive them directly.
+	serialConsole := console.New(machine.Serial, len(batteries))
+	missionEngine := mission.NewEngine(len(batteries))
+	if useRealPins {
+		batteryResetButton = peripheral.AnyPressed(batteryResetButton, serialConsole.ResetInput(), missionEngine.ResetInput(), inputReplayer.Button("reset"))
+		batteryResetButtons = make([]peripheral.ButtonReader, len(batteryConnects))
+		for i := range batteryConnects {
+			replayed := inputReplayer.Button("connect" + strconv.Itoa(i+1))
+			batteryConnects[i] = peripheral.AnyPressed(batteryConnects[i], serialConsole.BatteryInput(i), missionEngine.BatteryInput(i), replayed)
+			batteryResetButtons[i] = serialConsole.BatteryResetInput(i)
+		}
+		for i := range batteryChargers {
+			batteryChargers[i] = peripheral.AnyPressed(batteryChargers[i], inputReplayer.Button("charger"+strconv.Itoa(i+1)))
+		}
+		if airLockButton != nil {
+			airLockButton = peripheral.AnyPressed(airLockButton, inputReplayer.Button("airlock"))
+		}
+		if abortSwitch != nil {
+			abortSwitch = peripheral.AnyPressed(abortSwitch, inputReplayer.Button("abort"))
+		}
+	}
+
+	// The airlock needs at least three live batteries to cycle its door
+	airLock := airlock.NewAirLock(airlock.DefaultConfig())
+	defer airLock.Stop()
+
 	// Create and configure the panel
 	panelConfig := panel.PanelConfig{
-		Batteries:          batteries,
-		LEDStrip:           ledStrip,
-		BatteryResetButton: batteryResetButton,
-		BatteryConnects:    batteryConnects,
-		UpdateRate:         50 * time.Millisecond,
+		Batteries:           batteries,
+		Bank:                batteryBank,
+		LEDStrip:            drawStrip,
+		AirLockButton:       airLockButton,
+		AbortSwitch:         abortSwitch,
+		AirLock:             airLock,
+		AirLockLEDs:         hw.AirLockLEDs,
+		BatteryResetButton:  batteryResetButton,
+		BatteryResetButtons: batteryResetButtons,
+		BatteryConnects:     batteryConnects,
+		BatteryChargers:     batteryChargers,
+		UpdateRate:          50 * time.Millisecond,
+		StateFade:           300 * time.Millisecond,
+		Transitions:         panel.DefaultTransitions(),
+		RandomSeed:          randomSeed,
+		DifficultyKnob:      analogInputs.Reader(peripheral.AnalogDrainRate),
+		Audio:               audio.DefaultConfig(),
+		Power:               panel.DefaultPowerConfig(),
+		StatusIndicator:     panel.NewNeoPixelStatus(neoPixel),
+		Faults:              faults,
+		Logger:              log,
+		Context:             ctx,
+	}
+
+	// Buzzer alarms are optional - run silently if it isn't fitted or can't be configured
+	if hw.BuzzerPin != machine.NoPin {
+		buzzer := peripheral.NewBuzzer(hw.BuzzerPWM, hw.BuzzerPin)
+		if err := buzzer.Configure(); err == nil {
+			panelConfig.Buzzer = buzzer
+		} else {
+			log.Warn("buzzer configuration failed: %v", err)
+		}
+	}
+
+	// Reset the board if the panel loop stops completing updates, e.g. a hung SPI bus
+	if err := machine.Watchdog.Configure(machine.WatchdogConfig{TimeoutMillis: 4000}); err == nil {
+		if err := machine.Watchdog.Start(); err == nil {
+			panelConfig.Watchdog = machine.Watchdog
+		}
+	}
+
+	// Let the crew shout charge into the batteries if a microphone is fitted
+	if hw.MicrophonePin != machine.NoPin {
+		mic := peripheral.NewMicrophoneOnPin(hw.MicrophonePin, peripheral.DefaultMicrophoneConfig())
+		mic.Start(time.Millisecond)
+		defer mic.Stop()
+		panelConfig.ChargeBoost = panel.DefaultChargeBoostConfig(mic)
+	}
+
+	mainPanel, err := panel.NewPanel(panelConfig)
+	if err != nil {
+		log.Error("panel configuration failed: %v", err)
+		neoPixel.SetColorAndPause(Red, pauseMilliseconds)
+		return // Exit on configuration error
 	}
-	mainPanel := panel.NewPanel(panelConfig)
 
 	// Ensure panel cleanup on exit
 	defer mainPanel.Stop()
 
+	// Replayed knob moves go where the live knobs do; the panel polls the difficulty knob itself
+	inputReplayer.OnAnalog(func(name string, percentage int) {
+		role, ok := peripheral.ParseAnalogRole(name)
+		switch {
+		case !ok:
+		case role == peripheral.AnalogDrainRate:
+			mainPanel.SetDifficulty(percentage)
+		default:
+			applyAnalog(peripheral.AnalogChange{Role: role, Percentage: percentage})
+		}
+	})
+
+	// The panel's status indicator owns the NeoPixel from here on, errors show on it instead
+	log.SetPixel(nil)
+	recovery.SetHandler(func(err error) {
+		log.Error("%v", err)
+		mainPanel.ReportError()
+	})
+
+	// Catch wiring faults before the show starts
+	if result := mainPanel.SelfTest(); result.Passed {
+		log.Info("%v", result)
+	} else {
+		log.Error("%v", result)
+	}
+
+	// Hidden operator gestures, run through the console like any other command. Demos
+	// drive the mock buttons, so the gestures are only watched on real inputs.
+	if useRealPins {
+		combos := peripheral.NewComboDetector()
+		runCombo := func(command string) func(name string) {
+			return func(name string) {
+				log.Info("combo %s: %s", name, serialConsole.Execute(command))
+			}
+		}
+		if hw.Combos.SelfTestHold > 0 && len(comboConnects) > 0 {
+			combos.Add(&peripheral.Combo{
+				Name:     "self-test",
+				Kind:     peripheral.Simultaneous,
+				Buttons:  []peripheral.ButtonReader{comboReset, comboConnects[0]},
+				HoldTime: hw.Combos.SelfTestHold,
+				OnFire:   runCombo("selftest"),
+			})
+		}
+		if hw.Combos.EasterEggWindow > 0 && len(comboConnects) >= 3 {
+			combos.Add(&peripheral.Combo{
+				Name:    "easter egg",
+				Kind:    peripheral.Sequential,
+				Buttons: comboConnects[:3],
+				Window:  hw.Combos.EasterEggWindow,
+				OnFire:  runCombo("pattern rainbow"),
+			})
+		}
+		combos.Start(20 * time.Millisecond)
+		defer combos.Stop()
+	}
+
+	// Text status display for operators, if one is fitted
+	var display *peripheral.Display
+	var displayErr error
+	if hw.OLED.Bus != nil {
+		display, displayErr = peripheral.NewOLEDDisplay(hw.OLED)
+	} else if hw.TFT.Bus != nil {
+		display, displayErr = peripheral.NewTFTDisplay(hw.TFT)
+	}
+	if displayErr != nil {
+		log.Warn("status display configuration failed: %v", displayErr)
+	} else if display != nil {
+		statusDisplay := panel.NewStatusDisplay(mainPanel, display)
+		statusDisplay.Start(500 * time.Millisecond)
+		defer statusDisplay.Stop()
+	}
+
+	// Seven-segment battery percentage readout, selected from the console or its knob
+	if hw.SevenSegment.Bus != nil {
+		if sevenSegment, err := peripheral.NewSevenSegmentDisplay(hw.SevenSegment); err != nil {
+			log.Warn("seven-segment display configuration failed: %v", err)
+		} else {
+			var readoutKnob peripheral.RotaryReader
+			if hw.ReadoutKnob.PinA != machine.NoPin {
+				knob := peripheral.NewRotaryEncoder(hw.ReadoutKnob)
+				if err := knob.Configure(); err != nil {
+					log.Warn("readout knob configuration failed: %v", err)
+				} else {
+					readoutKnob = knob
+				}
+			}
+			readout := panel.NewBatteryReadout(mainPanel, sevenSegment, readoutKnob)
+			readout.Start(250 * time.Millisecond)
+			defer readout.Stop()
+			serialConsole.SetReadout(readout)
+		}
+	}
+
+	// Elevator button lights breathe while it's fitted; door changes are logged and
+	// available to missions
+	if elevatorDoor != nil {
+		if elevator, err := peripheral.NewElevatorFromConfig(hw.Elevator, elevatorDoor); err != nil {
+			log.Warn("elevator configuration failed: %v", err)
+		} else {
+			elevator.OnDoor(func(event peripheral.DoorEvent) {
+				log.Info("elevator door %s", event.State)
+			})
+			peripherals.Add("elevator", elevator)
+			if err := peripherals.Start(ctx); err != nil {
+				log.Warn("%v", err)
+			}
+			missionEngine.SetElevator(elevator)
+		}
+	}
+
+	// Mission clock, driven from missions and the console. Its changes are logged
+	// and running out of time plays the loss finale.
+	missionClock := timer.NewTimer(timer.DefaultConfig())
+	missionClock.OnEvent(func(event timer.Event) {
+		log.Info("timer %s, %s left", event.Kind, timer.FormatRemaining(event.Remaining))
+		if event.Kind == timer.ExpiredEvent {
+			mainPanel.PlayFinale(false)
+		}
+	})
+	missionEngine.SetTimer(missionClock)
+	serialConsole.SetTimer(missionClock)
+
+	// The payoff relay is energized by missions or by hand from the console,
+	// and released on the way out
+	if hw.Relay.Pin != machine.NoPin {
+		relay := peripheral.NewRelay(hw.Relay)
+		relay.OnChange(func(energized bool) {
+			log.Info("relay energized: %v", energized)
+		})
+		peripherals.Add("relay", relay)
+		if err := peripherals.Start(ctx); err != nil {
+			log.Warn("%v", err)
+		}
+		missionEngine.SetRelay(relay)
+		serialConsole.SetRelay(relay)
+	}
+
+	// The oxygen dial runs down with the mission clock, or follows one battery.
+	// Its needle must be parked at empty before power-up.
+	if hw.OxygenDial.Driver != peripheral.NoStepper {
+		dial := peripheral.NewStepperFromConfig(hw.OxygenDial)
+		peripherals.Add("oxygen dial", dial)
+		if err := peripherals.Start(ctx); err != nil {
+			log.Warn("%v", err)
+		}
+		reading := panel.TimeRemaining(missionClock)
+		if oxygenDialBattery >= 0 {
+			reading = panel.BatteryLevel(oxygenDialBattery)
+		}
+		mainPanel.AddGauge(panel.GaugeConfig{Needle: dial, Reading: reading})
+	}
+	if hw.TimerRingLEDs > 0 {
+		if ringStrip, err := peripheral.NewColorLedStripFromConfig(hw.TimerRingLEDs, hw.TimerRing); err != nil {
+			log.Warn("timer ring configuration failed: %v", err)
+		} else {
+			ring := timer.NewRing(missionClock, ringStrip)
+			ring.Start(100 * time.Millisecond)
+			defer ring.Stop()
+		}
+	}
+
+	// Stage fixtures mirror the battery sections if a DMX transceiver is fitted
+	if hw.DMX.UART != nil {
+		if port, err := dmx.NewUARTPort(hw.DMX); err != nil {
+			log.Warn("DMX configuration failed: %v", err)
+		} else {
+			dmxBridge := dmx.NewBridge(port, mainPanel, dmx.DefaultConfig(hw.DMXFixtures))
+			dmxBridge.Start()
+			defer dmxBridge.Stop()
+		}
+	}
+
+	// Cabinet lighting follows the show, dimming while paused or in standby
+	if hw.CabinetLight.Pin != machine.NoPin {
+		if cabinetLight, err := peripheral.NewPwmLight(hw.CabinetLight); err != nil {
+			log.Warn("cabinet light configuration failed: %v", err)
+		} else {
+			mainPanel.AddAuxLight(panel.AuxLightConfig{
+				Light:      cabinetLight,
+				Brightness: panel.CabinetLighting(255, 40),
+				Fade:       time.Second,
+			})
+		}
+	}
+
+	// A servo needle gauge sweeps to the bank's overall charge
+	if hw.Gauge.Pin != machine.NoPin {
+		if needle, err := peripheral.NewServo(hw.Gauge); err != nil {
+			log.Warn("charge gauge configuration failed: %v", err)
+		} else {
+			mainPanel.AddGauge(panel.GaugeConfig{Needle: needle})
+			defer needle.Release()
+		}
+	}
+
+	// Pattern commands pause the panel while a pattern owns the strip.
+	// Patterns are started by name from the pattern registry
+	patterns.SetSeed(randomSeed)
+	patternManager := patterns.NewPatternManager(drawStrip)
+	serialConsole.SetPanel(mainPanel)
+	serialConsole.SetPatterns(patternManager)
+	serialConsole.SetLogger(log)
+	serialConsole.SetStrips(ledStrip)
+	serialConsole.SetInputs(inputRecorder, inputReplayer)
+	go serialConsole.Run(ctx)
+
+	// Pattern knob: twist to choose a pattern, press to start it
+	if hw.PatternKnob.PinA != machine.NoPin {
+		knob := peripheral.NewRotaryEncoder(hw.PatternKnob)
+		if err := knob.Configure(); err != nil {
+			log.Warn("pattern knob configuration failed: %v", err)
+		} else {
+			var confirm peripheral.ButtonReader
+			if button := knob.Button(); button != nil {
+				debounced := peripheral.NewDebouncedButton(button, peripheral.DefaultDebounce)
+				debounced.Start(5 * time.Millisecond)
+				defer debounced.Stop()
+				confirm = debounced
+			}
+			selector := patterns.NewPatternSelector(patternManager, patterns.RegistryEntries(), knob, confirm)
+			selector.SetPanel(mainPanel)
+			selector.OnSelect = func(name string) {
+				log.Info("pattern knob: %s", name)
+			}
+			selector.Start(20 * time.Millisecond)
+			defer selector.Stop()
+		}
+	}
+
+	// Live show state for a laptop on the USB serial port
+	if streamTelemetry {
+		telemetryReporter := telemetry.NewReporter(machine.Serial, mainPanel)
+		telemetryReporter.Start(time.Second)
+		defer telemetryReporter.Stop()
+	}
+
+	// Room control alerts the game master if these stop arriving
+	var heartbeat *telemetry.Heartbeat
+	if sendHeartbeat {
+		heartbeat = telemetry.NewHeartbeat(mainPanel, telemetry.DefaultHeartbeatConfig(peripheral.ResetCause()))
+		heartbeat.OnBeat(telemetry.WriteHeartbeat(machine.Serial))
+		heartbeat.Start()
+		defer heartbeat.Stop()
+	}
+
+	// HTTP control API, MQTT bridge and pixel endpoint over WiFi, commands go through the serial console
+	startNetwork(ctx, network{
+		Control:   serveNetControl,
+		MQTT:      bridgeMQTT,
+		Pixels:    servePixels,
+		Log:       log,
+		Console:   serialConsole,
+		Panel:     mainPanel,
+		Patterns:  patternManager,
+		Heartbeat: heartbeat,
+		NumLEDs:   drawStrip.NumLEDs(),
+	})
+
+	missionEngine.Attach(mainPanel, batteryBank, neoPixel)
+	missionEngine.SetPatterns(patternManager)
+	defer missionEngine.Stop()
+	if useRealPins && runMission {
+		m := mission.SpacewalkMission()
+		log.Info("starting mission %s", m.Name)
+		if err := missionEngine.Start(m); err != nil {
+			log.Error("mission: %v", err)
+		}
+	}
+
 	// Only run demo sequences when using mock buttons
 	if !useRealPins {
-		if runDemoAllBatteries {
-			mainPanel.StartDemoAllBatteries(neoPixel)
-		} else if runDemoRandomBatteries {
-			mainPanel.StartDemoRandomBatteries(neoPixel)
+		if scenario, err := panel.BuiltinDemo(demoScenario); err != nil {
+			log.Warn("demo: %v", err)
+		} else {
+			mainPanel.StartDemo(scenario)
 		}
 	}
 
@@ -131,10 +683,29 @@ func main() {
 	select {
 	case <-ctx.Done():
 		// Context was cancelled
-	case <-mainPanel.GetContext().Done():
+	case <-mainPanel.Done():
 		// Panel stopped itself
 		cancel()
 	}
 
 	// Cleanup already handled by defer statements
 }
+
+// network is what startNetwork serves and the parts of the prop it serves them from
+type network struct {
+	Control bool // HTTP control API
+	MQTT    bool // Escape-room MQTT bridge
+	Pixels  bool // Art-Net pixel endpoint
+
+	Log       *logger.Logger
+	Console   *console.Console
+	Panel     *panel.Panel
+	Patterns  *patterns.PatternManager
+	Heartbeat *telemetry.Heartbeat // Also published over MQTT when bridged, nil if not sent
+	NumLEDs   int                  // Pixels the endpoint can drive
+}
+
+// enabled returns whether any network service is switched on
+func (n network) enabled() bool {
+	return n.Control || n.MQTT || n.Pixels
+}
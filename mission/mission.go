@@ -0,0 +1,547 @@
+// Package mission runs scripted show timelines against the panel, so operators
+// can run repeatable scenarios instead of relying on random demos
+package mission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/patterns"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/timer"
+)
+
+// Controls is everything a mission step can act on
+type Controls struct {
+	Panel     *panel.Panel
+	Batteries []*battery.Battery
+	Bank      *battery.Bank // Aggregate battery state for gating steps
+	NeoPixel  *peripheral.NeoPixel
+	Connects  []*peripheral.MockButton // Mission-driven battery connect inputs
+	Reset     *peripheral.MockButton   // Mission-driven battery reset input
+	Patterns  *patterns.PatternManager // Starts registered patterns, nil until attached
+	Elevator  *peripheral.Elevator     // Elevator door and lights, nil if not fitted
+	Timer     *timer.Timer             // Mission clock, nil until attached
+	Relay     *peripheral.Relay        // Door release or maglock payoff, nil if not fitted
+}
+
+// Action is a single thing a step does
+type Action func(c *Controls)
+
+// Step runs an action at an offset from the start of the mission
+type Step struct {
+	At     time.Duration
+	Name   string
+	Action Action
+}
+
+// Condition is checked against the controls, e.g. to gate a trigger
+type Condition func(c *Controls) bool
+
+// Trigger runs an action once its condition has held for a while, e.g. releasing
+// the door once every battery has stayed charged for 10 seconds. Each trigger
+// fires at most once per run of the mission.
+type Trigger struct {
+	Name   string
+	After  time.Duration // How far into the mission When starts being checked, e.g. after the batteries fail
+	When   Condition
+	For    time.Duration // How long When must hold continuously, 0 fires as soon as it does
+	Action Action
+}
+
+// triggerPollRate is how often trigger conditions are checked
+const triggerPollRate = 100 * time.Millisecond
+
+// Mission is a named timeline of steps, plus triggers watched while it runs.
+// A mission with triggers keeps running after its last step until stopped.
+type Mission struct {
+	Name     string
+	Steps    []Step
+	Triggers []Trigger
+	Loop     bool // Restart from the beginning after the last step
+}
+
+// ErrLoopNeedsWait is returned for a looping mission whose steps all run at the
+// start, which would rerun them flat out
+var ErrLoopNeedsWait = errors.New("mission: loop needs a step after the start")
+
+// Validate checks that the mission can run, e.g. before it is started
+func (m Mission) Validate() error {
+	if !m.Loop {
+		return nil
+	}
+	for _, step := range m.Steps {
+		if step.At > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrLoopNeedsWait, m.Name)
+}
+
+// Engine runs missions against the panel
+type Engine struct {
+	mu       sync.Mutex
+	controls Controls
+	cancel   context.CancelFunc
+	done     chan struct{} // Closed once the current run has released its inputs
+	running  bool
+	current  string // Name of the step most recently run
+}
+
+// NewEngine creates an engine for a panel with numBatteries batteries.
+// Combine BatteryInput and ResetInput with the physical inputs using
+// peripheral.AnyPressed, then Attach the panel once it is created.
+func NewEngine(numBatteries int) *Engine {
+	e := &Engine{
+		controls: Controls{
+			Connects: make([]*peripheral.MockButton, numBatteries),
+			Reset:    peripheral.NewMockButton(),
+		},
+	}
+	for i := range e.controls.Connects {
+		e.controls.Connects[i] = peripheral.NewMockButton()
+	}
+	return e
+}
+
+// BatteryInput returns the mission-driven connect input for a battery (0-based)
+func (e *Engine) BatteryInput(batteryIndex int) peripheral.ButtonReader {
+	return e.controls.Connects[batteryIndex]
+}
+
+// ResetInput returns the mission-driven battery reset input
+func (e *Engine) ResetInput() peripheral.ButtonReader {
+	return e.controls.Reset
+}
+
+// Attach sets the panel, battery bank, and NeoPixel that steps act on
+func (e *Engine) Attach(p *panel.Panel, bank *battery.Bank, neoPixel *peripheral.NeoPixel) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controls.Panel = p
+	e.controls.Bank = bank
+	e.controls.Batteries = bank.Batteries()
+	e.controls.NeoPixel = neoPixel
+}
+
+// SetPatterns attaches the pattern manager that pattern steps start patterns on
+func (e *Engine) SetPatterns(pm *patterns.PatternManager) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controls.Patterns = pm
+}
+
+// SetElevator attaches the elevator whose door state steps can check
+func (e *Engine) SetElevator(elevator *peripheral.Elevator) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controls.Elevator = elevator
+}
+
+// SetRelay attaches the relay that relay steps energize
+func (e *Engine) SetRelay(relay *peripheral.Relay) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controls.Relay = relay
+}
+
+// SetTimer attaches the mission clock that timer steps act on
+func (e *Engine) SetTimer(t *timer.Timer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.controls.Timer = t
+}
+
+// Start runs a mission in the background, stopping any mission already running
+// and waiting for it to release its inputs first. A mission that fails Validate
+// is not started.
+func (e *Engine) Start(m Mission) error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	e.Stop()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	e.mu.Lock()
+	e.cancel = cancel
+	e.done = done
+	e.running = true
+	e.mu.Unlock()
+
+	go func() {
+		defer close(done)
+		e.Run(ctx, m)
+
+		e.mu.Lock()
+		if e.done == done {
+			e.running = false
+		}
+		e.mu.Unlock()
+	}()
+	return nil
+}
+
+// Stop stops the running mission, returning once it has released its inputs
+func (e *Engine) Stop() {
+	e.mu.Lock()
+	cancel, done := e.cancel, e.done
+	e.cancel, e.done = nil, nil
+	e.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	if done != nil {
+		<-done
+	}
+
+	e.mu.Lock()
+	if e.done == nil {
+		e.running = false
+	}
+	e.mu.Unlock()
+}
+
+// IsRunning returns whether a mission is in progress
+func (e *Engine) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running
+}
+
+// CurrentStep returns the name of the step most recently run
+func (e *Engine) CurrentStep() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.current
+}
+
+// Run plays a mission until it ends or the context is cancelled. A mission that
+// fails Validate returns at once.
+func (e *Engine) Run(ctx context.Context, m Mission) {
+	defer e.releaseInputs()
+	if m.Validate() != nil {
+		return
+	}
+
+	steps := make([]Step, len(m.Steps))
+	copy(steps, m.Steps)
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].At < steps[j].At
+	})
+
+	if len(m.Triggers) > 0 {
+		triggersDone := make(chan struct{})
+		defer func() { <-triggersDone }()
+		go func() {
+			defer close(triggersDone)
+			e.watchTriggers(ctx, m.Triggers)
+		}()
+	}
+
+	for {
+		startedAt := time.Now()
+		for _, step := range steps {
+			if !sleepUntil(ctx, startedAt.Add(step.At)) {
+				return
+			}
+
+			e.mu.Lock()
+			e.current = step.Name
+			controls := e.controls
+			e.mu.Unlock()
+
+			if step.Action != nil {
+				step.Action(&controls)
+			}
+		}
+
+		if !m.Loop || len(steps) == 0 {
+			break
+		}
+	}
+
+	// Keep watching the triggers until the mission is stopped
+	if len(m.Triggers) > 0 {
+		<-ctx.Done()
+	}
+}
+
+// watchTriggers runs each trigger's action once its condition has held for long
+// enough, until the context is cancelled or every trigger has fired
+func (e *Engine) watchTriggers(ctx context.Context, triggers []Trigger) {
+	ticker := time.NewTicker(triggerPollRate)
+	defer ticker.Stop()
+
+	startedAt := time.Now()
+	heldSince := make([]time.Time, len(triggers)) // Zero while the condition doesn't hold
+	fired := make([]bool, len(triggers))
+	remaining := len(triggers)
+	for remaining > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.mu.Lock()
+			controls := e.controls
+			e.mu.Unlock()
+
+			for i, trigger := range triggers {
+				if fired[i] || now.Sub(startedAt) < trigger.After {
+					continue
+				}
+				if trigger.When == nil || !trigger.When(&controls) {
+					heldSince[i] = time.Time{}
+					continue
+				}
+				if heldSince[i].IsZero() {
+					heldSince[i] = now
+				}
+				if now.Sub(heldSince[i]) < trigger.For {
+					continue
+				}
+
+				fired[i] = true
+				remaining--
+				e.mu.Lock()
+				e.current = trigger.Name
+				e.mu.Unlock()
+				if trigger.Action != nil {
+					trigger.Action(&controls)
+				}
+			}
+		}
+	}
+}
+
+// releaseInputs lets go of every mission-driven input
+func (e *Engine) releaseInputs() {
+	for _, button := range e.controls.Connects {
+		button.SetPressed(false)
+	}
+	e.controls.Reset.SetPressed(false)
+}
+
+// sleepUntil waits until deadline, returning false if the context was cancelled first
+func sleepUntil(ctx context.Context, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// SetDraining connects (true) or disconnects (false) a battery (0-based)
+func SetDraining(batteryIndex int, draining bool) Action {
+	return func(c *Controls) {
+		if batteryIndex >= 0 && batteryIndex < len(c.Connects) {
+			c.Connects[batteryIndex].SetPressed(draining)
+		}
+	}
+}
+
+// ResetAll holds the battery reset input for duration, recharging every battery
+func ResetAll(duration time.Duration) Action {
+	return func(c *Controls) {
+		c.Reset.SetPressed(true)
+		time.AfterFunc(duration, func() {
+			c.Reset.SetPressed(false)
+		})
+	}
+}
+
+// SetLevel sets a battery's level (0-based index)
+func SetLevel(batteryIndex int, pct float32) Action {
+	return func(c *Controls) {
+		if batteryIndex >= 0 && batteryIndex < len(c.Batteries) {
+			c.Batteries[batteryIndex].SetLevel(pct)
+		}
+	}
+}
+
+// IfAlive runs action only while at least n batteries aren't dead
+func IfAlive(n int, action Action) Action {
+	return func(c *Controls) {
+		if c.Bank != nil && c.Bank.AtLeastAlive(n) {
+			action(c)
+		}
+	}
+}
+
+// AllCharged holds while every battery in the bank is charged
+func AllCharged() Condition {
+	return func(c *Controls) bool {
+		return c.Bank != nil && c.Bank.AllCharged()
+	}
+}
+
+// IfDoor runs action only while the elevator door is in state
+func IfDoor(state peripheral.DoorState, action Action) Action {
+	return func(c *Controls) {
+		if c.Elevator != nil && c.Elevator.State() == state {
+			action(c)
+		}
+	}
+}
+
+// PlayOverlay plays a one-shot effect over the panel
+func PlayOverlay(effect panel.Overlay, duration time.Duration) Action {
+	return func(c *Controls) {
+		if c.Panel != nil {
+			c.Panel.PlayOverlay(effect, duration)
+		}
+	}
+}
+
+// neoPixelHold is how long a SetNeoPixel color shows over the panel's status indicator
+const neoPixelHold = 10 * time.Second
+
+// SetNeoPixel sets the onboard NeoPixel color. When the panel's status indicator
+// is on the NeoPixel the color overrides it for neoPixelHold, then the status
+// shows again, so the indicator doesn't paint over it on the next tick.
+func SetNeoPixel(col color.RGBA) Action {
+	return func(c *Controls) {
+		if c.Panel != nil && c.Panel.OverrideStatus(col, neoPixelHold) {
+			return
+		}
+		if c.NeoPixel != nil {
+			c.NeoPixel.SetColorAndPause(col, 0)
+		}
+	}
+}
+
+// StartPattern pauses the panel and starts a registered pattern by name
+func StartPattern(name string, params patterns.Params) Action {
+	return func(c *Controls) {
+		if c.Patterns == nil {
+			return
+		}
+		pattern, err := patterns.Create(name, params)
+		if err != nil {
+			return
+		}
+		if c.Panel != nil {
+			c.Panel.Pause()
+		}
+		if err := c.Patterns.StartPattern(pattern); err != nil && c.Panel != nil {
+			c.Panel.Resume()
+		}
+	}
+}
+
+// StopPattern stops the running pattern and hands the strip back to the panel
+func StopPattern() Action {
+	return func(c *Controls) {
+		if c.Patterns != nil {
+			c.Patterns.StopPattern()
+		}
+		if c.Panel != nil {
+			c.Panel.Resume()
+		}
+	}
+}
+
+// Abort forces every battery Dead with a red alarm until the batteries are reset
+func Abort() Action {
+	return func(c *Controls) {
+		if c.Panel != nil {
+			c.Panel.Abort()
+		}
+	}
+}
+
+// StartTimer resets the mission clock to duration and starts it; 0 keeps the configured duration
+func StartTimer(duration time.Duration) Action {
+	return func(c *Controls) {
+		if c.Timer != nil {
+			c.Timer.Reset(duration)
+			c.Timer.Start()
+		}
+	}
+}
+
+// PauseTimer freezes the mission clock
+func PauseTimer() Action {
+	return func(c *Controls) {
+		if c.Timer != nil {
+			c.Timer.Pause()
+		}
+	}
+}
+
+// ResumeTimer continues the paused mission clock
+func ResumeTimer() Action {
+	return func(c *Controls) {
+		if c.Timer != nil {
+			c.Timer.Resume()
+		}
+	}
+}
+
+// AddTime puts d more on the mission clock, or takes it away when negative
+func AddTime(d time.Duration) Action {
+	return func(c *Controls) {
+		if c.Timer != nil {
+			c.Timer.AddTime(d)
+		}
+	}
+}
+
+// IfTimeLeft runs action only while the mission clock has at least d left
+func IfTimeLeft(d time.Duration, action Action) Action {
+	return func(c *Controls) {
+		if c.Timer != nil && c.Timer.Remaining() >= d {
+			action(c)
+		}
+	}
+}
+
+// Finale stops any pattern and plays the win or loss finale on the panel
+func Finale(win bool) Action {
+	return func(c *Controls) {
+		if c.Patterns != nil {
+			c.Patterns.StopPattern()
+		}
+		if c.Panel != nil {
+			c.Panel.PlayFinale(win)
+		}
+	}
+}
+
+// EnergizeRelay energizes the payoff relay for d, capped at its max on time (0 for the max)
+func EnergizeRelay(d time.Duration) Action {
+	return func(c *Controls) {
+		if c.Relay != nil {
+			c.Relay.EnergizeFor(d)
+		}
+	}
+}
+
+// ReleaseRelay de-energizes the payoff relay
+func ReleaseRelay() Action {
+	return func(c *Controls) {
+		if c.Relay != nil {
+			c.Relay.Release()
+		}
+	}
+}
+
+// Do runs an arbitrary function, e.g. to drive subsystems without a built-in action
+func Do(fn func()) Action {
+	return func(c *Controls) {
+		fn()
+	}
+}
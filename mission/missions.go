@@ -0,0 +1,29 @@
+package mission
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/panel"
+)
+
+// SpacewalkMission returns the standard show timeline: all batteries start
+// charged, battery 3 is forced draining, and an alarm plays before the finale.
+// Once the players have every battery charged for 10 seconds the door releases.
+func SpacewalkMission() Mission {
+	return Mission{
+		Name: "spacewalk",
+		Steps: []Step{
+			{At: 0, Name: "all charged", Action: ResetAll(time.Second)},
+			{At: 0, Name: "status ready", Action: SetNeoPixel(color.RGBA{G: 25, A: 255})},
+			{At: 30 * time.Second, Name: "battery 3 failing", Action: SetDraining(2, true)},
+			{At: 30 * time.Second, Name: "warning", Action: PlayOverlay(panel.FlashOverlay(panel.Red, 3), 2*time.Second)},
+			{At: 90 * time.Second, Name: "battery 5 failing", Action: SetDraining(4, true)},
+			{At: 2 * time.Minute, Name: "airlock alarm", Action: PlayOverlay(panel.FlashOverlay(panel.Yellow, 6), 3*time.Second)},
+			{At: 2 * time.Minute, Name: "status alarm", Action: SetNeoPixel(color.RGBA{R: 25, A: 255})},
+		},
+		Triggers: []Trigger{
+			{Name: "door release", After: 90 * time.Second, When: AllCharged(), For: 10 * time.Second, Action: EnergizeRelay(0)},
+		},
+	}
+}
@@ -0,0 +1,213 @@
+package mqtt
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/console"
+	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/telemetry"
+)
+
+// BridgeConfig holds the broker and topic settings for a Bridge.
+//
+// Published (retained) on every battery state change:
+//
+//	<prefix>/battery/<n>/state    e.g. "Draining"
+//	<prefix>/battery/<n>/level    e.g. "42.5"
+//
+// Published with every heartbeat, if the bridge is given them:
+//
+//	<prefix>/heartbeat            telemetry.Health as JSON
+//
+// Commands, answered on <prefix>/response:
+//
+//	<prefix>/cmd/reset                    any payload resets all batteries
+//	<prefix>/cmd/battery/<n>/drain        "on" forces draining, "off" releases it
+//	<prefix>/cmd/pattern                  pattern name and key=value parameters, or "off"
+type BridgeConfig struct {
+	Broker         string // host:port of the broker
+	Prefix         string // Topic prefix
+	Client         ClientConfig
+	ReconnectDelay time.Duration
+}
+
+// DefaultBridgeConfig returns a config for a broker on the standard port
+func DefaultBridgeConfig(broker string) BridgeConfig {
+	return BridgeConfig{
+		Broker:         broker,
+		Prefix:         "tinyspacewalk",
+		Client:         DefaultClientConfig("tinyspacewalk"),
+		ReconnectDelay: 5 * time.Second,
+	}
+}
+
+// Bridge publishes battery state changes from the battery event bus and runs
+// command topics through the serial console, so they behave like typed commands
+type Bridge struct {
+	config  BridgeConfig
+	console *console.Console
+	panel   *panel.Panel
+
+	mu     sync.Mutex
+	client *Client // nil while disconnected
+}
+
+// NewBridge creates a bridge for a panel and the console that runs its commands
+func NewBridge(c *console.Console, p *panel.Panel, config BridgeConfig) *Bridge {
+	return &Bridge{config: config, console: c, panel: p}
+}
+
+// batteryEvent is a state transition tagged with the battery it came from
+type batteryEvent struct {
+	index      int
+	transition battery.StateTransition
+}
+
+// Run keeps a session with the broker open until the context is cancelled,
+// reconnecting after failures
+func (b *Bridge) Run(ctx context.Context) {
+	// Forward transitions from every battery to a single publisher
+	events := make(chan batteryEvent, 16)
+	batteries := b.panel.Batteries()
+	subscriptions := make([]chan battery.StateTransition, len(batteries))
+	for i, bat := range batteries {
+		subscriptions[i] = make(chan battery.StateTransition, 4)
+		bat.Subscribe(subscriptions[i])
+		go func(index int, transitions <-chan battery.StateTransition) {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case t := <-transitions:
+					select {
+					case events <- batteryEvent{index: index, transition: t}:
+					default:
+						// Publisher isn't keeping up; the next change republishes the state
+					}
+				}
+			}
+		}(i, subscriptions[i])
+	}
+	defer func() {
+		for i, bat := range batteries {
+			bat.Unsubscribe(subscriptions[i])
+		}
+	}()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-events:
+				b.publishBattery(event.index, event.transition.To, event.transition.Level)
+			}
+		}
+	}()
+
+	for {
+		b.session(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(b.config.ReconnectDelay):
+		}
+	}
+}
+
+// session runs one connection to the broker until it fails or the context is cancelled
+func (b *Bridge) session(ctx context.Context) error {
+	conn, err := net.Dial("tcp", b.config.Broker)
+	if err != nil {
+		return err
+	}
+
+	client := NewClient(conn, b.config.Client)
+	if err := client.Connect(ctx); err != nil {
+		conn.Close()
+		return err
+	}
+	client.OnMessage(b.handleMessage)
+	if err := client.Subscribe(b.config.Prefix + "/cmd/#"); err != nil {
+		conn.Close()
+		return err
+	}
+
+	b.mu.Lock()
+	b.client = client
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		b.client = nil
+		b.mu.Unlock()
+		client.Close()
+	}()
+
+	// Bring retained state up to date for anything that changed while disconnected
+	for i, info := range b.panel.GetAllBatteryInfo() {
+		b.publishBattery(i, info.State, info.BatteryLevel)
+	}
+
+	return client.Run(ctx)
+}
+
+// publishBattery publishes a battery's retained state and level topics
+func (b *Bridge) publishBattery(index int, state battery.SystemState, level float32) {
+	topic := b.config.Prefix + "/battery/" + strconv.Itoa(index+1)
+	b.publish(topic+"/state", state.String(), true)
+	b.publish(topic+"/level", strconv.FormatFloat(float64(level), 'f', 1, 32), true)
+}
+
+// PublishHeartbeat publishes a heartbeat, e.g. registered with telemetry.Heartbeat.OnBeat
+func (b *Bridge) PublishHeartbeat(health telemetry.Health) {
+	b.publish(b.config.Prefix+"/heartbeat", string(health.AppendJSON(nil)), false)
+}
+
+// publish sends a message if connected, dropping it otherwise. A failed write
+// may have left half a packet on the wire, so it ends the session to reconnect.
+func (b *Bridge) publish(topic, payload string, retain bool) {
+	b.mu.Lock()
+	client := b.client
+	b.mu.Unlock()
+
+	if client != nil {
+		if err := client.Publish(topic, []byte(payload), retain); err != nil {
+			client.conn.Close()
+		}
+	}
+}
+
+// handleMessage maps a command topic to a console command and publishes its output
+func (b *Bridge) handleMessage(m Message) {
+	command, ok := strings.CutPrefix(m.Topic, b.config.Prefix+"/cmd/")
+	if !ok {
+		return
+	}
+	payload := strings.TrimSpace(string(m.Payload))
+	parts := strings.Split(command, "/")
+
+	var line string
+	switch {
+	case command == "reset":
+		line = "battery reset"
+	case command == "pattern":
+		line = "pattern " + payload
+	case len(parts) == 3 && parts[0] == "battery" && parts[2] == "drain":
+		action := "stop"
+		if payload == "on" || payload == "1" || payload == "true" {
+			action = "drain"
+		}
+		line = "battery " + parts[1] + " " + action
+	default:
+		b.publish(b.config.Prefix+"/response", "unknown command topic: "+command, false)
+		return
+	}
+	b.publish(b.config.Prefix+"/response", b.console.Execute(line), false)
+}
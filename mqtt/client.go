@@ -0,0 +1,323 @@
+// Package mqtt is a minimal MQTT 3.1.1 client (QoS 0 only) and a bridge that
+// connects the show to escape-room control software such as Node-RED or ERM.
+package mqtt
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Packet types
+const (
+	packetConnect    = 1
+	packetConnAck    = 2
+	packetPublish    = 3
+	packetSubscribe  = 8
+	packetSubAck     = 9
+	packetPingReq    = 12
+	packetPingResp   = 13
+	packetDisconnect = 14
+)
+
+// maxPacketLength caps the remaining length of a received packet, the show's
+// commands are a few bytes so anything larger is rejected rather than allocated
+const maxPacketLength = 4096
+
+// Errors returned by the client
+var (
+	ErrConnectionRefused = errors.New("mqtt: connection refused by broker")
+	ErrMalformedPacket   = errors.New("mqtt: malformed packet")
+	ErrPacketTooLarge    = errors.New("mqtt: packet too large")
+	ErrKeepAliveTimeout  = errors.New("mqtt: broker stopped responding")
+	ErrConnectTimeout    = errors.New("mqtt: no reply to connect")
+)
+
+// Message is a message received on a subscribed topic
+type Message struct {
+	Topic   string
+	Payload []byte
+}
+
+// ClientConfig holds the connection settings for a client
+type ClientConfig struct {
+	ClientID  string
+	Username  string // Optional
+	Password  string // Optional
+	KeepAlive time.Duration
+
+	// ConnectTimeout is how long Connect waits for the broker to accept the session
+	ConnectTimeout time.Duration
+}
+
+// DefaultClientConfig returns a config with a 30 second keep-alive that waits
+// 10 seconds for the broker to accept a connection
+func DefaultClientConfig(clientID string) ClientConfig {
+	return ClientConfig{
+		ClientID:       clientID,
+		KeepAlive:      30 * time.Second,
+		ConnectTimeout: 10 * time.Second,
+	}
+}
+
+// Client is an MQTT session over a single connection
+type Client struct {
+	writeMu   sync.Mutex // Serialises packets written to conn
+	conn      io.ReadWriteCloser
+	config    ClientConfig
+	packetID  uint16
+	onMessage func(Message)
+	received  atomic.Int64 // Unix nanoseconds of the last packet read, to spot a dead broker
+}
+
+// NewClient creates a client on an open connection to a broker
+func NewClient(conn io.ReadWriteCloser, config ClientConfig) *Client {
+	return &Client{conn: conn, config: config}
+}
+
+// OnMessage sets the handler for messages on subscribed topics.
+// It runs on the Run goroutine; set it before calling Run.
+func (c *Client) OnMessage(handler func(Message)) {
+	c.onMessage = handler
+}
+
+// Connect starts the session and waits for the broker to accept it, for at most
+// ConnectTimeout or until ctx is cancelled. Giving up closes the connection, as
+// that is the only way to interrupt the read.
+func (c *Client) Connect(ctx context.Context) error {
+	flags := byte(0x02) // Clean session
+	var payload []byte
+	payload = appendString(payload, c.config.ClientID)
+	if c.config.Username != "" {
+		flags |= 0x80
+		payload = appendString(payload, c.config.Username)
+		if c.config.Password != "" {
+			flags |= 0x40
+			payload = appendString(payload, c.config.Password)
+		}
+	}
+
+	var body []byte
+	body = appendString(body, "MQTT")
+	body = append(body, 4, flags) // Protocol level 3.1.1
+	body = binary.BigEndian.AppendUint16(body, uint16(c.config.KeepAlive/time.Second))
+	body = append(body, payload...)
+	if err := c.writePacket(packetConnect<<4, body); err != nil {
+		return err
+	}
+
+	timeout := c.config.ConnectTimeout
+	if timeout <= 0 {
+		timeout = DefaultClientConfig("").ConnectTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	stopClosing := context.AfterFunc(ctx, func() { c.conn.Close() })
+
+	packetType, body, err := c.readPacket()
+	if !stopClosing() {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return ErrConnectTimeout
+		}
+		return ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	if packetType != packetConnAck || len(body) != 2 {
+		return ErrMalformedPacket
+	}
+	if body[1] != 0 {
+		return ErrConnectionRefused
+	}
+	return nil
+}
+
+// Publish sends a message at QoS 0; retained messages are kept by the broker
+// for clients that subscribe later
+func (c *Client) Publish(topic string, payload []byte, retain bool) error {
+	header := byte(packetPublish << 4)
+	if retain {
+		header |= 0x01
+	}
+	body := appendString(nil, topic)
+	body = append(body, payload...)
+	return c.writePacket(header, body)
+}
+
+// Subscribe requests messages on topic filters, which may use + and # wildcards
+func (c *Client) Subscribe(filters ...string) error {
+	c.writeMu.Lock()
+	c.packetID++
+	if c.packetID == 0 {
+		c.packetID = 1
+	}
+	id := c.packetID
+	c.writeMu.Unlock()
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	for _, filter := range filters {
+		body = appendString(body, filter)
+		body = append(body, 0) // QoS 0
+	}
+	return c.writePacket(packetSubscribe<<4|0x02, body)
+}
+
+// Run reads packets and delivers messages until the context is cancelled or
+// the connection fails, sending keep-alive pings in the background. A broker
+// silent for 1.5 keep-alives, or a ping that can't be sent, ends the session.
+func (c *Client) Run(ctx context.Context) error {
+	done := make(chan struct{})
+	defer close(done)
+	failed := make(chan error, 1)
+
+	keepAlive := c.config.KeepAlive
+	if keepAlive <= 0 {
+		keepAlive = DefaultClientConfig("").KeepAlive
+	}
+	c.received.Store(time.Now().UnixNano())
+
+	go func() {
+		ticker := time.NewTicker(keepAlive / 2)
+		defer ticker.Stop()
+
+		// fail records why the session ended and unblocks the read below
+		fail := func(err error) {
+			failed <- err
+			c.conn.Close()
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				// Unblock the read below
+				c.conn.Close()
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+				// Every ping is answered, so silence means the broker or link is gone
+				if time.Since(time.Unix(0, c.received.Load())) > keepAlive*3/2 {
+					fail(ErrKeepAliveTimeout)
+					return
+				}
+				if err := c.writePacket(packetPingReq<<4, nil); err != nil {
+					fail(err)
+					return
+				}
+			}
+		}
+	}()
+
+	for {
+		packetType, body, err := c.readPacket()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			select {
+			case err = <-failed:
+			default:
+			}
+			return err
+		}
+		c.received.Store(time.Now().UnixNano())
+		if packetType == packetPublish {
+			c.deliver(body)
+		}
+		// SUBACK and PINGRESP need no handling at QoS 0
+	}
+}
+
+// Close ends the session and closes the connection
+func (c *Client) Close() error {
+	c.writePacket(packetDisconnect<<4, nil)
+	return c.conn.Close()
+}
+
+// deliver decodes a QoS 0 PUBLISH body and passes it to the message handler
+func (c *Client) deliver(body []byte) {
+	if len(body) < 2 {
+		return
+	}
+	topicLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+topicLen {
+		return
+	}
+	if c.onMessage != nil {
+		c.onMessage(Message{
+			Topic:   string(body[2 : 2+topicLen]),
+			Payload: body[2+topicLen:],
+		})
+	}
+}
+
+// writePacket writes a fixed header, the remaining length and the body
+func (c *Client) writePacket(header byte, body []byte) error {
+	packet := append([]byte{header}, encodeLength(len(body))...)
+	packet = append(packet, body...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+// readPacket reads one packet, returning its type and body
+func (c *Client) readPacket() (byte, []byte, error) {
+	var b [1]byte
+	if _, err := io.ReadFull(c.conn, b[:]); err != nil {
+		return 0, nil, err
+	}
+	packetType := b[0] >> 4
+
+	// Remaining length is a base-128 varint of at most four bytes
+	length := 0
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, ErrMalformedPacket
+		}
+		if _, err := io.ReadFull(c.conn, b[:]); err != nil {
+			return 0, nil, err
+		}
+		length |= int(b[0]&0x7f) << (7 * i)
+		if b[0]&0x80 == 0 {
+			break
+		}
+	}
+	if length > maxPacketLength {
+		return 0, nil, ErrPacketTooLarge
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.conn, body); err != nil {
+		return 0, nil, err
+	}
+	return packetType, body, nil
+}
+
+// encodeLength encodes a remaining length as a base-128 varint
+func encodeLength(n int) []byte {
+	var out []byte
+	for {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		out = append(out, b)
+		if n == 0 {
+			return out
+		}
+	}
+}
+
+// appendString appends a length-prefixed UTF-8 string
+func appendString(buf []byte, s string) []byte {
+	buf = binary.BigEndian.AppendUint16(buf, uint16(len(s)))
+	return append(buf, s...)
+}
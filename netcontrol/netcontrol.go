@@ -0,0 +1,135 @@
+// Package netcontrol exposes a small HTTP API for controlling the prop over WiFi:
+//
+//	GET  /status               battery and airlock state as JSON
+//	POST /battery/{n}/drain    toggle draining of battery n (1-based)
+//	POST /pattern/{name}       start a pattern, "off" returns to the panel;
+//	                           query parameters tune it, e.g. ?cooling=70
+//
+// Commands go through the serial console, so the API behaves exactly like
+// typing them at the console. Bringing up WiFi needs a board with a supported
+// network coprocessor and the netcontrol build tag; see Connect.
+package netcontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/christophergm/tinyspacewalk/console"
+	"github.com/christophergm/tinyspacewalk/panel"
+)
+
+// ErrNoWiFi is returned by Connect on builds without WiFi support
+var ErrNoWiFi = errors.New("netcontrol: built without WiFi support")
+
+// Handler serves the control API
+type Handler struct {
+	console *console.Console
+	panel   *panel.Panel
+}
+
+// NewHandler creates an API handler running commands on c and reading state from p
+func NewHandler(c *console.Console, p *panel.Panel) *Handler {
+	return &Handler{console: c, panel: p}
+}
+
+// batteryStatus is a battery in the /status response
+type batteryStatus struct {
+	Battery  int     `json:"battery"`
+	State    string  `json:"state"`
+	Level    float32 `json:"level"`
+	Draining bool    `json:"draining"`
+}
+
+// status is the /status response
+type status struct {
+	Batteries []batteryStatus `json:"batteries"`
+	AirLock   string          `json:"airlock,omitempty"`
+	Paused    bool            `json:"paused"`
+}
+
+// ListenAndServe serves the API on addr until the server fails
+func (h *Handler) ListenAndServe(addr string) error {
+	return http.ListenAndServe(addr, h)
+}
+
+// ServeHTTP routes requests to the API endpoints
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "status":
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.serveStatus(w)
+
+	case len(parts) == 3 && parts[0] == "battery" && parts[2] == "drain":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.serveDrain(w, parts[1])
+
+	case len(parts) == 2 && parts[0] == "pattern":
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		line := "pattern " + parts[1]
+		for key, values := range r.URL.Query() {
+			for _, value := range values {
+				line += " " + key + "=" + value
+			}
+		}
+		h.writeText(w, h.console.Execute(line))
+
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveStatus writes the battery and airlock state as JSON
+func (h *Handler) serveStatus(w http.ResponseWriter) {
+	var s status
+	for i, info := range h.panel.GetAllBatteryInfo() {
+		s.Batteries = append(s.Batteries, batteryStatus{
+			Battery:  i + 1,
+			State:    info.State.String(),
+			Level:    info.BatteryLevel,
+			Draining: info.IsDraining,
+		})
+	}
+	if info, ok := h.panel.GetAirLockInfo(); ok {
+		s.AirLock = info.State.String()
+	}
+	s.Paused = h.panel.IsPaused()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s)
+}
+
+// serveDrain toggles draining of a battery by its 1-based number
+func (h *Handler) serveDrain(w http.ResponseWriter, number string) {
+	n, err := strconv.Atoi(number)
+	infos := h.panel.GetAllBatteryInfo()
+	if err != nil || n < 1 || n > len(infos) {
+		http.Error(w, "no such battery", http.StatusNotFound)
+		return
+	}
+
+	action := "drain"
+	if infos[n-1].IsDraining {
+		action = "stop"
+	}
+	h.writeText(w, h.console.Execute("battery "+number+" "+action))
+}
+
+// writeText writes a console response as plain text
+func (h *Handler) writeText(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "text/plain")
+	w.Write([]byte(text + "\n"))
+}
@@ -0,0 +1,9 @@
+//go:build !(tinygo && netcontrol)
+
+package netcontrol
+
+// Connect always fails on builds without WiFi; on the host the net package
+// already works, so the handler can be served directly
+func Connect(ssid, passphrase string) error {
+	return ErrNoWiFi
+}
@@ -0,0 +1,18 @@
+//go:build tinygo && netcontrol
+
+package netcontrol
+
+import (
+	"tinygo.org/x/drivers/netlink"
+	"tinygo.org/x/drivers/netlink/probe"
+)
+
+// Connect brings up the board's WiFi coprocessor (e.g. an AirLift/NINA module)
+// and joins a network, after which the net and net/http packages work
+func Connect(ssid, passphrase string) error {
+	link, _ := probe.Probe()
+	return link.NetConnect(&netlink.ConnectParams{
+		Ssid:       ssid,
+		Passphrase: passphrase,
+	})
+}
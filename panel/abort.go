@@ -0,0 +1,54 @@
+package panel
+
+import (
+	"time"
+)
+
+// abortAlarmDuration is how long the red alarm overlay plays when the panel aborts
+const abortAlarmDuration = 5 * time.Second
+
+// Abort forces every battery Dead and plays a red alarm, e.g. for a hull breach
+// finale. The panel stays aborted until the battery reset input is pressed.
+func (p *Panel) Abort() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.abort(time.Now())
+}
+
+// IsAborted returns whether the panel is latched in the aborted state
+func (p *Panel) IsAborted() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.aborted
+}
+
+// abort kills every battery and starts the alarm (must be called with mutex locked)
+func (p *Panel) abort(now time.Time) {
+	p.aborted = true
+	for _, bat := range p.batteries {
+		bat.ForceDead()
+	}
+	p.overlays = append(p.overlays, activeOverlay{
+		overlay:   FlashOverlay(p.theme.Alarm, 10),
+		startedAt: now,
+		duration:  abortAlarmDuration,
+	})
+	if p.power != nil {
+		p.power.Wake(now)
+	}
+}
+
+// updateAbort aborts on a press of the master kill switch and clears the latch
+// when every battery is reset (must be called with mutex locked)
+func (p *Panel) updateAbort(now time.Time, resetAll bool) {
+	if p.abortSwitch != nil {
+		pressed := isPressed(p.abortSwitch)
+		if pressed && !p.abortPressed {
+			p.abort(now)
+		}
+		p.abortPressed = pressed
+	}
+	if p.aborted && resetAll {
+		p.aborted = false
+	}
+}
@@ -0,0 +1,75 @@
+package panel
+
+import (
+	"math"
+
+	"github.com/christophergm/tinyspacewalk/airlock"
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/render"
+)
+
+// updateAirLockInputs feeds the airlock button and battery power to the airlock,
+// returning whether the button is pressed (must be called with mutex locked)
+func (p *Panel) updateAirLockInputs() bool {
+	if p.airLock == nil {
+		return false
+	}
+
+	pressed := p.airLocktButton != nil && isPressed(p.airLocktButton)
+	p.airLock.SetButton(pressed)
+
+	// Any battery that isn't dead can run the pumps
+	p.airLock.SetPoweredBatteries(p.bank.AliveCount())
+	return pressed
+}
+
+// GetAirLockInfo returns the airlock state, ok is false when the panel has no airlock
+func (p *Panel) GetAirLockInfo() (info airlock.Info, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.airLock == nil {
+		return airlock.Info{}, false
+	}
+	return p.airLock.GetInfo(), true
+}
+
+// renderAirLock draws the airlock section (must be called with mutex locked)
+func (p *Panel) renderAirLock() {
+	seg := p.airLockSegment
+	if p.airLock == nil || seg.Strip == nil {
+		return
+	}
+
+	info := p.airLock.GetInfo()
+	seg.fill(Black)
+
+	switch info.State {
+	case airlock.Sealed:
+		// Steady when the door can be opened, pulsing a warning when there isn't enough power
+		if info.Powered {
+			seg.fill(p.theme.AirLockReady)
+		} else {
+			seg.fill(colorutil.Scale(p.theme.AirLockWarning, 0.5+0.5*math.Sin(p.pulsePhase*2*math.Pi)))
+		}
+	case airlock.Depressurizing:
+		// Bar emptying as the air is pumped out, with a chasing light
+		p.displayAirLockCycle(seg, 1-info.Progress)
+	case airlock.Open:
+		// Flashing a warning while the door is open
+		if p.flashPhase < 0.5 {
+			seg.fill(p.theme.AirLockWarning)
+		}
+	case airlock.Pressurizing:
+		// Bar filling as the air returns
+		p.displayAirLockCycle(seg, info.Progress)
+	}
+}
+
+// displayAirLockCycle shows the pressure as a bar with a light chasing through it
+func (p *Panel) displayAirLockCycle(seg Segment, pressure float64) {
+	render.Bar(seg, pressure*100, p.theme.AirLockCycle)
+
+	chasePos := int(p.flashPhase * float64(seg.Length))
+	seg.setPixel(chasePos, Yellow)
+}
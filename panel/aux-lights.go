@@ -0,0 +1,102 @@
+package panel
+
+import (
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// AuxLight is a dimmable light beside the strips, e.g. a peripheral.PwmLight
+type AuxLight interface {
+	SetBrightness(brightness uint8)
+	FadeTo(target uint8, duration time.Duration)
+}
+
+// AuxLightConfig attaches an auxiliary light that tracks the system state
+type AuxLightConfig struct {
+	Light AuxLight
+	// Brightness picks the light's brightness (0-255) from the panel status and battery info
+	Brightness func(status Status, infos []battery.BatteryInfo) uint8
+	Fade       time.Duration // How long the light takes to reach a new brightness
+}
+
+// auxLight is an attached light and the brightness it was last sent
+type auxLight struct {
+	config AuxLightConfig
+	target uint8
+	primed bool
+}
+
+// CabinetLighting is lit while the show runs, dims while paused and in standby,
+// and goes out after an abort or a lost game
+func CabinetLighting(on, dim uint8) func(Status, []battery.BatteryInfo) uint8 {
+	return func(status Status, infos []battery.BatteryInfo) uint8 {
+		switch status {
+		case StatusRunning, StatusDemo, StatusError, StatusWon:
+			return on
+		case StatusAborted, StatusLost:
+			return 0
+		default:
+			return dim
+		}
+	}
+}
+
+// BatteryHalo follows a battery (0-based): full while charged, its level in 10%
+// steps while draining or charging, and off once dead
+func BatteryHalo(batteryIndex int) func(Status, []battery.BatteryInfo) uint8 {
+	return func(status Status, infos []battery.BatteryInfo) uint8 {
+		if batteryIndex < 0 || batteryIndex >= len(infos) {
+			return 0
+		}
+		info := infos[batteryIndex]
+		switch info.State {
+		case battery.Charged:
+			return 255
+		case battery.Dead:
+			return 0
+		default:
+			return uint8(int(min(max(info.BatteryLevel, 0), 100)) / 10 * 255 / 10)
+		}
+	}
+}
+
+// AddAuxLight attaches an auxiliary light updated every tick
+func (p *Panel) AddAuxLight(config AuxLightConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if config.Light == nil || config.Brightness == nil {
+		return
+	}
+	p.auxLights = append(p.auxLights, &auxLight{config: config})
+}
+
+// batteryInfos refreshes and returns the reused snapshot of every battery's info
+// for aux lights and gauges (must be called with mutex locked)
+func (p *Panel) batteryInfos() []battery.BatteryInfo {
+	p.auxInfos = p.auxInfos[:0]
+	for _, bat := range p.batteries {
+		p.auxInfos = append(p.auxInfos, bat.GetInfo())
+	}
+	return p.auxInfos
+}
+
+// updateAuxLights fades each auxiliary light to the brightness its rule picks
+// (must be called with mutex locked)
+func (p *Panel) updateAuxLights(now time.Time) {
+	if len(p.auxLights) == 0 {
+		return
+	}
+
+	status := p.status(now)
+	infos := p.batteryInfos()
+	for _, light := range p.auxLights {
+		target := light.config.Brightness(status, infos)
+		if light.primed && target == light.target {
+			continue
+		}
+		light.target = target
+		light.primed = true
+		light.config.Light.FadeTo(target, light.config.Fade)
+	}
+}
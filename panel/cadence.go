@@ -0,0 +1,113 @@
+package panel
+
+import (
+	"math"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// Cadence is a blink rhythm laid over a section's animation, so states can be
+// told apart by timing as well as color
+type Cadence int
+
+const (
+	Solid       Cadence = iota // Always on
+	SlowBlink                  // On for half of each flash period
+	FastBlink                  // Four blinks per flash period
+	DoublePulse                // Two short flashes, then dark for the rest of the flash period
+	Wink                       // On, dropping out briefly once per flash period
+	TriplePulse                // Three short flashes, then dark for the rest of the flash period
+)
+
+// String returns the cadence's name
+func (c Cadence) String() string {
+	switch c {
+	case Solid:
+		return "Solid"
+	case SlowBlink:
+		return "SlowBlink"
+	case FastBlink:
+		return "FastBlink"
+	case DoublePulse:
+		return "DoublePulse"
+	case Wink:
+		return "Wink"
+	case TriplePulse:
+		return "TriplePulse"
+	default:
+		return "Unknown"
+	}
+}
+
+// on returns whether the cadence is lit at phase (0.0 to 1.0) of the flash period
+func (c Cadence) on(phase float64) bool {
+	switch c {
+	case SlowBlink:
+		return phase < 0.5
+	case FastBlink:
+		return math.Mod(phase*4, 1) < 0.5
+	case DoublePulse:
+		return phase < 0.1 || (phase >= 0.2 && phase < 0.3)
+	case Wink:
+		return phase < 0.9
+	case TriplePulse:
+		return phase < 0.3 && math.Mod(phase*10, 2) < 1
+	default:
+		return true
+	}
+}
+
+// StateCadences sets the cadence of each battery state; the zero value leaves every state Solid
+type StateCadences struct {
+	Charged       Cadence
+	Disconnecting Cadence
+	Draining      Cadence
+	Dead          Cadence
+	Charging      Cadence
+	Idle          Cadence
+	Overheated    Cadence
+}
+
+// AccessibleCadences gives every state its own rhythm. Charging keeps its climbing
+// light, which already sets it apart from the steady charged section.
+func AccessibleCadences() StateCadences {
+	return StateCadences{
+		Charged:       Solid,
+		Disconnecting: FastBlink,
+		Draining:      SlowBlink,
+		Dead:          DoublePulse,
+		Charging:      Solid,
+		Idle:          Wink,
+		Overheated:    TriplePulse,
+	}
+}
+
+// forState returns the cadence of a state, Solid for states without one
+func (c StateCadences) forState(state battery.SystemState) Cadence {
+	switch state {
+	case battery.Charged:
+		return c.Charged
+	case battery.Disconnecting:
+		return c.Disconnecting
+	case battery.Draining:
+		return c.Draining
+	case battery.Dead:
+		return c.Dead
+	case battery.Charging:
+		return c.Charging
+	case battery.Idle:
+		return c.Idle
+	case battery.Overheated:
+		return c.Overheated
+	default:
+		return Solid
+	}
+}
+
+// applyCadence blanks a section drawn for state while its cadence is dark
+// (must be called with mutex locked)
+func (p *Panel) applyCadence(seg Segment, state battery.SystemState) {
+	if !p.theme.Cadences.forState(state).on(p.flashPhase) {
+		seg.fill(Black)
+	}
+}
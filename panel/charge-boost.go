@@ -0,0 +1,85 @@
+package panel
+
+import (
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// ChargeBoostConfig lets players charge batteries by making noise: once the
+// input stays loud for Sustain, batteries gain charge for as long as it does
+type ChargeBoostConfig struct {
+	Input           peripheral.AnalogReader // Loudness, e.g. a peripheral.Microphone; nil disables the boost
+	Threshold       int                     // Percentage the input must reach to count as loud
+	Sustain         time.Duration           // How long the input must stay loud before charge flows
+	ChargePerSecond float32                 // Percentage points added per second while boosting
+	Batteries       []int                   // Batteries (0-based) boosted, all when empty
+}
+
+// DefaultChargeBoostConfig returns a boost for a shouting crew: a second of
+// noise, then 5% a second
+func DefaultChargeBoostConfig(input peripheral.AnalogReader) ChargeBoostConfig {
+	return ChargeBoostConfig{
+		Input:           input,
+		Threshold:       60,
+		Sustain:         time.Second,
+		ChargePerSecond: 5,
+	}
+}
+
+// chargeBoost tracks how long the input has been loud
+type chargeBoost struct {
+	config    ChargeBoostConfig
+	loudSince time.Time // Zero while quiet
+	boosting  bool
+}
+
+// IsBoosting returns whether noise is currently charging the batteries
+func (p *Panel) IsBoosting() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.boost != nil && p.boost.boosting
+}
+
+// updateChargeBoost adds charge while the input has been loud for long enough
+// (must be called with mutex locked)
+func (p *Panel) updateChargeBoost(now time.Time, deltaTime float64) {
+	b := p.boost
+	if b == nil {
+		return
+	}
+
+	if b.config.Input.ReadPercentage() < b.config.Threshold {
+		b.loudSince = time.Time{}
+		b.boosting = false
+		return
+	}
+	if b.loudSince.IsZero() {
+		b.loudSince = now
+	}
+	b.boosting = now.Sub(b.loudSince) >= b.config.Sustain
+	if !b.boosting {
+		return
+	}
+
+	amount := b.config.ChargePerSecond * float32(deltaTime)
+	if len(b.config.Batteries) == 0 {
+		for _, bat := range p.batteries {
+			boostBattery(bat, amount)
+		}
+		return
+	}
+	for _, i := range b.config.Batteries {
+		if i >= 0 && i < len(p.batteries) {
+			boostBattery(p.batteries[i], amount)
+		}
+	}
+}
+
+// boostBattery adds charge to a battery that isn't already full
+func boostBattery(bat *battery.Battery, amount float32) {
+	if bat.GetInfo().State != battery.Charged {
+		bat.AddCharge(amount)
+	}
+}
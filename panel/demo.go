@@ -1,94 +1,247 @@
 package panel
 
 import (
-	"math/rand"
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/christophergm/tinyspacewalk/patterns"
 	"github.com/christophergm/tinyspacewalk/peripheral"
 )
 
-// DemoAllBatteries runs a demo sequence of inputs to mock
-// battery connect inputs with context support for graceful shutdown
-func (p *Panel) DemoAllBatteries(mockBatteryConnects []*peripheral.MockButton, neoPixel peripheral.NeoPixel) {
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+// ErrUnknownDemo is returned when no built-in demo has the requested name
+var ErrUnknownDemo = errors.New("unknown demo")
 
-	for {
-		select {
-		case <-p.ctx.Done():
-			// Clean up: turn off all mock buttons
-			for _, button := range mockBatteryConnects {
-				button.SetPressed(false)
-			}
-			return
-		case <-ticker.C:
-			// Demo sequence - simulate input presses
-			// Set draining to true for all batteries (simulate button press)
-			for _, button := range mockBatteryConnects {
-				select {
-				case <-p.ctx.Done():
-					return
-				default:
-					button.SetPressed(true)
-					// Use a shorter sleep with context checking
-					if !p.sleepWithContext(1 * time.Second) {
-						return
-					}
-				}
-			}
+// DemoAction is what a demo step does
+type DemoAction int
 
-			// Wait longer period with context checking
-			if !p.sleepWithContext(10 * time.Second) {
-				return
-			}
+const (
+	DemoPress   DemoAction = iota // Press the target input
+	DemoRelease                   // Release the target input
+	DemoRandom                    // Press or release a random battery connect input
+	DemoWait                      // Wait before the next step
+)
 
-			// Turn off all batteries
-			for _, button := range mockBatteryConnects {
-				select {
-				case <-p.ctx.Done():
-					return
-				default:
-					button.SetPressed(false)
-				}
+// Demo step targets besides battery indexes
+const (
+	DemoAll   = -1 // Every battery connect input
+	DemoReset = -2 // The battery reset input
+)
+
+// DemoStep is a single step of a demo scenario
+type DemoStep struct {
+	Action DemoAction
+	Target int           // Battery index (0-based), DemoAll or DemoReset
+	Wait   time.Duration // Only used by DemoWait
+}
+
+// DemoScenario is a named sequence of timed input steps that drives the mock inputs
+type DemoScenario struct {
+	Name  string
+	Steps []DemoStep
+	Loop  bool // Restart from the first step after the last
+}
+
+// Built-in demo scenarios, one per file. Each line is a step:
+//
+//	press 1|all|reset
+//	release 1|all|reset
+//	random any
+//	wait 1s
+//	loop
+//
+//go:embed demos/*.demo
+var demoFiles embed.FS
+
+// BuiltinDemo returns the embedded demo scenario with the given name, e.g. "random-batteries"
+func BuiltinDemo(name string) (DemoScenario, error) {
+	data, err := demoFiles.ReadFile("demos/" + name + ".demo")
+	if err != nil {
+		return DemoScenario{}, fmt.Errorf("%w: %s", ErrUnknownDemo, name)
+	}
+	return ParseDemoScenario(name, string(data))
+}
+
+// DemoNames returns the names of the built-in demo scenarios, sorted
+func DemoNames() []string {
+	entries, _ := demoFiles.ReadDir("demos")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".demo"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseDemoScenario reads a demo scenario from its text form; blank lines and # comments are ignored
+func ParseDemoScenario(name, text string) (DemoScenario, error) {
+	scenario := DemoScenario{Name: name}
+
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		step, err := parseDemoStep(fields)
+		if err != nil {
+			return DemoScenario{}, fmt.Errorf("demo %s line %d: %w", name, lineNumber, err)
+		}
+		if fields[0] == "loop" {
+			scenario.Loop = true
+			continue
+		}
+		scenario.Steps = append(scenario.Steps, step)
+	}
+
+	// A loop that never waits would spin the demo goroutine flat out
+	if scenario.Loop && scenario.duration() <= 0 {
+		return DemoScenario{}, fmt.Errorf("demo %s: loop needs a wait", name)
+	}
+	return scenario, nil
+}
+
+// duration returns the total time a scenario waits in one pass
+func (s DemoScenario) duration() time.Duration {
+	var total time.Duration
+	for _, step := range s.Steps {
+		if step.Action == DemoWait {
+			total += step.Wait
+		}
+	}
+	return total
+}
+
+// parseDemoStep parses the fields of a single demo line
+func parseDemoStep(fields []string) (DemoStep, error) {
+	switch fields[0] {
+	case "loop":
+		if len(fields) != 1 {
+			return DemoStep{}, errors.New("usage: loop")
+		}
+		return DemoStep{}, nil
+	case "wait":
+		if len(fields) != 2 {
+			return DemoStep{}, errors.New("usage: wait <duration>")
+		}
+		wait, err := time.ParseDuration(fields[1])
+		if err != nil || wait < 0 {
+			return DemoStep{}, fmt.Errorf("invalid duration %q", fields[1])
+		}
+		return DemoStep{Action: DemoWait, Wait: wait}, nil
+	case "random":
+		if len(fields) != 2 || fields[1] != "any" {
+			return DemoStep{}, errors.New("usage: random any")
+		}
+		return DemoStep{Action: DemoRandom, Target: DemoAll}, nil
+	case "press", "release":
+		if len(fields) != 2 {
+			return DemoStep{}, fmt.Errorf("usage: %s <battery>|all|reset", fields[0])
+		}
+		step := DemoStep{Action: DemoPress}
+		if fields[0] == "release" {
+			step.Action = DemoRelease
+		}
+		switch fields[1] {
+		case "all":
+			step.Target = DemoAll
+		case "reset":
+			step.Target = DemoReset
+		default:
+			n, err := strconv.Atoi(fields[1])
+			if err != nil || n < 1 {
+				return DemoStep{}, fmt.Errorf("invalid battery %q", fields[1])
 			}
+			step.Target = n - 1
 		}
+		return step, nil
+	default:
+		return DemoStep{}, fmt.Errorf("unknown step %q", fields[0])
 	}
 }
 
-// DemoRandomBatteries randomly toggles individual inputs to
-// mock battery connect inputs with context support for graceful shutdown
-func (p *Panel) DemoRandomBatteries(batteryResetButton *peripheral.MockButton, mockBatteryConnects []*peripheral.MockButton, neoPixel peripheral.NeoPixel) {
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+// StartDemo runs a demo scenario in a goroutine until the panel stops, returning true
+// once started or false when a demo is already running. Demos press mock buttons directly when the panel was built with them;
+// with real inputs they drive the input overrides instead, so a demo can run on
+// installed hardware, and hand the inputs back when they end.
+func (p *Panel) StartDemo(scenario DemoScenario) bool {
+	if !p.claimDemo() {
+		return false
+	}
+	inputs := demoInputs{panel: p, count: len(p.batteryConnects)}
 
-	defer func() {
-		// Clean up: turn off all mock buttons
-		batteryResetButton.SetPressed(false)
-		for _, button := range mockBatteryConnects {
-			button.SetPressed(false)
+	connects := make([]*peripheral.MockButton, 0, len(p.batteryConnects))
+	for _, buttonReader := range p.batteryConnects {
+		if mockButton, ok := buttonReader.(*peripheral.MockButton); ok {
+			connects = append(connects, mockButton)
 		}
-	}()
+	}
+	if len(connects) == len(p.batteryConnects) {
+		inputs.connects = connects
+	}
+	inputs.reset, _ = p.batteryResetButton.(*peripheral.MockButton)
+
+	// Demos draw from their own source so they don't disturb the flicker sequence
+	p.mu.RLock()
+	inputs.rng = patterns.NewRand(p.seed)
+	p.mu.RUnlock()
+
+	go p.runDemo(scenario, inputs)
+	return true
+}
+
+// runDemo plays a scenario against the inputs until it ends or the panel stops
+func (p *Panel) runDemo(scenario DemoScenario, inputs demoInputs) {
+	// Clean up: let go of every input the demo pressed, then let another demo start
+	defer p.endDemo()
+	defer inputs.release()
 
 	for {
-		select {
-		case <-p.ctx.Done():
-			return
-		case <-ticker.C:
-			// Pick a random battery (0-4)
-			batteryNum := rand.Intn(5)
-			// Pick a random action (true/false)
-			pressed := rand.Float32() < 0.5
-			// Apply to a random input handler
-			mockBatteryConnects[batteryNum].SetPressed(pressed)
-
-			// Wait a bit more with context checking
-			if !p.sleepWithContext(1 * time.Second) {
+		for _, step := range scenario.Steps {
+			if step.Action == DemoWait {
+				if !p.sleepWithContext(step.Wait) {
+					return
+				}
+				continue
+			}
+
+			select {
+			case <-p.ctx.Done():
 				return
+			default:
 			}
+			applyDemoStep(step, inputs)
+		}
 
-			// Always set first battery to true (as in original)
-			mockBatteryConnects[0].SetPressed(true)
+		if !scenario.Loop || scenario.duration() <= 0 {
+			return
+		}
+	}
+}
+
+// applyDemoStep presses or releases the inputs a step targets
+func applyDemoStep(step DemoStep, inputs demoInputs) {
+	pressed := step.Action == DemoPress
+	switch {
+	case step.Action == DemoRandom:
+		if inputs.count > 0 {
+			inputs.setConnect(inputs.rng.Intn(inputs.count), inputs.rng.Float32() < 0.5)
 		}
+	case step.Target == DemoAll:
+		for i := 0; i < inputs.count; i++ {
+			inputs.setConnect(i, pressed)
+		}
+	case step.Target == DemoReset:
+		inputs.setReset(pressed)
+	case step.Target >= 0 && step.Target < inputs.count:
+		inputs.setConnect(step.Target, pressed)
 	}
 }
 
@@ -106,39 +259,21 @@ func (p *Panel) sleepWithContext(duration time.Duration) bool {
 	}
 }
 
-// StartDemoAllBatteries starts the DemoAllBatteries routine in a goroutine
-// This is a convenience method that checks if we're using mock buttons
-func (p *Panel) StartDemoAllBatteries(neoPixel peripheral.NeoPixel) {
-	// Try to cast batteryConnects to mock buttons
-	mockButtons := make([]*peripheral.MockButton, 0, len(p.batteryConnects))
-	for _, buttonReader := range p.batteryConnects {
-		if mockButton, ok := buttonReader.(*peripheral.MockButton); ok {
-			mockButtons = append(mockButtons, mockButton)
-		}
-	}
-
-	// Only start demo if all buttons are mock buttons
-	if len(mockButtons) == len(p.batteryConnects) {
-		go p.DemoAllBatteries(mockButtons, neoPixel)
+// claimDemo marks the panel as running a demo for the status indicator,
+// returning false when one already is
+func (p *Panel) claimDemo() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.demo {
+		return false
 	}
+	p.demo = true
+	return true
 }
 
-// StartDemoRandomBatteries starts the DemoRandomBatteries routine in a goroutine
-// This is a convenience method that checks if we're using mock buttons
-func (p *Panel) StartDemoRandomBatteries(neoPixel peripheral.NeoPixel) {
-	// Try to cast batteryConnects to mock buttons
-	mockButtons := make([]*peripheral.MockButton, 0, len(p.batteryConnects))
-	for _, buttonReader := range p.batteryConnects {
-		if mockButton, ok := buttonReader.(*peripheral.MockButton); ok {
-			mockButtons = append(mockButtons, mockButton)
-		}
-	}
-
-	// Try to cast battery reset button to mock button
-	if mockResetButton, ok := p.batteryResetButton.(*peripheral.MockButton); ok {
-		// Only start demo if all buttons are mock buttons
-		if len(mockButtons) == len(p.batteryConnects) {
-			go p.DemoRandomBatteries(mockResetButton, mockButtons, neoPixel)
-		}
-	}
+// endDemo clears the demo mark once a demo has ended
+func (p *Panel) endDemo() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.demo = false
 }
@@ -0,0 +1,48 @@
+package panel
+
+import (
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// difficultyDeadband is the knob movement in percent needed before the drain rate changes
+const difficultyDeadband = 2
+
+// SetDifficulty scales every battery's drain rate from a knob percentage:
+// 50 is the configured rate, 0 drains at half speed and 100 at double speed
+func (p *Panel) SetDifficulty(percentage int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.setDifficulty(percentage)
+}
+
+// Difficulty returns the difficulty percentage and the drain rate multiplier it applies
+func (p *Panel) Difficulty() (percentage int, multiplier float32) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.difficulty, battery.KnobMultiplier(p.difficulty)
+}
+
+// setDifficulty applies a difficulty to every battery (must be called with mutex locked)
+func (p *Panel) setDifficulty(percentage int) {
+	percentage = min(max(percentage, 0), 100)
+	p.difficulty = percentage
+	multiplier := battery.KnobMultiplier(percentage)
+	for _, bat := range p.batteries {
+		bat.SetRateMultiplier(multiplier)
+	}
+}
+
+// updateDifficulty follows the difficulty knob, ignoring jitter within the
+// deadband (must be called with mutex locked)
+func (p *Panel) updateDifficulty() {
+	if p.difficultyKnob == nil {
+		return
+	}
+	percentage := p.difficultyKnob.ReadPercentage()
+	delta := percentage - p.difficulty
+	if p.difficultyRead && delta <= difficultyDeadband && delta >= -difficultyDeadband {
+		return
+	}
+	p.difficultyRead = true
+	p.setDifficulty(percentage)
+}
@@ -0,0 +1,41 @@
+package panel
+
+import (
+	"sort"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// handleFault logs a peripheral failing or recovering and keeps the error status
+// showing while any peripheral is failing, so a dead strip still shows on the NeoPixel
+func (p *Panel) handleFault(fault peripheral.Fault) {
+	p.mu.Lock()
+	if fault.Err != nil {
+		p.failing[fault.Source] = fault.Err
+	} else {
+		delete(p.failing, fault.Source)
+	}
+	p.mu.Unlock()
+
+	if p.log == nil {
+		return
+	}
+	if fault.Err != nil {
+		p.log.Error("%s", fault)
+	} else {
+		p.log.Info("%s", fault)
+	}
+}
+
+// Failing returns the peripherals currently reported failing, e.g. "LED strip: spi timeout"
+func (p *Panel) Failing() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	failing := make([]string, 0, len(p.failing))
+	for source, err := range p.failing {
+		failing = append(failing, peripheral.Fault{Source: source, Err: err}.String())
+	}
+	sort.Strings(failing)
+	return failing
+}
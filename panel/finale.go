@@ -0,0 +1,112 @@
+package panel
+
+import (
+	"math"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// Finale timing
+const (
+	winCascadeTime = 2 * time.Second // Green climbing every strip
+	winHoldTime    = 3 * time.Second // Whole strip breathing green
+	winFadeTime    = time.Second     // Fade to black before the panel takes back over
+	lossStrobeTime = 3 * time.Second // Red strobe
+	lossFadeTime   = time.Second     // Red dying away
+	lossBlackTime  = time.Second     // Blackout before the panel takes back over
+	lossStrobeHz   = 8
+	lossStrobeDuty = 0.3
+	winFinaleTime  = winCascadeTime + winHoldTime + winFadeTime
+	lossFinaleTime = lossStrobeTime + lossFadeTime + lossBlackTime
+)
+
+// finale is a running end-of-game sequence
+type finale struct {
+	win       bool
+	startedAt time.Time
+}
+
+// length returns how long the sequence plays
+func (f finale) length() time.Duration {
+	if f.win {
+		return winFinaleTime
+	}
+	return lossFinaleTime
+}
+
+// PlayFinale takes over every strip and the status indicator for the end of the
+// game: a green cascade for a win, or a red strobe into blackout for a loss.
+// It returns at once; the panel draws the sequence on its own ticks and goes
+// back to the battery display when it ends. Playing another finale restarts it.
+func (p *Panel) PlayFinale(win bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.finale = &finale{win: win, startedAt: now}
+
+	// The finale is drawn at the full update rate even from standby
+	if p.power != nil {
+		p.power.Wake(now)
+		if p.running && p.power.IsStandby() {
+			p.animationTicker.Reset(p.updateRate)
+		}
+	}
+}
+
+// IsPlayingFinale returns whether a finale owns the strips
+func (p *Panel) IsPlayingFinale() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.finale != nil
+}
+
+// updateFinale draws the running finale, handing the strips back once it has
+// played (must be called with mutex locked)
+func (p *Panel) updateFinale(now time.Time) {
+	elapsed := now.Sub(p.finale.startedAt)
+	if elapsed >= p.finale.length() {
+		p.finale = nil
+		p.fullRedraw = true
+		return
+	}
+
+	p.updateAnimationPhases(now.Sub(p.lastUpdate).Seconds())
+	p.lastUpdate = now
+	p.showStatus(now)
+	p.updateAuxLights(now)
+
+	for _, strip := range p.strips {
+		strip.SetAll(Black)
+		n := strip.NumLEDs()
+
+		if p.finale.win {
+			switch {
+			case elapsed < winCascadeTime:
+				// Green climbs the strip with a white leading edge
+				lit := int(float64(n) * float64(elapsed) / float64(winCascadeTime))
+				for i := 0; i < lit; i++ {
+					strip.SetPixel(i, Green)
+				}
+				strip.SetPixel(lit, White)
+			case elapsed < winCascadeTime+winHoldTime:
+				strip.SetAll(colorutil.Scale(Green, 0.6+0.4*math.Cos(p.pulsePhase*2*math.Pi)))
+			default:
+				fade := float64(elapsed-winCascadeTime-winHoldTime) / float64(winFadeTime)
+				strip.SetAll(colorutil.Scale(Green, 1-fade))
+			}
+		} else {
+			switch {
+			case elapsed < lossStrobeTime:
+				if math.Mod(elapsed.Seconds()*lossStrobeHz, 1) < lossStrobeDuty {
+					strip.SetAll(Red)
+				}
+			case elapsed < lossStrobeTime+lossFadeTime:
+				fade := float64(elapsed-lossStrobeTime) / float64(lossFadeTime)
+				strip.SetAll(colorutil.Scale(Red, 1-fade))
+			}
+		}
+		strip.ShowIfDirty()
+	}
+}
@@ -0,0 +1,111 @@
+package panel
+
+import (
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/timer"
+)
+
+// Needle is an analog needle gauge, e.g. a peripheral.Servo or Stepper, positioned from
+// 0.0 (empty) to 1.0 (full scale)
+type Needle interface {
+	SweepTo(position float32, duration time.Duration)
+}
+
+// DefaultGaugeSweep is how long a needle takes to cross the full scale, slow
+// enough to read as a heavy, old-fashioned meter
+const DefaultGaugeSweep = 2 * time.Second
+
+// gaugeDeadband is the smallest reading change, in percent, that moves a needle,
+// so a slowly draining bank steps the needle instead of sweeping it every tick
+const gaugeDeadband = 1
+
+// GaugeConfig attaches a needle gauge that tracks the batteries
+type GaugeConfig struct {
+	Needle Needle
+	// Reading picks the gauge reading (0-100) from the panel status and battery info, BankCharge if nil
+	Reading func(status Status, infos []battery.BatteryInfo) float32
+	Sweep   time.Duration // How long the needle takes to cross the full scale, DefaultGaugeSweep if 0
+}
+
+// gauge is an attached gauge and the reading it was last sent
+type gauge struct {
+	config  GaugeConfig
+	reading float32
+	primed  bool
+}
+
+// BankCharge reads the mean battery level across the bank, so the gauge shows
+// how much power the station has left overall
+func BankCharge(status Status, infos []battery.BatteryInfo) float32 {
+	if len(infos) == 0 {
+		return 0
+	}
+	var total float32
+	for _, info := range infos {
+		total += min(max(info.BatteryLevel, 0), 100)
+	}
+	return total / float32(len(infos))
+}
+
+// BatteryLevel reads one battery's level (0-based), e.g. for a dial watching the weakest cell
+func BatteryLevel(batteryIndex int) func(Status, []battery.BatteryInfo) float32 {
+	return func(status Status, infos []battery.BatteryInfo) float32 {
+		if batteryIndex < 0 || batteryIndex >= len(infos) {
+			return 0
+		}
+		return infos[batteryIndex].BatteryLevel
+	}
+}
+
+// TimeRemaining reads the time left on a mission clock as a percentage of its
+// duration, e.g. for an oxygen dial running down with the clock
+func TimeRemaining(t *timer.Timer) func(Status, []battery.BatteryInfo) float32 {
+	return func(status Status, infos []battery.BatteryInfo) float32 {
+		return float32(t.GetInfo().Fraction() * 100)
+	}
+}
+
+// AddGauge attaches a needle gauge updated every tick
+func (p *Panel) AddGauge(config GaugeConfig) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if config.Needle == nil {
+		return
+	}
+	if config.Reading == nil {
+		config.Reading = BankCharge
+	}
+	if config.Sweep <= 0 {
+		config.Sweep = DefaultGaugeSweep
+	}
+	p.gauges = append(p.gauges, &gauge{config: config})
+}
+
+// updateGauges sweeps each gauge's needle to its reading at a steady speed
+// (must be called with mutex locked)
+func (p *Panel) updateGauges(now time.Time) {
+	if len(p.gauges) == 0 {
+		return
+	}
+
+	status := p.status(now)
+	infos := p.batteryInfos()
+	for _, g := range p.gauges {
+		reading := min(max(g.config.Reading(status, infos), 0), 100)
+		delta := reading - g.reading
+		if delta < 0 {
+			delta = -delta
+		}
+		if g.primed && delta < gaugeDeadband {
+			continue
+		}
+		if !g.primed {
+			delta = reading // The needle starts from rest at zero
+		}
+		g.reading = reading
+		g.primed = true
+		g.config.Needle.SweepTo(reading/100, time.Duration(float32(g.config.Sweep)*delta/100))
+	}
+}
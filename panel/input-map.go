@@ -0,0 +1,131 @@
+package panel
+
+import (
+	"fmt"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// RoleKind is what a logical panel input does
+type RoleKind int
+
+const (
+	ConnectRole      RoleKind = iota // A battery's connect signal, which drains it
+	ChargerRole                      // A battery's charger dock
+	BatteryResetRole                 // Resets one battery
+	ResetRole                        // Resets every battery
+	AirLockRole                      // Airlock door button
+	AbortRole                        // Master kill switch
+)
+
+// Role is a logical panel input; Battery (0-based) is only used by the per-battery kinds
+type Role struct {
+	Kind    RoleKind
+	Battery int
+}
+
+// Connect returns the connect role of a battery (0-based)
+func Connect(battery int) Role {
+	return Role{Kind: ConnectRole, Battery: battery}
+}
+
+// Charger returns the charger dock role of a battery (0-based)
+func Charger(battery int) Role {
+	return Role{Kind: ChargerRole, Battery: battery}
+}
+
+// BatteryReset returns the reset role of a battery (0-based)
+func BatteryReset(battery int) Role {
+	return Role{Kind: BatteryResetRole, Battery: battery}
+}
+
+// Roles without a battery
+var (
+	Reset   = Role{Kind: ResetRole}
+	AirLock = Role{Kind: AirLockRole}
+	Abort   = Role{Kind: AbortRole}
+)
+
+// String returns the role's name, e.g. "connect 2" for the second battery
+func (r Role) String() string {
+	switch r.Kind {
+	case ConnectRole:
+		return fmt.Sprintf("connect %d", r.Battery+1)
+	case ChargerRole:
+		return fmt.Sprintf("charger %d", r.Battery+1)
+	case BatteryResetRole:
+		return fmt.Sprintf("reset %d", r.Battery+1)
+	case ResetRole:
+		return "reset"
+	case AirLockRole:
+		return "airlock"
+	case AbortRole:
+		return "abort"
+	default:
+		return "unknown"
+	}
+}
+
+// InputMapping wires a physical input to the logical roles it drives, so wiring
+// mistakes can be fixed in config rather than with a soldering iron. Mapping
+// connect 1 to Connect(1) and connect 2 to Connect(0) swaps two batteries; one
+// mapping with several roles lets a single switch drive several batteries.
+type InputMapping struct {
+	Input  peripheral.ButtonReader
+	Roles  []Role
+	Invert bool // The input is pressed when it reads released, e.g. a normally-closed switch
+}
+
+// applyInputMap replaces the inputs of every role in the map with the inputs
+// mapped to it, combining several inputs mapped to the same role so any of them
+// drives it. Roles not in the map keep the input set directly in the config.
+func (config *PanelConfig) applyInputMap(numBatteries int) {
+	if len(config.InputMap) == 0 {
+		return
+	}
+
+	mapped := make(map[Role][]peripheral.ButtonReader)
+	for _, m := range config.InputMap {
+		input := m.Input
+		if m.Invert {
+			input = peripheral.Inverted(input)
+		}
+		for _, role := range m.Roles {
+			mapped[role] = append(mapped[role], input)
+		}
+	}
+
+	for role, inputs := range mapped {
+		input := inputs[0]
+		if len(inputs) > 1 {
+			input = peripheral.AnyPressed(inputs...)
+		}
+
+		switch role.Kind {
+		case ConnectRole:
+			config.BatteryConnects = setRoleInput(config.BatteryConnects, role.Battery, numBatteries, input)
+		case ChargerRole:
+			config.BatteryChargers = setRoleInput(config.BatteryChargers, role.Battery, numBatteries, input)
+		case BatteryResetRole:
+			config.BatteryResetButtons = setRoleInput(config.BatteryResetButtons, role.Battery, numBatteries, input)
+		case ResetRole:
+			config.BatteryResetButton = input
+		case AirLockRole:
+			config.AirLockButton = input
+		case AbortRole:
+			config.AbortSwitch = input
+		}
+	}
+}
+
+// setRoleInput returns a copy of a per-battery input list with one battery's input
+// replaced, growing it to numBatteries if needed; other batteries keep their input
+func setRoleInput(inputs []peripheral.ButtonReader, battery, numBatteries int, input peripheral.ButtonReader) []peripheral.ButtonReader {
+	if battery < 0 || battery >= numBatteries {
+		return inputs
+	}
+	updated := make([]peripheral.ButtonReader, max(len(inputs), numBatteries))
+	copy(updated, inputs)
+	updated[battery] = input
+	return updated
+}
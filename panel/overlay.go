@@ -0,0 +1,91 @@
+package panel
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Overlay is a one-shot effect composited on top of the battery display
+type Overlay interface {
+	// Render draws the effect onto a strip, progress runs from 0.0 to 1.0 over the overlay's duration
+	Render(strip peripheral.LedStrip, progress float64)
+}
+
+// OverlayFunc adapts a plain function to the Overlay interface
+type OverlayFunc func(strip peripheral.LedStrip, progress float64)
+
+// Render calls the function
+func (f OverlayFunc) Render(strip peripheral.LedStrip, progress float64) {
+	f(strip, progress)
+}
+
+// activeOverlay is an overlay currently playing
+type activeOverlay struct {
+	overlay   Overlay
+	startedAt time.Time
+	duration  time.Duration
+}
+
+// PlayOverlay composites effect on top of the battery sections for duration,
+// after which the display automatically reverts to normal
+func (p *Panel) PlayOverlay(effect Overlay, duration time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if duration <= 0 {
+		return
+	}
+	p.overlays = append(p.overlays, activeOverlay{
+		overlay:   effect,
+		startedAt: time.Now(),
+		duration:  duration,
+	})
+}
+
+// renderOverlays draws active overlays and drops finished ones (must be called with mutex locked)
+func (p *Panel) renderOverlays(now time.Time) {
+	remaining := p.overlays[:0]
+	for _, active := range p.overlays {
+		progress := float64(now.Sub(active.startedAt)) / float64(active.duration)
+		if progress >= 1 {
+			// Sections under a finished overlay must all be redrawn
+			p.fullRedraw = true
+			continue
+		}
+		for _, strip := range p.strips {
+			active.overlay.Render(strip, progress)
+		}
+		remaining = append(remaining, active)
+	}
+	p.overlays = remaining
+}
+
+// FlashOverlay flashes the whole strip in a color the given number of times
+func FlashOverlay(c color.RGBA, flashes int) Overlay {
+	return OverlayFunc(func(strip peripheral.LedStrip, progress float64) {
+		// On for the first half of each flash period
+		if math.Mod(progress*float64(flashes), 1.0) < 0.5 {
+			strip.SetAll(c)
+		}
+	})
+}
+
+// ExplosionOverlay expands a fading ring of light outward from center
+func ExplosionOverlay(center int, c color.RGBA) Overlay {
+	return OverlayFunc(func(strip peripheral.LedStrip, progress float64) {
+		radius := progress * float64(strip.NumLEDs()) / 2
+		fade := 1 - progress
+		ringColor := colorutil.Scale(c, fade)
+
+		for i := 0; i < strip.NumLEDs(); i++ {
+			distance := math.Abs(float64(i - center))
+			if distance <= radius && distance >= radius-3 {
+				strip.SetPixel(i, ringColor)
+			}
+		}
+	})
+}
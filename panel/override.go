@@ -0,0 +1,99 @@
+package panel
+
+import (
+	"math/rand"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// inputOverride replaces an input's reading while set
+type inputOverride struct {
+	set     bool
+	pressed bool
+}
+
+// apply returns the override's reading when set, otherwise the input's own
+func (o inputOverride) apply(pressed bool) bool {
+	if o.set {
+		return o.pressed
+	}
+	return pressed
+}
+
+// SetInputOverride holds a battery's (0-based) connect input pressed or released,
+// whatever the real input reads, e.g. for staff testing installed hardware
+func (p *Panel) SetInputOverride(batteryIndex int, pressed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if batteryIndex >= 0 && batteryIndex < len(p.connectOverrides) {
+		p.connectOverrides[batteryIndex] = inputOverride{set: true, pressed: pressed}
+	}
+}
+
+// SetResetOverride holds the battery reset input pressed or released, whatever it reads
+func (p *Panel) SetResetOverride(pressed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resetOverride = inputOverride{set: true, pressed: pressed}
+}
+
+// ClearOverrides hands every overridden input back to its real reading
+func (p *Panel) ClearOverrides() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	clear(p.connectOverrides)
+	p.resetOverride = inputOverride{}
+}
+
+// InputOverride returns whether a battery's (0-based) connect input is
+// overridden, and the reading it is held at
+func (p *Panel) InputOverride(batteryIndex int) (pressed bool, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if batteryIndex < 0 || batteryIndex >= len(p.connectOverrides) {
+		return false, false
+	}
+	o := p.connectOverrides[batteryIndex]
+	return o.pressed, o.set
+}
+
+// demoInputs are the inputs a demo presses: the mock buttons when the panel
+// was built with them, otherwise the panel's input overrides
+type demoInputs struct {
+	panel    *Panel
+	connects []*peripheral.MockButton // nil when driving overrides
+	reset    *peripheral.MockButton   // nil when driving the reset override
+	count    int
+	rng      *rand.Rand // Picks the battery and reading of random steps
+}
+
+// setConnect presses or releases a battery's connect input
+func (d demoInputs) setConnect(batteryIndex int, pressed bool) {
+	if d.connects != nil {
+		d.connects[batteryIndex].SetPressed(pressed)
+		return
+	}
+	d.panel.SetInputOverride(batteryIndex, pressed)
+}
+
+// setReset presses or releases the battery reset input
+func (d demoInputs) setReset(pressed bool) {
+	if d.reset != nil {
+		d.reset.SetPressed(pressed)
+		return
+	}
+	d.panel.SetResetOverride(pressed)
+}
+
+// release lets go of everything the demo pressed
+func (d demoInputs) release() {
+	for _, button := range d.connects {
+		button.SetPressed(false)
+	}
+	if d.reset != nil {
+		d.reset.SetPressed(false)
+	}
+	if d.connects == nil || d.reset == nil {
+		d.panel.ClearOverrides()
+	}
+}
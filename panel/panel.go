@@ -2,44 +2,141 @@ package panel
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"image/color"
 	"math"
 	"math/rand"
 	"sync"
 	"time"
 
+	"github.com/christophergm/tinyspacewalk/airlock"
+	"github.com/christophergm/tinyspacewalk/audio"
 	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/logger"
+	"github.com/christophergm/tinyspacewalk/patterns"
 	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/recovery"
+	"github.com/christophergm/tinyspacewalk/render"
+	"github.com/christophergm/tinyspacewalk/timer"
 )
 
-// Common colors
+// Common colors, at full range: the strip's gamma correction and brightness set
+// how bright they show
 var (
 	Black  = color.RGBA{R: 0, G: 0, B: 0, A: 255}
-	Red    = color.RGBA{R: 5, G: 0, B: 0, A: 255}
-	Green  = color.RGBA{R: 0, G: 5, B: 0, A: 255}
-	Yellow = color.RGBA{R: 5, G: 5, B: 0, A: 255}
-	Blue   = color.RGBA{R: 0, G: 0, B: 5, A: 255}
-	White  = color.RGBA{R: 5, G: 5, B: 5, A: 255}
+	Red    = color.RGBA{R: 255, G: 0, B: 0, A: 255}
+	Green  = color.RGBA{R: 0, G: 255, B: 0, A: 255}
+	Yellow = color.RGBA{R: 255, G: 255, B: 0, A: 255}
+	Blue   = color.RGBA{R: 0, G: 0, B: 255, A: 255}
+	White  = color.RGBA{R: 255, G: 255, B: 255, A: 255}
 )
 
 // Panel manages the LED display and input handling for the battery system
 type Panel struct {
 	mu                 sync.RWMutex
 	batteries          []*battery.Battery
-	ledStrip           *peripheral.ColorLedStrip
+	bank               *battery.Bank
+	ownsBatteries      bool // Created from PanelConfig.BatteryConfigs
 	airLocktButton     peripheral.ButtonReader
+	abortSwitch        peripheral.ButtonReader
+	abortPressed       bool // Kill switch reading at the last tick, aborts on the press edge
+	aborted            bool // Latched until the battery reset input is pressed
 	batteryResetButton peripheral.ButtonReader
+	batteryResets      []peripheral.ButtonReader // Per-battery resets, entries may be nil
 	batteryConnects    []peripheral.ButtonReader
+	batteryChargers    []peripheral.ButtonReader // Per-battery charger docks, entries may be nil
+
+	// Readings forced by demos and remote commands over the real inputs
+	connectOverrides []inputOverride
+	resetOverride    inputOverride
+
+	// Airlock door, nil when the prop has none
+	airLock        *airlock.AirLock
+	airLockSegment Segment // LED section for the airlock, Strip is nil when it has none
+
+	// Mission clock, nil when not shown on the panel
+	timer        *timer.Timer
+	timerSegment Segment // LED section reserved for the clock
 
 	// LED allocation
-	batteryLEDCount int // LEDs per battery section
-	spacingLEDs     int // LEDs between batteries
-	ledOffset       int // Offset to skip obscured LEDs at the start
+	segments []Segment             // LED section for each battery
+	strips   []peripheral.LedStrip // Every strip the segments live on
+
+	// Time-sliced rendering
+	renderSlices int // Number of ticks over which all sections are redrawn, at most the number of sections
+	renderSlice  int // Slice to redraw on the next tick
+
+	// History playback, nil when rendering live state
+	replay *historyReplay
+
+	// End-of-game sequence owning every strip, nil when not playing
+	finale *finale
+
+	// One-shot effects drawn over the battery sections
+	overlays   []activeOverlay
+	fullRedraw bool // Redraw every section next tick, e.g. after an overlay ends
+
+	// Sound effects for battery events, nil when no buzzer is fitted
+	audio *audio.Player
+
+	// Hardware watchdog fed after every completed update, nil if not used
+	watchdog WatchdogFeeder
+
+	// Standby after idle, nil when disabled
+	power       *PowerManager
+	powerInputs []bool // Reused snapshot of the inputs for the power manager
+	updateRate  time.Duration
+
+	// Overall status display, nil when not fitted
+	statusIndicator StatusIndicator
+	errorUntil      time.Time // Error status is shown until then
+	faults          <-chan peripheral.Fault
+	failing         map[string]error // Peripherals reported failing and not yet recovered, by source
+	demo            bool             // A demo sequence is driving the inputs
+
+	// Difficulty knob scaling every battery's drain rate, nil when not fitted
+	difficultyKnob peripheral.AnalogReader
+	difficulty     int  // Current difficulty percentage, 50 is the configured rate
+	difficultyRead bool // The knob has been read at least once
+
+	// Noise-driven bonus charge, nil when no input is configured
+	boost *chargeBoost
+
+	// Crossfade of a section when its battery changes state, 0 snaps, unless a
+	// transition is configured for the new state
+	stateFade   time.Duration
+	transitions Transitions           // By the state entered
+	playing     []*activeTransition   // Running transition of each section, nil if none
+	lastStates  []battery.SystemState // State each section was last drawn in
+	drawn       []bool                // Whether each section has been drawn yet
+
+	// Auxiliary lights and needle gauges tracking the system state
+	auxLights []*auxLight
+	gauges    []*gauge
+	auxInfos  []battery.BatteryInfo // Reused snapshot of the battery info for the light and gauge rules
 
 	// Animation state
 	animationTicker *time.Ticker
 	stopAnimation   chan struct{}
 	running         bool
+	paused          bool // Inputs and drawing are frozen, the LEDs keep their last frame
+	selfTesting     bool // SelfTest owns the strips and status indicator
+
+	// Statistics
+	startedAt  time.Time
+	frameCount uint32      // Frames drawn since the panel started
+	tickCount  uint32      // Update loop ticks completed, including paused ones that draw nothing
+	timing     frameTiming // Frame times, resettable
+	log        *logger.Logger
+
+	// Colors and animation speeds
+	theme Theme
+
+	// Random source for the flicker animations, only used under the mutex
+	seed int64
+	rng  *rand.Rand
 
 	// Flash/pulse timing
 	flashPhase float64 // 0.0 to 1.0 for flash animations
@@ -58,44 +155,146 @@ type Panel struct {
 
 // PanelConfig holds configuration for panel creation
 type PanelConfig struct {
-	Batteries          []*battery.Battery
-	LEDStrip           *peripheral.ColorLedStrip
-	AirLockButton      peripheral.ButtonReader
-	BatteryResetButton peripheral.ButtonReader
-	BatteryConnects    []peripheral.ButtonReader
-	UpdateRate         time.Duration // How often to update animations and check inputs
+	Batteries           []*battery.Battery
+	BatteryConfigs      []battery.Config    // Per-slot configs used to create the batteries when Batteries is empty
+	Bank                *battery.Bank       // Optional bank over Batteries, created when nil
+	LEDStrip            peripheral.LedStrip // Single strip carved into equal sections (or by Layout), used when Segments is empty
+	Segments            []Segment           // Explicit LED section per battery, possibly across several strips
+	Layout              SectionLayout       // Explicit LED section per battery on LEDStrip, used when Segments is empty
+	AirLockButton       peripheral.ButtonReader
+	AirLock             *airlock.AirLock        // Optional airlock driven by AirLockButton and battery power
+	AbortSwitch         peripheral.ButtonReader // Optional master kill switch, forces every battery Dead until reset
+	AirLockSegment      Segment                 // Explicit LED section for the airlock
+	AirLockLEDs         int                     // With no explicit segments or layout, LEDs reserved at the end of LEDStrip for the airlock
+	Timer               *timer.Timer            // Optional mission clock drawn on TimerSegment
+	TimerSegment        Segment                 // LED section reserved for the mission clock, e.g. a ring on its own strip
+	BatteryResetButton  peripheral.ButtonReader
+	BatteryResetButtons []peripheral.ButtonReader // Optional reset per battery, alongside BatteryResetButton which resets all
+	BatteryConnects     []peripheral.ButtonReader
+	BatteryChargers     []peripheral.ButtonReader // Optional charger dock per battery, for batteries with Config.ChargerInput, e.g. a peripheral.MagneticSwitch sensing a seated battery
+	InputMap            []InputMapping            // Optional physical input to role wiring, replacing the inputs above for the roles it maps
+	UpdateRate          time.Duration             // How often to update animations and check inputs
+	RenderSlices        int                       // Spread section redraws over this many ticks for long strips (0 or 1 redraws all every tick); slicing is by section, so at most one per battery section
+	Buzzer              peripheral.ToneGenerator  // Optional buzzer for battery event alarms
+	Audio               audio.Config              // Volume, enable, and sequences for the buzzer
+	Watchdog            WatchdogFeeder            // Optional watchdog fed by the update loop, e.g. machine.Watchdog
+	Power               PowerConfig               // Optional standby after idle, disabled when IdleTimeout is 0
+	StatusIndicator     StatusIndicator           // Optional overall status display, e.g. NewNeoPixelStatus
+	AuxLights           []AuxLightConfig          // Optional dimmable lights that track the system state
+	Gauges              []GaugeConfig             // Optional needle gauges, e.g. a servo showing the bank's charge
+	StateFade           time.Duration             // Crossfade a section over this long when its battery changes state, 0 snaps
+	Transitions         Transitions               // Optional animation played over a section as its battery enters a state, e.g. DefaultTransitions, instead of the crossfade
+	Theme               Theme                     // Colors and animation speeds, DefaultTheme if zero
+	RandomSeed          int64                     // Seeds the flicker animations and random demos so recordings are repeatable, 0 seeds from the clock
+	DifficultyKnob      peripheral.AnalogReader   // Optional potentiometer scaling every battery's drain rate while the game runs
+	ChargeBoost         ChargeBoostConfig         // Optional bonus charge while players make noise, disabled when Input is nil
+	Faults              <-chan peripheral.Fault   // Optional peripheral failures after startup, shown as the error status while they last
+	Logger              *logger.Logger            // Optional logger warned when the update rate can't be sustained
+	Context             context.Context           // Optional parent context, cancelling it stops the panel
+}
+
+// WatchdogFeeder is a started hardware watchdog; machine.Watchdog satisfies it
+type WatchdogFeeder interface {
+	Update()
 }
 
+// ErrBatteryCount is returned by NewPanel when the battery sections or connect
+// inputs don't match the number of batteries
+var ErrBatteryCount = errors.New("panel: sections and connect inputs must match the batteries")
+
 // NewPanel creates a new panel instance
-func NewPanel(config PanelConfig) *Panel {
+func NewPanel(config PanelConfig) (*Panel, error) {
 	if config.UpdateRate <= 0 {
 		config.UpdateRate = 50 * time.Millisecond // 20 FPS default
 	}
 
-	// Calculate LED allocation - skip first 6 and last 6 LEDs
-	totalLEDs := config.LEDStrip.NumLEDs()
-	obscuredLEDs := 12 // 6 at top + 6 at bottom
-	usableLEDs := totalLEDs - obscuredLEDs
-	ledOffset := 6 // Skip first 6 LEDs
+	// The panel owns batteries it creates and stops them with itself
+	ownsBatteries := false
+	if len(config.Batteries) == 0 && len(config.BatteryConfigs) > 0 {
+		config.Batteries = battery.NewBatteries(config.BatteryConfigs)
+		ownsBatteries = true
+	}
+
+	if config.Bank == nil {
+		config.Bank = battery.NewBank(config.Batteries)
+	}
+
+	config.applyInputMap(len(config.Batteries))
+
+	segments := config.Segments
+	airLockSegment := config.AirLockSegment
+	if len(segments) == 0 && len(config.Layout) > 0 {
+		segments = config.Layout.Segments(config.LEDStrip)
+	}
+	if len(segments) == 0 {
+		var reserved Segment
+		segments, reserved = defaultSegments(config.LEDStrip, len(config.Batteries), config.AirLockLEDs)
+		if airLockSegment.Strip == nil {
+			airLockSegment = reserved
+		}
+	}
+	allSegments := segments
+	if airLockSegment.Strip != nil {
+		allSegments = append(append([]Segment(nil), segments...), airLockSegment)
+	}
+	if config.Timer != nil && config.TimerSegment.Strip != nil {
+		allSegments = append(append([]Segment(nil), allSegments...), config.TimerSegment)
+	}
 
-	numBatteries := len(config.Batteries)
-	spacingLEDs := 4
-	totalSpacing := spacingLEDs * (numBatteries - 1)
-	batteryLEDs := (usableLEDs - totalSpacing) / numBatteries
+	// Every battery needs a section to draw in and a connect input to read
+	if n := len(config.Batteries); len(segments) != n || len(config.BatteryConnects) != n {
+		if ownsBatteries {
+			for _, bat := range config.Batteries {
+				bat.Stop()
+			}
+		}
+		return nil, fmt.Errorf("%w: %d batteries, %d sections, %d connect inputs",
+			ErrBatteryCount, n, len(segments), len(config.BatteryConnects))
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	parent := config.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
 
 	p := &Panel{
 		batteries:          config.Batteries,
-		ledStrip:           config.LEDStrip,
+		bank:               config.Bank,
+		ownsBatteries:      ownsBatteries,
 		batteryResetButton: config.BatteryResetButton,
+		batteryResets:      config.BatteryResetButtons,
 		batteryConnects:    config.BatteryConnects,
+		batteryChargers:    config.BatteryChargers,
+		connectOverrides:   make([]inputOverride, len(config.Batteries)),
 		airLocktButton:     config.AirLockButton,
-		batteryLEDCount:    batteryLEDs,
-		spacingLEDs:        spacingLEDs,
-		ledOffset:          ledOffset,
+		abortSwitch:        config.AbortSwitch,
+		airLock:            config.AirLock,
+		airLockSegment:     airLockSegment,
+		timer:              config.Timer,
+		timerSegment:       config.TimerSegment,
+		segments:           segments,
+		strips:             uniqueStrips(allSegments),
+		renderSlices:       min(config.RenderSlices, len(segments)),
+		watchdog:           config.Watchdog,
+		updateRate:         config.UpdateRate,
+		statusIndicator:    config.StatusIndicator,
+		faults:             config.Faults,
+		failing:            make(map[string]error),
+		stateFade:          config.StateFade,
+		transitions:        config.Transitions,
+		playing:            make([]*activeTransition, len(config.Batteries)),
+		theme:              config.Theme.withDefaults().capped(),
+		seed:               config.RandomSeed,
+		rng:                patterns.NewRand(config.RandomSeed),
+		difficultyKnob:     config.DifficultyKnob,
+		difficulty:         50,
+		lastStates:         make([]battery.SystemState, len(config.Batteries)),
+		drawn:              make([]bool, len(config.Batteries)),
+		log:                config.Logger,
 		stopAnimation:      make(chan struct{}),
 		lastUpdate:         time.Now(),
+		startedAt:          time.Now(),
 		ctx:                ctx,
 		cancel:             cancel,
 		// Initialize pre-allocated color structs
@@ -104,8 +303,28 @@ func NewPanel(config PanelConfig) *Panel {
 		unknownColor: color.RGBA{A: 255},
 	}
 
+	if config.Power.IdleTimeout > 0 {
+		p.power = NewPowerManager(config.Power)
+	}
+
+	if config.ChargeBoost.Input != nil {
+		p.boost = &chargeBoost{config: config.ChargeBoost}
+	}
+
+	if config.Buzzer != nil {
+		p.audio = audio.NewPlayer(config.Buzzer, config.Audio)
+		p.audio.Watch(config.Batteries)
+	}
+
+	for _, light := range config.AuxLights {
+		p.AddAuxLight(light)
+	}
+	for _, g := range config.Gauges {
+		p.AddGauge(g)
+	}
+
 	p.start(config.UpdateRate)
-	return p
+	return p, nil
 }
 
 // Start begins the panel's update loop
@@ -130,17 +349,31 @@ func (p *Panel) start(updateRate time.Duration) {
 		for {
 			select {
 			case <-p.animationTicker.C:
-				p.update()
+				// A panicking update is reported and retried on the next tick;
+				// the watchdog is only fed when an update completes, so a hung
+				// SPI write or repeated crash resets the board
+				if err := recovery.Call("panel update", p.update); err != nil {
+					p.ReportError()
+				} else {
+					p.countTick()
+					if p.watchdog != nil {
+						p.watchdog.Update()
+					}
+				}
+			case fault := <-p.faults:
+				p.handleFault(fault)
 			case <-p.stopAnimation:
 				return
 			case <-p.ctx.Done():
+				// Cancelled from outside, clean up as if Stop was called
+				p.Stop()
 				return
 			}
 		}
 	}()
 }
 
-// Stop stops the panel's update loop
+// Stop stops the panel's update loop and cancels its context, ending any demos
 func (p *Panel) Stop() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -152,102 +385,264 @@ func (p *Panel) Stop() {
 			p.animationTicker.Stop()
 		}
 		p.running = false
-		p.ledStrip.Clear()
-		p.ledStrip.Show()
+		if p.audio != nil {
+			p.audio.Stop()
+		}
+		for _, strip := range p.strips {
+			strip.Clear()
+			strip.Show()
+		}
+		if p.ownsBatteries {
+			for _, bat := range p.batteries {
+				bat.Stop()
+			}
+		}
 	}
 }
 
+// isPressed reads an input, also counting a press latched by its interrupt
+// since the last tick so quick presses between updates aren't missed
+func isPressed(input peripheral.ButtonReader) bool {
+	latched := peripheral.DrainPresses(input)
+	return input.IsPressed() || latched
+}
+
+// batteryResetPressed reports whether a battery's own reset input is pressed
+func (p *Panel) batteryResetPressed(batteryIndex int) bool {
+	if batteryIndex >= len(p.batteryResets) || p.batteryResets[batteryIndex] == nil {
+		return false
+	}
+	return isPressed(p.batteryResets[batteryIndex])
+}
+
+// batteryChargerPressed returns whether a battery (0-based) is on its charger
+func (p *Panel) batteryChargerPressed(batteryIndex int) bool {
+	if batteryIndex >= len(p.batteryChargers) || p.batteryChargers[batteryIndex] == nil {
+		return false
+	}
+	return isPressed(p.batteryChargers[batteryIndex])
+}
+
 // update handles input checking, animation updates, and LED display
 func (p *Panel) update() {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if p.finale != nil && !p.selfTesting {
+		p.updateFinale(time.Now())
+		if p.finale != nil {
+			return
+		}
+	}
+
+	if p.paused {
+		if !p.selfTesting {
+			p.showStatus(time.Now())
+		}
+		return
+	}
+
 	now := time.Now()
+	defer p.recordFrame(now)
 	deltaTime := now.Sub(p.lastUpdate).Seconds()
 	p.lastUpdate = now
 
 	// Check inputs and update all batteries
+	resetAll := p.resetOverride.apply(isPressed(p.batteryResetButton))
+	p.updateAbort(now, resetAll)
+	p.powerInputs = append(p.powerInputs[:0], resetAll, p.abortPressed)
 	for i, bat := range p.batteries {
-		bat.SetChargedOverride(p.batteryResetButton.IsPressed())
-		bat.SetIsDraining(p.batteryConnects[i].IsPressed())
+		reset := p.batteryResetPressed(i)
+		draining := p.connectOverrides[i].apply(isPressed(p.batteryConnects[i]))
+		charging := p.batteryChargerPressed(i)
+		bat.SetChargedOverride(resetAll || reset)
+		bat.SetIsDraining(draining)
+		bat.SetIsCharging(charging)
+		p.powerInputs = append(p.powerInputs, reset, draining, charging)
+	}
+	p.powerInputs = append(p.powerInputs, p.updateAirLockInputs())
+	p.updateDifficulty()
+	p.updateChargeBoost(now, deltaTime)
+
+	// Update animation phases, at the simulation's pace
+	p.updateAnimationPhases(deltaTime * battery.TimeScale())
+	p.showStatus(now)
+	p.updateAuxLights(now)
+	p.updateGauges(now)
+
+	// Drop to a slow dim standby when idle, returning to full rate on any input
+	if p.power != nil {
+		if p.power.Observe(p.powerInputs, p.batteriesBusy(), now) {
+			p.fullRedraw = true
+			if p.power.IsStandby() {
+				p.animationTicker.Reset(p.power.config.StandbyRate)
+			} else {
+				p.animationTicker.Reset(p.updateRate)
+			}
+		}
+		if p.power.IsStandby() {
+			p.renderStandby()
+			for _, strip := range p.strips {
+				strip.ShowIfDirty()
+			}
+			return
+		}
 	}
 
-	// Update animation phases
-	p.updateAnimationPhases(deltaTime)
+	// Overlays and transitions animate every tick, so time slicing is suspended while they play
+	sliced := p.renderSlices > 1 && len(p.overlays) == 0 && !p.transitionsPlaying() && !p.fullRedraw
+	p.fullRedraw = false
+
+	// Work out the replayed time when a history playback is running
+	var replayAt time.Time
+	if p.replay != nil {
+		var ok bool
+		if replayAt, ok = p.replay.replayTime(now); !ok {
+			p.replay = nil
+		}
+	}
 
 	// Clear the strip first
-	p.ledStrip.SetAll(Black)
+	if !sliced {
+		for _, strip := range p.strips {
+			strip.SetAll(Black)
+		}
+	}
 
 	// Update LED display for each battery
 	for i, bat := range p.batteries {
+		// In time-sliced mode only every renderSlices-th section is redrawn per tick
+		if sliced && i%p.renderSlices != p.renderSlice {
+			continue
+		}
+
 		info := bat.GetInfo()
+		if p.replay != nil {
+			info = p.replay.infoAt(i, replayAt)
+		}
+		if sliced {
+			p.clearBatterySection(i)
+		}
 		p.updateBatterySection(i, info)
+		p.showStateChange(i, info.State, now)
+		p.renderTransition(i, now)
+	}
+	if sliced {
+		p.renderSlice = (p.renderSlice + 1) % p.renderSlices
 	}
 
-	// Show the updated display
-	p.ledStrip.Show()
+	// The airlock section is small, so it is redrawn every tick
+	p.renderAirLock()
+	p.renderTimer()
+
+	// Composite one-shot effects on top
+	p.renderOverlays(now)
+
+	// Show the updated display, skipping strips whose frame hasn't changed
+	for _, strip := range p.strips {
+		strip.ShowIfDirty()
+	}
 }
 
 // updateAnimationPhases updates the timing for flash and pulse animations
 func (p *Panel) updateAnimationPhases(deltaTime float64) {
 	// Use math.Mod to prevent accumulation of floating point errors
-	p.flashPhase = math.Mod(p.flashPhase+deltaTime, 1.0)
-	p.pulsePhase = math.Mod(p.pulsePhase+deltaTime*0.5, 1.0)
+	p.flashPhase = math.Mod(p.flashPhase+deltaTime/p.theme.FlashPeriod.Seconds(), 1.0)
+	p.pulsePhase = math.Mod(p.pulsePhase+deltaTime/p.theme.PulsePeriod.Seconds(), 1.0)
 }
 
-// getBatteryStartLED returns the starting LED index for a battery section
-func (p *Panel) getBatteryStartLED(batteryIndex int) int {
-	return p.ledOffset + batteryIndex*(p.batteryLEDCount+p.spacingLEDs)
+// showStateChange starts the transition for the new state when a section's battery
+// changed state, or crossfades the section from its last frame to the one just drawn
+func (p *Panel) showStateChange(batteryIndex int, state battery.SystemState, now time.Time) {
+	changed := p.drawn[batteryIndex] && state != p.lastStates[batteryIndex]
+	p.lastStates[batteryIndex] = state
+	p.drawn[batteryIndex] = true
+	if !changed || p.startTransition(batteryIndex, state, now) || p.stateFade <= 0 {
+		return
+	}
+
+	seg := p.segments[batteryIndex]
+	fader, ok := seg.Strip.(peripheral.PixelFader)
+	if !ok {
+		return
+	}
+	for i := seg.Start; i < seg.Start+seg.Length; i++ {
+		fader.FadePixel(i, seg.Strip.GetPixel(i), p.stateFade)
+	}
+}
+
+// clearBatterySection turns off all LEDs in a battery section
+func (p *Panel) clearBatterySection(batteryIndex int) {
+	p.segments[batteryIndex].fill(Black)
 }
 
 // updateBatterySection updates the LED section for a specific battery
 func (p *Panel) updateBatterySection(batteryIndex int, info battery.BatteryInfo) {
-	startLED := p.getBatteryStartLED(batteryIndex)
+	seg := p.segments[batteryIndex]
 
 	switch info.State {
 	case battery.Charged:
-		p.displayChargedSection(startLED)
+		p.displayChargedSection(seg, info.Health)
 	case battery.Disconnecting:
-		p.displayDisconnectingSection(startLED, info.BatteryLevel)
+		p.displayDisconnectingSection(seg, info)
 	case battery.Draining:
-		p.displayDrainingSection(startLED, info.BatteryLevel)
+		p.displayDrainingSection(seg, info.BatteryLevel)
 	case battery.Dead:
-		p.displayDeadSection(startLED)
+		p.displayDeadSection(seg)
 	case battery.Charging:
-		p.displayChargingSection(startLED, info.BatteryLevel)
+		p.displayChargingSection(seg, info.BatteryLevel)
+	case battery.Idle:
+		p.displayIdleSection(seg, info.BatteryLevel)
+	case battery.Overheated:
+		p.displayOverheatedSection(seg, info)
 	default:
-		p.displayUnknownSection(startLED)
+		p.displayUnknownSection(seg)
+	}
+	p.applyCadence(seg, info.State)
+}
+
+// displayChargedSection shows a full battery section, dimmer for a worn battery
+func (p *Panel) displayChargedSection(seg Segment, health float32) {
+	// Infos without wear data, e.g. from a replay, show as healthy
+	if health <= 0 {
+		health = 1
 	}
+
+	// Pulse once per flash period with a subtle pulse from 100% to 80%
+	maxBrightness := float64(p.theme.ChargedBrightness) * float64(health)
+	pulseBrightness := uint8(maxBrightness * (0.9 + 0.1*math.Sin(p.flashPhase*2*math.Pi)))
+
+	p.pulseColor = colorutil.WithPeak(p.theme.Charged, pulseBrightness)
+	seg.fill(p.pulseColor)
 }
 
-// displayChargedSection shows green LEDs for a battery section
-func (p *Panel) displayChargedSection(startLED int) {
-	// Pulse the green with 1 second period
-	// with a subtle pulse from 100% to 80%
-	maxBrightness := uint8(40)
-	pulseBrightness := uint8(float64(maxBrightness) * (0.9 + 0.1*math.Sin(p.flashPhase*2*math.Pi)))
+// displayDisconnectingSection counts down to draining: the bar sweeps from full
+// to empty over the time left in Disconnecting, with a flickering edge
+func (p *Panel) displayDisconnectingSection(seg Segment, info battery.BatteryInfo) {
+	// Infos without timing, e.g. from a replay, just flicker
+	if info.DisconnectingDuration <= 0 {
+		p.displayDisconnectingFlicker(seg, info.BatteryLevel)
+		return
+	}
 
-	// Reuse pre-allocated color struct
-	p.pulseColor.R = 0
-	p.pulseColor.G = pulseBrightness
-	p.pulseColor.B = 0
-	// A is already set to 255 in initialization
+	remaining := float64(info.DisconnectingDurationRemaining) / float64(info.DisconnectingDuration)
 
-	for i := 0; i < p.batteryLEDCount; i++ {
-		p.ledStrip.SetPixel(startLED+i, p.pulseColor)
+	seg.fill(Black)
+	pixelsLit, fraction := render.Bar(seg, remaining*100, p.theme.Countdown)
+	if pixelsLit < seg.Length {
+		// The edge burns, flickering faster as time runs out
+		edge := p.theme.CountdownEdge
+		if p.rng.Float64() < 0.5*(1-remaining) {
+			edge = Black
+		}
+		seg.setPixel(pixelsLit, colorutil.Scale(edge, 0.5+0.5*fraction))
 	}
 }
 
-// displayDisconnectingSection shows green flickering out with random pixels turning yellow or off
-func (p *Panel) displayDisconnectingSection(startLED int, batteryLevel float32) {
+// displayDisconnectingFlicker shows the countdown color flickering out with random pixels turning to the edge color or off
+func (p *Panel) displayDisconnectingFlicker(seg Segment, batteryLevel float32) {
 	// Calculate how many pixels should be affected based on battery level
-	pixelsAffected := int(math.Ceil(float64(p.batteryLEDCount) * float64(batteryLevel) / 100.0))
-	if pixelsAffected < 0 {
-		pixelsAffected = 0
-	}
-	if pixelsAffected > p.batteryLEDCount {
-		pixelsAffected = p.batteryLEDCount
-	}
+	pixelsAffected := render.Covered(seg.Length, float64(batteryLevel))
 
 	// Use flash phase to control the amount of flickering (more flickering over time)
 	flickerIntensity := p.flashPhase // 0.0 to 1.0
@@ -255,105 +650,90 @@ func (p *Panel) displayDisconnectingSection(startLED int, batteryLevel float32)
 	// Only flicker LEDs up to the battery level
 	for i := 0; i < pixelsAffected; i++ {
 		// Random chance for each pixel to flicker based on intensity
-		if rand.Float64() < flickerIntensity*0.5 {
-			// Randomly choose between yellow or off
-			if rand.Float64() < 0.6 {
-				p.ledStrip.SetPixel(startLED+i, Yellow)
+		if p.rng.Float64() < flickerIntensity*0.5 {
+			// Randomly choose between the edge color or off
+			if p.rng.Float64() < 0.6 {
+				seg.setPixel(i, p.theme.CountdownEdge)
 			} else {
-				p.ledStrip.SetPixel(startLED+i, Black)
+				seg.setPixel(i, Black)
 			}
 		} else {
-			// Default to green when not flickering
-			p.ledStrip.SetPixel(startLED+i, Green)
+			// Default to the countdown color when not flickering
+			seg.setPixel(i, p.theme.Countdown)
 		}
 	}
 }
 
-// displayDrainingSection shows yellow bar getting smaller with pixels incrementally flickering out
-func (p *Panel) displayDrainingSection(startLED int, batteryLevel float32) {
-	// Calculate how many pixels should be solidly lit based on battery level
-	pixelsLit := int(math.Ceil(float64(p.batteryLEDCount) * float64(batteryLevel) / 100.0))
-	if pixelsLit < 0 {
-		pixelsLit = 0
-	}
-	if pixelsLit > p.batteryLEDCount {
-		pixelsLit = p.batteryLEDCount
-	}
-
-	// Light up the solid yellow bar
-	for i := 0; i < pixelsLit; i++ {
-		p.ledStrip.SetPixel(startLED+i, Yellow)
-	}
+// displayDrainingSection shows a bar getting smaller with pixels incrementally flickering
+// out, shading from green through yellow to red as the level falls
+func (p *Panel) displayDrainingSection(seg Segment, batteryLevel float32) {
+	// Light up the solid bar, with the tip dimmed by the fractional level
+	barColor := p.theme.drainingColor(batteryLevel)
+	pixelsLit, _ := render.Bar(seg, float64(batteryLevel), barColor)
 
 	// Add flickering effect at the edge of the bar to simulate pixels dying
 	flickerZone := 2 // Number of pixels at the edge that can flicker
-	for i := pixelsLit; i < pixelsLit+flickerZone && i < p.batteryLEDCount; i++ {
-		// Random chance for edge pixels to flicker yellow
-		if rand.Float64() < 0.3 {
-			p.ledStrip.SetPixel(startLED+i, Yellow)
+	for i := pixelsLit + 1; i < pixelsLit+1+flickerZone && i < seg.Length; i++ {
+		// Random chance for edge pixels to flicker on
+		if p.rng.Float64() < 0.3 {
+			seg.setPixel(i, barColor)
 		}
 	}
 }
 
-// displayDeadSection shows pulsing red with variable intensity for a battery section
-func (p *Panel) displayDeadSection(startLED int) {
-	// Pulse the red with 1 second period (same as draining)
-	maxBrightness := uint8(10)
-	pulseBrightness := uint8(float64(maxBrightness) * (0.5 + 0.5*math.Sin(p.flashPhase*2*math.Pi)))
+// displayDeadSection shows a pulse with variable intensity for a battery section
+func (p *Panel) displayDeadSection(seg Segment) {
+	// Pulse once per flash period (same as draining)
+	pulseBrightness := uint8(float64(p.theme.DeadBrightness) * (0.5 + 0.5*math.Sin(p.flashPhase*2*math.Pi)))
 
-	// Reuse pre-allocated color struct
-	p.pulseColor.R = pulseBrightness
-	p.pulseColor.G = 0
-	p.pulseColor.B = 0
-	// A is already set to 255 in initialization
-
-	for i := 0; i < p.batteryLEDCount; i++ {
-		p.ledStrip.SetPixel(startLED+i, p.pulseColor)
-	}
+	p.pulseColor = colorutil.WithPeak(p.theme.Dead, pulseBrightness)
+	seg.fill(p.pulseColor)
 }
 
 // displayChargingSection shows a charging animation for a battery section
-func (p *Panel) displayChargingSection(startLED int, batteryLevel float32) {
-	// Show current charge level in green
-	pixelsLit := int(math.Ceil(float64(p.batteryLEDCount) * float64(batteryLevel) / 100.0))
-	if pixelsLit < 0 {
-		pixelsLit = 0
-	}
-	if pixelsLit > p.batteryLEDCount {
-		pixelsLit = p.batteryLEDCount
-	}
-
-	for i := 0; i < pixelsLit; i++ {
-		p.ledStrip.SetPixel(startLED+i, Green)
+func (p *Panel) displayChargingSection(seg Segment, batteryLevel float32) {
+	// Show current charge level, with the tip dimmed by the fractional level
+	pixelsLit, _ := render.Bar(seg, float64(batteryLevel), p.theme.Charging)
+	if pixelsLit < seg.Length {
+		pixelsLit++
 	}
 
 	// Add a moving "charging" indicator
-	if pixelsLit < p.batteryLEDCount {
-		// Create a yellow pulse that moves up the strip
-		chargePos := int(p.flashPhase * float64(p.batteryLEDCount-pixelsLit))
+	if pixelsLit < seg.Length {
+		// Create a pulse that moves up the strip
+		chargePos := int(p.flashPhase * float64(seg.Length-pixelsLit))
 		if chargePos < 0 {
 			chargePos = 0
 		}
-		if chargePos+pixelsLit < p.batteryLEDCount {
-			p.ledStrip.SetPixel(startLED+pixelsLit+chargePos, Yellow)
+		if chargePos+pixelsLit < seg.Length {
+			seg.setPixel(pixelsLit+chargePos, p.theme.ChargingIndicator)
 		}
 	}
 }
 
-// displayUnknownSection shows a blue pattern to indicate unknown state for a battery section
-func (p *Panel) displayUnknownSection(startLED int) {
-	// Slow pulse in blue to indicate unknown/error state
-	brightness := uint8(64 + 64*math.Sin(p.pulsePhase*2*math.Pi))
+// displayIdleSection shows the held level as a steady dim bar, for a battery
+// that is unplugged but not on its charger
+func (p *Panel) displayIdleSection(seg Segment, batteryLevel float32) {
+	render.Bar(seg, float64(batteryLevel), p.theme.Idle)
+}
 
-	// Reuse pre-allocated color struct
-	p.unknownColor.R = 0
-	p.unknownColor.G = 0
-	p.unknownColor.B = brightness
-	// A is already set to 255 in initialization
+// displayOverheatedSection shows the held level as a bar that throbs deeper the
+// hotter the battery is, settling as it cools towards the point it can be used again
+func (p *Panel) displayOverheatedSection(seg Segment, info battery.BatteryInfo) {
+	throb := 0.5 * float64(info.Heat) * (0.5 + 0.5*math.Sin(p.flashPhase*2*math.Pi))
+	brightness := uint8(float64(p.theme.OverheatedBrightness) * (1 - throb))
 
-	for i := 0; i < p.batteryLEDCount; i++ {
-		p.ledStrip.SetPixel(startLED+i, p.unknownColor)
-	}
+	seg.fill(Black)
+	render.Bar(seg, float64(info.BatteryLevel), colorutil.WithPeak(p.theme.Overheated, brightness))
+}
+
+// displayUnknownSection shows a slow pulse to indicate unknown state for a battery section
+func (p *Panel) displayUnknownSection(seg Segment) {
+	// Slow pulse to indicate unknown/error state
+	brightness := uint8(float64(p.theme.UnknownBrightness) * (0.5 + 0.5*math.Sin(p.pulsePhase*2*math.Pi)))
+
+	p.unknownColor = colorutil.WithPeak(p.theme.Unknown, brightness)
+	seg.fill(p.unknownColor)
 }
 
 // GetBatteryInfo returns current battery information for a specific battery
@@ -380,7 +760,125 @@ func (p *Panel) GetAllBatteryInfo() []battery.BatteryInfo {
 	return infos
 }
 
+// SectionColors returns the average color currently drawn on each battery's section,
+// in battery order, e.g. to mirror the panel onto stage lighting
+func (p *Panel) SectionColors() []color.RGBA {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	colors := make([]color.RGBA, len(p.segments))
+	for i, seg := range p.segments {
+		colors[i] = seg.average()
+	}
+	return colors
+}
+
+// Pause freezes input handling and animation while leaving the LEDs lit with
+// their last frame. The update loop keeps running (and feeding any watchdog),
+// and the strip may be used by something else until Resume.
+func (p *Panel) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+}
+
+// Resume restarts input handling and animation after Pause
+func (p *Panel) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.paused {
+		return
+	}
+	p.paused = false
+
+	// Don't jump the animations by the time spent paused, and repaint
+	// everything in case the strip was drawn on meanwhile
+	p.lastUpdate = time.Now()
+	p.fullRedraw = true
+}
+
+// Uptime returns how long the panel has been running
+func (p *Panel) Uptime() time.Duration {
+	return time.Since(p.startedAt)
+}
+
+// FrameCount returns the number of frames drawn since the panel started
+func (p *Panel) FrameCount() uint32 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.frameCount
+}
+
+// TickCount returns the number of update loop ticks completed since the panel
+// started. Unlike FrameCount it keeps rising while the panel is paused or a
+// finale plays, so it shows the loop itself is alive.
+func (p *Panel) TickCount() uint32 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.tickCount
+}
+
+// countTick records a completed update loop tick
+func (p *Panel) countTick() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tickCount++
+}
+
+// IsPaused returns whether the panel is paused
+func (p *Panel) IsPaused() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.paused
+}
+
+// PauseAll freezes every battery's level and timers; the panel keeps rendering them
+func (p *Panel) PauseAll() {
+	for _, bat := range p.Batteries() {
+		bat.Pause()
+	}
+}
+
+// ResumeAll continues every battery after PauseAll
+func (p *Panel) ResumeAll() {
+	for _, bat := range p.Batteries() {
+		bat.Resume()
+	}
+}
+
+// Batteries returns the panel's batteries, including any it created from BatteryConfigs
+func (p *Panel) Batteries() []*battery.Battery {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.batteries
+}
+
+// Bank returns the bank grouping the panel's batteries
+func (p *Panel) Bank() *battery.Bank {
+	return p.bank
+}
+
 // GetContext returns the panel's context for coordinating shutdown
 func (p *Panel) GetContext() context.Context {
 	return p.ctx
 }
+
+// Done returns a channel that is closed once the panel has been stopped
+func (p *Panel) Done() <-chan struct{} {
+	return p.ctx.Done()
+}
+
+// SetAudioEnabled turns the buzzer alarms on or off
+func (p *Panel) SetAudioEnabled(enabled bool) {
+	if p.audio != nil {
+		p.audio.SetEnabled(enabled)
+	}
+}
+
+// SetVolume sets the buzzer alarm volume (0-100)
+func (p *Panel) SetVolume(volume uint8) {
+	if p.audio != nil {
+		p.audio.SetVolume(volume)
+	}
+}
@@ -0,0 +1,415 @@
+package panel
+
+import (
+	"errors"
+	"image/color"
+	"testing"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/telemetry"
+)
+
+// Test layout: three sections of 10 LEDs with 2 dark LEDs between them
+const (
+	testBatteries  = 3
+	testSectionLen = 10
+	testSectionGap = 2
+)
+
+// testPanel is a panel on a mock strip whose batteries only move when told to
+type testPanel struct {
+	*Panel
+	strip     *peripheral.MockStrip
+	clock     *battery.FakeClock
+	batteries []*battery.Battery
+}
+
+// newTestPanel creates a panel on a mock strip with explicit sections. Its update
+// loop never ticks on its own, so frames are only drawn by render.
+func newTestPanel(t *testing.T) *testPanel {
+	t.Helper()
+	return newTestPanelAt(t, time.Hour)
+}
+
+// newTestPanelAt creates a test panel whose update loop ticks at updateRate
+func newTestPanelAt(t *testing.T, updateRate time.Duration) *testPanel {
+	t.Helper()
+	clock := battery.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	configs := make([]battery.Config, testBatteries)
+	for i := range configs {
+		configs[i] = battery.Config{
+			DrainRate:             100 * time.Second,
+			ChargeRate:            100 * time.Second,
+			DisconnectingDuration: 10 * time.Second,
+			Clock:                 clock,
+		}
+	}
+	batteries := battery.NewBatteries(configs)
+
+	// Frames are checked against the colors the panel draws, before gamma correction
+	strip := peripheral.NewMockStrip(testBatteries*(testSectionLen+testSectionGap) - testSectionGap)
+	strip.SetGammaCorrection(false)
+	segments := make([]Segment, testBatteries)
+	connects := make([]peripheral.ButtonReader, testBatteries)
+	for i := range segments {
+		segments[i] = Segment{Strip: strip, Start: i * (testSectionLen + testSectionGap), Length: testSectionLen}
+		connects[i] = peripheral.NewMockButton()
+	}
+
+	p, err := NewPanel(PanelConfig{
+		Batteries:          batteries,
+		Segments:           segments,
+		BatteryConnects:    connects,
+		BatteryResetButton: peripheral.NewMockButton(),
+		UpdateRate:         updateRate,
+		RandomSeed:         1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		p.Stop()
+		for _, bat := range batteries {
+			bat.Stop()
+		}
+	})
+	return &testPanel{Panel: p, strip: strip, clock: clock, batteries: batteries}
+}
+
+// render draws and shows one frame, returning it
+func (tp *testPanel) render(t *testing.T) []color.RGBA {
+	t.Helper()
+	tp.update()
+	frame := tp.strip.LastFrame()
+	if len(frame) != tp.strip.NumLEDs() {
+		t.Fatalf("frame has %d pixels, want %d", len(frame), tp.strip.NumLEDs())
+	}
+	return frame
+}
+
+// section returns the pixels of a battery's section in frame, bottom first
+func section(frame []color.RGBA, batteryIndex int) []color.RGBA {
+	start := batteryIndex * (testSectionLen + testSectionGap)
+	return frame[start : start+testSectionLen]
+}
+
+// rgb drops the alpha channel so colors compare on what the LEDs show
+func rgb(c color.RGBA) color.RGBA {
+	return color.RGBA{R: c.R, G: c.G, B: c.B}
+}
+
+// isBlack returns whether c is off
+func isBlack(c color.RGBA) bool {
+	return rgb(c) == color.RGBA{}
+}
+
+// expectPixels fails the test unless pixels from..to-1 of a section are want
+func expectPixels(t *testing.T, pixels []color.RGBA, from, to int, want color.RGBA) {
+	t.Helper()
+	for i := from; i < to; i++ {
+		if rgb(pixels[i]) != rgb(want) {
+			t.Errorf("pixel %d = %v, want %v", i, rgb(pixels[i]), rgb(want))
+		}
+	}
+}
+
+// expectOthersDark fails the test unless every pixel outside the section of batteryIndex is off
+func expectOthersDark(t *testing.T, frame []color.RGBA, batteryIndex int) {
+	t.Helper()
+	start := batteryIndex * (testSectionLen + testSectionGap)
+	for i, c := range frame {
+		if i >= start && i < start+testSectionLen {
+			continue
+		}
+		if !isBlack(c) {
+			t.Errorf("pixel %d outside section %d = %v, want off", i, batteryIndex, rgb(c))
+		}
+	}
+}
+
+// isolate puts every battery except batteryIndex Idle at 0%, which draws nothing
+func (tp *testPanel) isolate(batteryIndex int) {
+	for i, bat := range tp.batteries {
+		if i != batteryIndex {
+			bat.Restore(0, battery.Idle)
+		}
+	}
+}
+
+func TestPanelRendersEachStateInItsSection(t *testing.T) {
+	theme := DefaultTheme()
+	tests := []struct {
+		name  string
+		state battery.SystemState
+		level float32
+		check func(t *testing.T, pixels []color.RGBA)
+	}{
+		{
+			name:  "charged",
+			state: battery.Charged,
+			level: 100,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				// Every pixel green, pulsing between 80% and 100% of the charged brightness
+				low, high := int(theme.ChargedBrightness)*8/10, int(theme.ChargedBrightness)
+				for i, c := range pixels {
+					if c.R != 0 || c.B != 0 || int(c.G) < low || int(c.G) > high {
+						t.Errorf("pixel %d = %v, want green at %d-%d", i, rgb(c), low, high)
+					}
+				}
+			},
+		},
+		{
+			name:  "draining",
+			state: battery.Draining,
+			level: 50,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				bar := theme.drainingColor(50)
+				expectPixels(t, pixels, 0, 5, bar)
+
+				// The two pixels above the bar may flicker, the rest stay dark
+				for i := 5; i < 8; i++ {
+					if !isBlack(pixels[i]) && rgb(pixels[i]) != rgb(bar) {
+						t.Errorf("pixel %d = %v, want off or %v", i, rgb(pixels[i]), rgb(bar))
+					}
+				}
+				expectPixels(t, pixels, 8, testSectionLen, Black)
+			},
+		},
+		{
+			name:  "charging",
+			state: battery.Charging,
+			level: 50,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				expectPixels(t, pixels, 0, 5, theme.Charging)
+
+				// One indicator pixel climbing above the bar
+				indicators := 0
+				for i := 5; i < testSectionLen; i++ {
+					switch {
+					case rgb(pixels[i]) == rgb(theme.ChargingIndicator):
+						indicators++
+					case !isBlack(pixels[i]):
+						t.Errorf("pixel %d = %v, want off or the indicator", i, rgb(pixels[i]))
+					}
+				}
+				if indicators != 1 {
+					t.Errorf("%d charging indicators, want 1", indicators)
+				}
+			},
+		},
+		{
+			name:  "idle",
+			state: battery.Idle,
+			level: 50,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				expectPixels(t, pixels, 0, 5, theme.Idle)
+				expectPixels(t, pixels, 5, testSectionLen, Black)
+			},
+		},
+		{
+			name:  "dead",
+			state: battery.Dead,
+			level: 0,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				// One dim red across the whole section
+				for i, c := range pixels {
+					if c.G != 0 || c.B != 0 || c.R > theme.DeadBrightness || rgb(c) != rgb(pixels[0]) {
+						t.Errorf("pixel %d = %v, want the same red up to %d", i, rgb(c), theme.DeadBrightness)
+					}
+				}
+			},
+		},
+		{
+			name:  "disconnecting",
+			state: battery.Disconnecting,
+			level: 100,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				// The countdown has only just started, so its bar is full
+				expectPixels(t, pixels, 0, testSectionLen, theme.Countdown)
+			},
+		},
+		{
+			name:  "overheated",
+			state: battery.Overheated,
+			level: 50,
+			check: func(t *testing.T, pixels []color.RGBA) {
+				// Without a thermal model the battery reads cold, so the bar doesn't throb
+				expectPixels(t, pixels, 0, 5, colorutil.WithPeak(theme.Overheated, theme.OverheatedBrightness))
+				expectPixels(t, pixels, 5, testSectionLen, Black)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp := newTestPanel(t)
+			for batteryIndex := range tp.batteries {
+				tp.isolate(batteryIndex)
+				tp.batteries[batteryIndex].Restore(tt.level, tt.state)
+
+				frame := tp.render(t)
+				if got := tp.GetBatteryInfo(batteryIndex).State; got != tt.state {
+					t.Fatalf("battery %d state = %v, want %v", batteryIndex, got, tt.state)
+				}
+				tt.check(t, section(frame, batteryIndex))
+				expectOthersDark(t, frame, batteryIndex)
+			}
+		})
+	}
+}
+
+func TestPanelDisconnectingCountdownEmpties(t *testing.T) {
+	tp := newTestPanel(t)
+	tp.isolate(0)
+	tp.batteries[0].Restore(100, battery.Disconnecting)
+
+	// Half the countdown gone leaves half the bar, with the flickering edge above it
+	tp.clock.Advance(5 * time.Second)
+	pixels := section(tp.render(t), 0)
+	expectPixels(t, pixels, 0, 5, DefaultTheme().Countdown)
+	expectPixels(t, pixels, 6, testSectionLen, Black)
+}
+
+func TestPanelDrainingBarShrinksWithLevel(t *testing.T) {
+	tp := newTestPanel(t)
+	tp.isolate(0)
+
+	for _, level := range []float32{100, 80, 30, 10} {
+		tp.batteries[0].Restore(level, battery.Draining)
+		pixels := section(tp.render(t), 0)
+
+		lit := int(level) * testSectionLen / 100
+		expectPixels(t, pixels, 0, lit, DefaultTheme().drainingColor(level))
+		if lit+3 <= testSectionLen {
+			expectPixels(t, pixels, lit+3, testSectionLen, Black)
+		}
+	}
+}
+
+func TestPanelDrainingTipShowsFractionalLevel(t *testing.T) {
+	tp := newTestPanel(t)
+	tp.isolate(0)
+
+	// Half a pixel of charge above the bar lights the tip at half brightness
+	tp.batteries[0].Restore(45, battery.Draining)
+	pixels := section(tp.render(t), 0)
+	barColor := DefaultTheme().drainingColor(45)
+	expectPixels(t, pixels, 0, 4, barColor)
+	expectPixels(t, pixels, 4, 5, colorutil.Scale(barColor, 0.5))
+	if tip := rgb(pixels[4]); tip == rgb(barColor) || isBlack(tip) {
+		t.Errorf("tip pixel = %v, want dimmer than %v but lit", tip, rgb(barColor))
+	}
+}
+
+func TestDrainingColorShadesByLevel(t *testing.T) {
+	theme := DefaultTheme()
+	tests := []struct {
+		level float32
+		want  color.RGBA
+	}{
+		{100, theme.DrainingHigh},
+		{theme.DrainingHighLevel, theme.DrainingHigh},
+		{(theme.DrainingHighLevel + theme.DrainingLowLevel) / 2, theme.Draining},
+		{theme.DrainingLowLevel, theme.DrainingLow},
+		{0, theme.DrainingLow},
+	}
+	for _, tt := range tests {
+		if got := theme.drainingColor(tt.level); got != tt.want {
+			t.Errorf("drainingColor(%v) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+
+	// Without a low level below the high level the bar is flat
+	theme.DrainingHighLevel, theme.DrainingLowLevel = 0, 0
+	if got := theme.drainingColor(10); got != theme.Draining {
+		t.Errorf("flat drainingColor = %v, want %v", got, theme.Draining)
+	}
+}
+
+func TestPanelStopClearsStrip(t *testing.T) {
+	tp := newTestPanel(t)
+	tp.render(t)
+	tp.Stop()
+
+	for i, c := range tp.strip.LastFrame() {
+		if !isBlack(c) {
+			t.Errorf("pixel %d = %v after Stop, want off", i, rgb(c))
+		}
+	}
+}
+
+func TestNewPanelRejectsMismatchedInputs(t *testing.T) {
+	clock := battery.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	batteries := battery.NewBatteries([]battery.Config{{Clock: clock}, {Clock: clock}})
+	defer func() {
+		for _, bat := range batteries {
+			bat.Stop()
+		}
+	}()
+
+	_, err := NewPanel(PanelConfig{
+		Batteries:       batteries,
+		LEDStrip:        peripheral.NewMockStrip(20),
+		BatteryConnects: []peripheral.ButtonReader{peripheral.NewMockButton()},
+	})
+	if !errors.Is(err, ErrBatteryCount) {
+		t.Errorf("NewPanel with one connect for two batteries = %v, want ErrBatteryCount", err)
+	}
+}
+
+func TestHeartbeatReportsPausedPanelAlive(t *testing.T) {
+	tp := newTestPanelAt(t, time.Millisecond)
+	tp.Pause()
+	heartbeat := telemetry.NewHeartbeat(tp.Panel, telemetry.HeartbeatConfig{Interval: time.Hour})
+
+	// A paused panel draws no frames, but its update loop keeps ticking
+	frames, ticks := tp.FrameCount(), tp.TickCount()
+	deadline := time.Now().Add(time.Second)
+	for tp.TickCount() < ticks+3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := tp.FrameCount(); got != frames {
+		t.Fatalf("paused panel drew %d frames", got-frames)
+	}
+	if health := heartbeat.Beat(); !health.PanelAlive {
+		t.Error("heartbeat of a paused panel reports panelAlive=false, want true")
+	}
+}
+
+// pixelRecorder is a NeoPixel driver remembering the last color written
+type pixelRecorder struct {
+	last color.RGBA
+}
+
+func (r *pixelRecorder) WriteColors(buf []color.RGBA) error {
+	r.last = buf[0]
+	return nil
+}
+
+func TestNeoPixelStatusOverrideHoldsUntilTimeout(t *testing.T) {
+	recorder := &pixelRecorder{}
+	status := NewNeoPixelStatus(&peripheral.NeoPixel{NeoPixelDriver: recorder})
+	alarm := color.RGBA{R: 25, A: 255}
+
+	// The override shows over the status on every tick until it times out
+	status.Override(alarm, 20*time.Millisecond)
+	for range 3 {
+		status.Show(StatusPaused, 0)
+		if rgb(recorder.last) != rgb(alarm) {
+			t.Fatalf("pixel = %v during override, want %v", recorder.last, alarm)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	status.Show(StatusPaused, 0)
+	if rgb(recorder.last) != rgb(statusYellow) {
+		t.Errorf("pixel = %v after override timed out, want %v", recorder.last, statusYellow)
+	}
+
+	// A panel without an overridable indicator leaves the NeoPixel to the caller
+	if newTestPanel(t).OverrideStatus(alarm, time.Second) {
+		t.Error("OverrideStatus without a status indicator = true, want false")
+	}
+}
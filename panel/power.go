@@ -0,0 +1,115 @@
+package panel
+
+import (
+	"image/color"
+	"math"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// PowerConfig sets when an idle panel drops into standby
+type PowerConfig struct {
+	IdleTimeout  time.Duration // Time without any input change before standby, 0 disables standby
+	StandbyRate  time.Duration // Update interval while in standby, letting the MCU sleep between ticks
+	StandbyColor color.RGBA    // Color of the slow standby breathing animation
+}
+
+// DefaultPowerConfig returns a config entering standby after five idle minutes
+func DefaultPowerConfig() PowerConfig {
+	return PowerConfig{
+		IdleTimeout:  5 * time.Minute,
+		StandbyRate:  200 * time.Millisecond,
+		StandbyColor: color.RGBA{R: 0, G: 0, B: 52, A: 255},
+	}
+}
+
+// PowerManager watches the panel inputs and decides when it is in standby.
+// Presses latched by input interrupts count as activity, so even a press
+// between the slow standby ticks wakes the panel on the next one.
+type PowerManager struct {
+	config       PowerConfig
+	lastInputs   []bool
+	lastActivity time.Time
+	standby      bool
+}
+
+// NewPowerManager creates a power manager, active until IdleTimeout passes without input
+func NewPowerManager(config PowerConfig) *PowerManager {
+	if config.StandbyRate <= 0 {
+		config.StandbyRate = DefaultPowerConfig().StandbyRate
+	}
+	return &PowerManager{config: config, lastActivity: time.Now()}
+}
+
+// Observe records the current inputs, returning whether the standby state changed.
+// Any input differing from the previous observation counts as activity, as does
+// busy, so a panel with a battery still moving never drops into standby.
+func (m *PowerManager) Observe(inputs []bool, busy bool, now time.Time) bool {
+	active := busy || len(inputs) != len(m.lastInputs)
+	for i := 0; !active && i < len(inputs); i++ {
+		active = inputs[i] != m.lastInputs[i]
+	}
+	m.lastInputs = append(m.lastInputs[:0], inputs...)
+
+	if active {
+		m.lastActivity = now
+	}
+
+	standby := m.config.IdleTimeout > 0 && now.Sub(m.lastActivity) >= m.config.IdleTimeout
+	changed := standby != m.standby
+	m.standby = standby
+	return changed
+}
+
+// Wake leaves standby as if an input had changed
+func (m *PowerManager) Wake(now time.Time) {
+	m.lastActivity = now
+}
+
+// IsStandby returns whether the panel is in standby
+func (m *PowerManager) IsStandby() bool {
+	return m.standby
+}
+
+// renderStandby replaces the battery display with a dim breathing glow (must be called with mutex locked)
+func (p *Panel) renderStandby() {
+	brightness := 0.3 + 0.7*(0.5+0.5*math.Sin(p.pulsePhase*2*math.Pi))
+	glow := colorutil.Scale(p.power.config.StandbyColor, brightness)
+
+	for _, strip := range p.strips {
+		strip.SetAll(Black)
+	}
+	for _, seg := range p.segments {
+		seg.fill(glow)
+	}
+}
+
+// IsStandby returns whether the panel has dropped into standby after being idle
+func (p *Panel) IsStandby() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.power != nil && p.power.IsStandby()
+}
+
+// batteriesBusy returns whether any battery is draining, disconnecting or
+// charging, which holds off standby (must be called with mutex locked)
+func (p *Panel) batteriesBusy() bool {
+	for _, bat := range p.batteries {
+		switch bat.GetInfo().State {
+		case battery.Draining, battery.Disconnecting, battery.Charging:
+			return true
+		}
+	}
+	return false
+}
+
+// Wake brings the panel out of standby, e.g. on a console command
+func (p *Panel) Wake() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.power != nil {
+		p.power.Wake(time.Now())
+	}
+}
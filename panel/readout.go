@@ -0,0 +1,144 @@
+package panel
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// NumericDisplay shows short numeric text, e.g. a peripheral.SevenSegment
+type NumericDisplay interface {
+	ShowText(text string) error
+}
+
+// ReadoutAll rotates the readout through every battery instead of showing one
+const ReadoutAll = -1
+
+// BatteryReadout shows a battery's number and percentage on a 4-digit display,
+// e.g. "3. 42", either for a selected battery or rotating through all of them.
+// Turning the optional encoder steps through all, battery 1, battery 2 and so on.
+type BatteryReadout struct {
+	mu          sync.Mutex
+	panel       *Panel
+	display     NumericDisplay
+	encoder     peripheral.RotaryReader // nil if selection is only by Select
+	selected    int                     // Battery index, or ReadoutAll
+	rotateEvery time.Duration
+	rotateIndex int
+	rotatedAt   time.Time
+	lastText    string
+
+	// Ticker for refreshing
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewBatteryReadout creates a readout for panel rotating through all batteries
+func NewBatteryReadout(panel *Panel, display NumericDisplay, encoder peripheral.RotaryReader) *BatteryReadout {
+	return &BatteryReadout{
+		panel:       panel,
+		display:     display,
+		encoder:     encoder,
+		selected:    ReadoutAll,
+		rotateEvery: 2 * time.Second,
+	}
+}
+
+// Select shows a single battery (0-based), or ReadoutAll to rotate through them
+func (r *BatteryReadout) Select(batteryIndex int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if batteryIndex < 0 || batteryIndex >= len(r.panel.Batteries()) {
+		batteryIndex = ReadoutAll
+	}
+	r.selected = batteryIndex
+	r.lastText = ""
+}
+
+// Selected returns the battery shown, or ReadoutAll
+func (r *BatteryReadout) Selected() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.selected
+}
+
+// Start begins refreshing the readout at the given rate
+func (r *BatteryReadout) Start(refreshRate time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+	r.running = true
+	r.ticker = time.NewTicker(refreshRate)
+	r.stopTicker = make(chan struct{})
+
+	ticker, stop := r.ticker, r.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				r.Update(time.Now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing and blanks the readout
+func (r *BatteryReadout) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		close(r.stopTicker)
+		if r.ticker != nil {
+			r.ticker.Stop()
+		}
+		r.running = false
+		r.display.ShowText("")
+	}
+}
+
+// Update reads the encoder and redraws the readout if it changed.
+// It is called by the refresh goroutine but may also be driven externally.
+func (r *BatteryReadout) Update(now time.Time) error {
+	infos := r.panel.GetAllBatteryInfo()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(infos) == 0 {
+		return nil
+	}
+
+	// Positions are all, then each battery
+	if r.encoder != nil {
+		if delta := r.encoder.Delta(); delta != 0 {
+			n := len(infos) + 1
+			position := ((r.selected+1+delta)%n + n) % n
+			r.selected = position - 1
+		}
+	}
+
+	index := r.selected
+	if index == ReadoutAll {
+		if now.Sub(r.rotatedAt) >= r.rotateEvery {
+			r.rotateIndex = (r.rotateIndex + 1) % len(infos)
+			r.rotatedAt = now
+		}
+		index = r.rotateIndex % len(infos)
+	}
+
+	text := fmt.Sprintf("%d.%3.0f", index+1, infos[index].BatteryLevel)
+	if text == r.lastText {
+		return nil
+	}
+	r.lastText = text
+	return r.display.ShowText(text)
+}
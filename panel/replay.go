@@ -0,0 +1,105 @@
+package panel
+
+import (
+	"sort"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// historyReplay holds the state of a time-compressed history playback
+type historyReplay struct {
+	startedAt   time.Time // Wall time playback began
+	windowStart time.Time // Start of the replayed period
+	windowEnd   time.Time // End of the replayed period (when playback began)
+	playback    time.Duration
+	scale       float64 // Replayed time per unit of wall time
+	histories   [][]battery.StateTransition
+	final       []battery.BatteryInfo // Battery info at the end of the window
+}
+
+// ReplayHistory replays the last window of recorded battery state transitions
+// across the panel compressed into playback, e.g. the last hour in 20 seconds.
+// Live rendering resumes automatically when playback finishes.
+func (p *Panel) ReplayHistory(window, playback time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if window <= 0 || playback <= 0 {
+		return
+	}
+
+	// Transitions are stamped in simulation time, playback runs in wall time
+	simNow := battery.SimulationClock.Now()
+	r := &historyReplay{
+		startedAt:   time.Now(),
+		windowStart: simNow.Add(-window),
+		windowEnd:   simNow,
+		playback:    playback,
+		scale:       float64(window) / float64(playback),
+		histories:   make([][]battery.StateTransition, len(p.batteries)),
+		final:       make([]battery.BatteryInfo, len(p.batteries)),
+	}
+	for i, bat := range p.batteries {
+		r.histories[i] = bat.History()
+		r.final[i] = bat.GetInfo()
+	}
+	p.replay = r
+}
+
+// StopReplay ends a history playback early and returns to live rendering
+func (p *Panel) StopReplay() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.replay = nil
+}
+
+// IsReplaying returns whether a history playback is in progress
+func (p *Panel) IsReplaying() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.replay != nil
+}
+
+// replayTime maps wall time onto the replayed period, returning false once playback is over
+func (r *historyReplay) replayTime(now time.Time) (time.Time, bool) {
+	elapsed := now.Sub(r.startedAt)
+	if elapsed >= r.playback {
+		return r.windowEnd, false
+	}
+	return r.windowStart.Add(time.Duration(float64(elapsed) * r.scale)), true
+}
+
+// infoAt reconstructs a battery's state and level at time t from its history
+func (r *historyReplay) infoAt(batteryIndex int, t time.Time) battery.BatteryInfo {
+	entries := r.histories[batteryIndex]
+	final := r.final[batteryIndex]
+
+	// Index of the first transition after t
+	next := sort.Search(len(entries), func(k int) bool {
+		return entries[k].At.After(t)
+	})
+
+	if next == 0 {
+		// Before the first recorded transition
+		if len(entries) == 0 {
+			return battery.BatteryInfo{State: final.State, BatteryLevel: final.BatteryLevel}
+		}
+		return battery.BatteryInfo{State: entries[0].From, BatteryLevel: entries[0].Level}
+	}
+
+	prev := entries[next-1]
+	toTime, toLevel := r.windowEnd, final.BatteryLevel
+	if next < len(entries) {
+		toTime, toLevel = entries[next].At, entries[next].Level
+	}
+
+	// Interpolate the level between the surrounding transitions
+	level := prev.Level
+	if span := toTime.Sub(prev.At); span > 0 {
+		progress := float32(t.Sub(prev.At)) / float32(span)
+		level = prev.Level + (toLevel-prev.Level)*progress
+	}
+
+	return battery.BatteryInfo{State: prev.To, BatteryLevel: level}
+}
@@ -0,0 +1,163 @@
+package panel
+
+import (
+	"image/color"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Segment maps a battery's LED section onto a range of LEDs on a physical strip.
+// Batteries may share one strip or each have their own strip on its own SPI bus.
+type Segment struct {
+	Strip    peripheral.LedStrip
+	Start    int  // First LED of the section on the strip
+	Length   int  // Number of LEDs in the section
+	Reversed bool // The section's bottom is at Start+Length-1, e.g. on a zig-zag routed strip
+}
+
+// setPixel sets a pixel relative to the bottom of the segment, ignoring out of range indices
+func (s Segment) setPixel(index int, c color.RGBA) {
+	if index < 0 || index >= s.Length {
+		return
+	}
+	if s.Reversed {
+		index = s.Length - 1 - index
+	}
+	s.Strip.SetPixel(s.Start+index, c)
+}
+
+// getPixel returns a pixel relative to the bottom of the segment, black for out of range indices
+func (s Segment) getPixel(index int) color.RGBA {
+	if index < 0 || index >= s.Length {
+		return color.RGBA{}
+	}
+	if s.Reversed {
+		index = s.Length - 1 - index
+	}
+	return s.Strip.GetPixel(s.Start + index)
+}
+
+// Len returns the number of LEDs in the segment, so it can be drawn on with the render package
+func (s Segment) Len() int {
+	return s.Length
+}
+
+// Set sets a pixel relative to the bottom of the segment, for the render package
+func (s Segment) Set(index int, c color.RGBA) {
+	s.setPixel(index, c)
+}
+
+// average returns the mean color of the pixels in the segment
+func (s Segment) average() color.RGBA {
+	if s.Length <= 0 {
+		return color.RGBA{}
+	}
+	var r, g, b int
+	for i := 0; i < s.Length; i++ {
+		c := s.Strip.GetPixel(s.Start + i)
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+	}
+	return color.RGBA{R: uint8(r / s.Length), G: uint8(g / s.Length), B: uint8(b / s.Length), A: 255}
+}
+
+// fill sets every pixel in the segment to the same color
+func (s Segment) fill(c color.RGBA) {
+	for i := 0; i < s.Length; i++ {
+		s.Strip.SetPixel(s.Start+i, c)
+	}
+}
+
+// Direction is the way a section's bar grows along the strip
+type Direction int
+
+const (
+	Forward Direction = iota // Bar grows towards higher LED indices
+	Reverse                  // Bar grows towards lower LED indices
+)
+
+// Section is one battery's LED range in a SectionLayout
+type Section struct {
+	Start     int // First LED of the section on the strip
+	Length    int // Number of LEDs in the section
+	Direction Direction
+}
+
+// SectionLayout lists the LED range of each battery, in battery order, for
+// strips that are unevenly cut or routed back and forth across the panel
+type SectionLayout []Section
+
+// Segments places the layout's sections on strip
+func (l SectionLayout) Segments(strip peripheral.LedStrip) []Segment {
+	segments := make([]Segment, len(l))
+	for i, section := range l {
+		segments[i] = Segment{
+			Strip:    strip,
+			Start:    section.Start,
+			Length:   section.Length,
+			Reversed: section.Direction == Reverse,
+		}
+	}
+	return segments
+}
+
+// defaultSegments carves a single strip into equal sections for numBatteries,
+// skipping the obscured LEDs at each end and leaving gaps between sections.
+// When airLockLEDs is positive that many LEDs at the end are reserved for the
+// airlock and returned as a separate segment.
+func defaultSegments(strip peripheral.LedStrip, numBatteries int, airLockLEDs int) ([]Segment, Segment) {
+	if numBatteries == 0 {
+		return nil, Segment{}
+	}
+
+	// Calculate LED allocation - skip first 6 and last 6 LEDs
+	totalLEDs := strip.NumLEDs()
+	obscuredLEDs := 12 // 6 at top + 6 at bottom
+	usableLEDs := totalLEDs - obscuredLEDs
+	ledOffset := 6 // Skip first 6 LEDs
+
+	spacingLEDs := 4
+
+	// Reserve the airlock section after the last battery
+	var airLock Segment
+	if airLockLEDs > 0 {
+		usableLEDs -= airLockLEDs + spacingLEDs
+		airLock = Segment{
+			Strip:  strip,
+			Start:  ledOffset + usableLEDs + spacingLEDs,
+			Length: airLockLEDs,
+		}
+	}
+
+	totalSpacing := spacingLEDs * (numBatteries - 1)
+	batteryLEDs := (usableLEDs - totalSpacing) / numBatteries
+
+	segments := make([]Segment, numBatteries)
+	for i := range segments {
+		segments[i] = Segment{
+			Strip:  strip,
+			Start:  ledOffset + i*(batteryLEDs+spacingLEDs),
+			Length: batteryLEDs,
+		}
+	}
+	return segments, airLock
+}
+
+// uniqueStrips returns each distinct strip used by the segments, in order of first use
+func uniqueStrips(segments []Segment) []peripheral.LedStrip {
+	var strips []peripheral.LedStrip
+	for _, segment := range segments {
+		found := false
+		for _, strip := range strips {
+			if strip == segment.Strip {
+				found = true
+				break
+			}
+		}
+		if !found {
+			strips = append(strips, segment.Strip)
+		}
+	}
+	return strips
+}
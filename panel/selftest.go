@@ -0,0 +1,159 @@
+package panel
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Self-test timing
+const (
+	selfTestPixelStep   = 5 * time.Millisecond   // Time the walking pixel spends on each LED
+	selfTestSectionTime = 300 * time.Millisecond // Time each section is lit
+	selfTestBlinkTime   = 250 * time.Millisecond // Half period of the pass/fail blink
+	selfTestBlinks      = 3
+)
+
+// SelfTestResult reports what the self-test found
+type SelfTestResult struct {
+	Passed    bool
+	Stuck     []string // Momentary inputs (reset, airlock) held down for the whole test
+	Connected []string // Battery connects closed for the whole test, not a fault on their own
+}
+
+// String summarises the result for the log or console
+func (r SelfTestResult) String() string {
+	if r.Passed {
+		return "self-test passed"
+	}
+	return fmt.Sprintf("self-test failed, stuck inputs: %v", r.Stuck)
+}
+
+// selfTestInput is an input watched during the self-test
+type selfTestInput struct {
+	name      string
+	input     peripheral.ButtonReader
+	momentary bool
+	released  bool // Seen released at least once
+}
+
+// SelfTest checks the wiring before a show: it walks a white pixel across every
+// strip, flashes each battery section in turn, watches every input for ones stuck
+// pressed, and blinks the result on the status indicator (green pass, red fail).
+// The panel is paused while it runs and resumed afterwards if it was running.
+func (p *Panel) SelfTest() SelfTestResult {
+	p.mu.Lock()
+	wasPaused := p.paused
+	p.paused = true
+	p.selfTesting = true
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		p.selfTesting = false
+		p.mu.Unlock()
+		if !wasPaused {
+			p.Resume()
+		}
+	}()
+
+	inputs := p.selfTestInputs()
+	sample := func() {
+		for i := range inputs {
+			if !inputs[i].input.IsPressed() {
+				inputs[i].released = true
+			}
+		}
+	}
+
+	// Walk a pixel along every strip
+	for _, strip := range p.strips {
+		for i := 0; i < strip.NumLEDs(); i++ {
+			strip.Clear()
+			strip.SetPixel(i, White)
+			strip.Show()
+			sample()
+			if !p.sleepWithContext(selfTestPixelStep) {
+				return SelfTestResult{}
+			}
+		}
+		strip.Clear()
+		strip.Show()
+	}
+
+	// Flash each battery section, then the airlock
+	sections := p.segments
+	if p.airLockSegment.Strip != nil {
+		sections = append(append([]Segment(nil), sections...), p.airLockSegment)
+	}
+	for _, seg := range sections {
+		seg.fill(White)
+		seg.Strip.Show()
+		sample()
+		if !p.sleepWithContext(selfTestSectionTime) {
+			return SelfTestResult{}
+		}
+		seg.fill(Black)
+		seg.Strip.Show()
+	}
+
+	result := SelfTestResult{Passed: true}
+	for _, in := range inputs {
+		if in.released {
+			continue
+		}
+		if in.momentary {
+			result.Stuck = append(result.Stuck, in.name)
+			result.Passed = false
+		} else {
+			result.Connected = append(result.Connected, in.name)
+		}
+	}
+
+	p.blinkSelfTestResult(result.Passed)
+	return result
+}
+
+// selfTestInputs lists every input the panel reads
+func (p *Panel) selfTestInputs() []selfTestInput {
+	var inputs []selfTestInput
+	add := func(name string, input peripheral.ButtonReader, momentary bool) {
+		if input != nil {
+			inputs = append(inputs, selfTestInput{name: name, input: input, momentary: momentary})
+		}
+	}
+
+	add("reset", p.batteryResetButton, true)
+	for i, input := range p.batteryResets {
+		add(fmt.Sprintf("battery %d reset", i+1), input, true)
+	}
+	for i, input := range p.batteryConnects {
+		add(fmt.Sprintf("battery %d connect", i+1), input, false)
+	}
+	add("airlock", p.airLocktButton, true)
+	return inputs
+}
+
+// blinkSelfTestResult blinks the status indicator green for a pass or red for a failure
+func (p *Panel) blinkSelfTestResult(passed bool) {
+	if p.statusIndicator == nil {
+		return
+	}
+
+	status := StatusRunning
+	if !passed {
+		status = StatusError
+	}
+	for i := 0; i < selfTestBlinks; i++ {
+		// Phase 0.25 is the brightest point of the running breathe and the lit half of the error blink
+		p.statusIndicator.Show(status, 0.25)
+		if !p.sleepWithContext(selfTestBlinkTime) {
+			return
+		}
+		p.statusIndicator.Show(StatusStandby, 0)
+		if !p.sleepWithContext(selfTestBlinkTime) {
+			return
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package panel
+
+import (
+	"time"
+)
+
+// FrameStats summarises how long the panel takes to draw and show frames
+type FrameStats struct {
+	Frames       uint32        // Frames drawn since the stats were reset
+	AvgFrameTime time.Duration // Mean time to check inputs, render and show a frame
+	MaxFrameTime time.Duration // Slowest frame
+	Dropped      uint32        // Ticks missed because a frame overran the update rate
+	UpdateRate   time.Duration // Current interval between frames
+}
+
+// overrunWindow is the number of frames over which sustained overruns are detected
+const overrunWindow = 100
+
+// overrunWarnInterval limits how often the overrun warning is logged
+const overrunWarnInterval = time.Minute
+
+// frameTiming accumulates FrameStats
+type frameTiming struct {
+	stats     FrameStats
+	total     time.Duration
+	lastStart time.Time
+
+	// Overrun detection over the current window
+	windowFrames  int
+	windowDropped uint32
+	lastWarning   time.Time
+}
+
+// recordFrame adds a frame that started at start to the stats, warning through
+// the logger if the update rate isn't being sustained (must be called with mutex locked)
+func (p *Panel) recordFrame(start time.Time) {
+	t := &p.timing
+	elapsed := time.Since(start)
+	rate := p.tickRate()
+
+	t.stats.Frames++
+	t.total += elapsed
+	t.stats.MaxFrameTime = max(t.stats.MaxFrameTime, elapsed)
+	t.stats.AvgFrameTime = t.total / time.Duration(t.stats.Frames)
+	p.frameCount++
+
+	// A gap of several update intervals since the previous frame means ticks were dropped
+	if !t.lastStart.IsZero() && rate > 0 {
+		if missed := int(start.Sub(t.lastStart)/rate) - 1; missed > 0 {
+			t.stats.Dropped += uint32(missed)
+			t.windowDropped += uint32(missed)
+		}
+	}
+	t.lastStart = start
+
+	t.windowFrames++
+	if t.windowFrames < overrunWindow {
+		return
+	}
+	if t.windowDropped > overrunWindow/10 && p.log != nil && time.Since(t.lastWarning) >= overrunWarnInterval {
+		p.log.Warn("panel can't sustain %v updates: %d ticks dropped in %d frames, avg frame %v, max %v",
+			rate, t.windowDropped, t.windowFrames, t.stats.AvgFrameTime, t.stats.MaxFrameTime)
+		t.lastWarning = time.Now()
+	}
+	t.windowFrames = 0
+	t.windowDropped = 0
+}
+
+// tickRate returns the current interval between updates (must be called with mutex locked)
+func (p *Panel) tickRate() time.Duration {
+	if p.power != nil && p.power.IsStandby() {
+		return p.power.config.StandbyRate
+	}
+	return p.updateRate
+}
+
+// Stats returns the frame timing statistics
+func (p *Panel) Stats() FrameStats {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	stats := p.timing.stats
+	stats.UpdateRate = p.tickRate()
+	return stats
+}
+
+// ResetStats clears the frame timing statistics
+func (p *Panel) ResetStats() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.timing = frameTiming{lastWarning: p.timing.lastWarning}
+}
+
+// SetUpdateRate changes the interval between updates while running
+func (p *Panel) SetUpdateRate(rate time.Duration) {
+	if rate <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.updateRate = rate
+	if p.animationTicker != nil && (p.power == nil || !p.power.IsStandby()) {
+		p.animationTicker.Reset(rate)
+	}
+	// Don't count the change as dropped ticks
+	p.timing.lastStart = time.Time{}
+}
@@ -0,0 +1,107 @@
+package panel
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// StatusDisplay shows the panel as text on an OLED or TFT: the overall status,
+// each battery's state and percentage (or the countdown while disconnecting),
+// and the airlock, so operators don't have to read the LED colors
+type StatusDisplay struct {
+	mu      sync.Mutex
+	panel   *Panel
+	display *peripheral.Display
+
+	// Ticker for refreshing
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewStatusDisplay creates a status display for panel on display
+func NewStatusDisplay(panel *Panel, display *peripheral.Display) *StatusDisplay {
+	return &StatusDisplay{
+		panel:   panel,
+		display: display,
+	}
+}
+
+// Start begins refreshing the display at the given rate; text displays are slow
+// to write, so a few times a second is plenty
+func (s *StatusDisplay) Start(refreshRate time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+	s.running = true
+	s.ticker = time.NewTicker(refreshRate)
+	s.stopTicker = make(chan struct{})
+
+	ticker, stop := s.ticker, s.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Update()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing and blanks the display
+func (s *StatusDisplay) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		close(s.stopTicker)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+		s.running = false
+		s.display.Clear()
+		s.display.Show()
+	}
+}
+
+// Update redraws the display from the panel's current state
+func (s *StatusDisplay) Update() error {
+	lines := statusLines(s.panel)
+	for row, line := range lines {
+		s.display.SetLine(row, line)
+	}
+	return s.display.Show()
+}
+
+// statusLines formats the panel state as one line per row
+func statusLines(p *Panel) []string {
+	infos := p.GetAllBatteryInfo()
+	lines := make([]string, 0, len(infos)+2)
+
+	lines = append(lines, "PANEL "+strings.ToUpper(p.Status().String()))
+	for i, info := range infos {
+		lines = append(lines, fmt.Sprintf("B%d %-13s %s", i+1, strings.ToUpper(info.State.String()), batteryDetail(info)))
+	}
+	if info, ok := p.GetAirLockInfo(); ok {
+		lines = append(lines, fmt.Sprintf("LOCK %-14s %d", strings.ToUpper(info.State.String()), info.PoweredBatteries))
+	}
+	return lines
+}
+
+// batteryDetail is the seconds left while disconnecting, otherwise the battery level
+func batteryDetail(info battery.BatteryInfo) string {
+	if info.State == battery.Disconnecting {
+		return fmt.Sprintf("%3ds", int(info.DisconnectingDurationRemaining.Seconds()+0.5))
+	}
+	return fmt.Sprintf("%3.0f%%", info.BatteryLevel)
+}
@@ -0,0 +1,196 @@
+package panel
+
+import (
+	"image/color"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Status is the overall system state shown on a StatusIndicator
+type Status int
+
+const (
+	StatusRunning Status = iota // Live show
+	StatusDemo                  // Demo sequence driving the inputs
+	StatusPaused                // Panel paused, e.g. while a pattern runs
+	StatusStandby               // Idle standby
+	StatusError                 // An error was reported recently
+	StatusAborted               // Master kill switch latched until reset
+	StatusWon                   // Win finale playing
+	StatusLost                  // Loss finale playing
+)
+
+// String returns the status name
+func (s Status) String() string {
+	switch s {
+	case StatusRunning:
+		return "Running"
+	case StatusDemo:
+		return "Demo"
+	case StatusPaused:
+		return "Paused"
+	case StatusStandby:
+		return "Standby"
+	case StatusError:
+		return "Error"
+	case StatusAborted:
+		return "Aborted"
+	case StatusWon:
+		return "Won"
+	case StatusLost:
+		return "Lost"
+	default:
+		return "Unknown"
+	}
+}
+
+// errorStatusDuration is how long a reported error is shown
+const errorStatusDuration = 5 * time.Second
+
+// StatusIndicator shows the panel's overall status, e.g. on the board's NeoPixel.
+// Show is called every panel tick with phase running 0.0 to 1.0 once a second
+// for animating the status.
+type StatusIndicator interface {
+	Show(status Status, phase float64)
+}
+
+// StatusOverrider is a StatusIndicator that can show a color set from outside the
+// panel, e.g. by a mission step, in place of the status until timeout passes
+type StatusOverrider interface {
+	Override(c color.RGBA, timeout time.Duration)
+}
+
+// Status pixel colors. The NeoPixel has no gamma correction and sits right by the
+// players, so these stay dim raw values rather than the panel's full range colors.
+var (
+	statusRed    = color.RGBA{R: 5, A: 255}
+	statusGreen  = color.RGBA{G: 5, A: 255}
+	statusYellow = color.RGBA{R: 5, G: 5, A: 255}
+	statusBlue   = color.RGBA{B: 5, A: 255}
+)
+
+// NeoPixelStatus shows the status on a NeoPixel: running breathes green, errors
+// blink red, an abort or lost game blinks red fast, a won game is steady green, demos breathe blue,
+// paused is steady yellow and standby is off. An Override holds its color over
+// all of these until it times out.
+type NeoPixelStatus struct {
+	mu            sync.Mutex
+	pixel         *peripheral.NeoPixel
+	last          color.RGBA
+	shown         bool
+	override      color.RGBA
+	overrideUntil time.Time
+}
+
+var _ StatusOverrider = (*NeoPixelStatus)(nil)
+
+// NewNeoPixelStatus creates a status indicator on pixel
+func NewNeoPixelStatus(pixel *peripheral.NeoPixel) *NeoPixelStatus {
+	return &NeoPixelStatus{pixel: pixel}
+}
+
+// Override shows c in place of the status for timeout
+func (s *NeoPixelStatus) Override(c color.RGBA, timeout time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.override = c
+	s.overrideUntil = time.Now().Add(timeout)
+}
+
+// Show writes the status color, skipping the write if it hasn't changed
+func (s *NeoPixelStatus) Show(status Status, phase float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	breathe := 0.2 + 0.8*(0.5+0.5*math.Sin(phase*2*math.Pi))
+
+	var c color.RGBA
+	switch status {
+	case StatusRunning:
+		c = colorutil.Scale(statusGreen, breathe)
+	case StatusDemo:
+		c = colorutil.Scale(statusBlue, breathe)
+	case StatusPaused:
+		c = statusYellow
+	case StatusError:
+		if phase < 0.5 {
+			c = statusRed
+		}
+	case StatusAborted, StatusLost:
+		if math.Mod(phase, 0.25) < 0.125 {
+			c = statusRed
+		}
+	case StatusWon:
+		c = statusGreen
+	}
+
+	if time.Now().Before(s.overrideUntil) {
+		c = s.override
+	}
+
+	if s.shown && c == s.last {
+		return
+	}
+	s.pixel.SetColorAndPause(c, 0)
+	s.last = c
+	s.shown = true
+}
+
+// status works out the overall status (must be called with mutex locked)
+func (p *Panel) status(now time.Time) Status {
+	switch {
+	case p.finale != nil && p.finale.win:
+		return StatusWon
+	case p.finale != nil:
+		return StatusLost
+	case now.Before(p.errorUntil) || len(p.failing) > 0:
+		return StatusError
+	case p.aborted:
+		return StatusAborted
+	case p.paused:
+		return StatusPaused
+	case p.power != nil && p.power.IsStandby():
+		return StatusStandby
+	case p.demo:
+		return StatusDemo
+	default:
+		return StatusRunning
+	}
+}
+
+// showStatus updates the status indicator, if any (must be called with mutex locked)
+func (p *Panel) showStatus(now time.Time) {
+	if p.statusIndicator != nil {
+		p.statusIndicator.Show(p.status(now), p.flashPhase)
+	}
+}
+
+// Status returns the panel's overall status
+func (p *Panel) Status() Status {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.status(time.Now())
+}
+
+// OverrideStatus shows c on the status indicator in place of the status for
+// timeout. Returns false if the indicator can't be overridden or there is none.
+func (p *Panel) OverrideStatus(c color.RGBA, timeout time.Duration) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	overrider, ok := p.statusIndicator.(StatusOverrider)
+	if ok {
+		overrider.Override(c, timeout)
+	}
+	return ok
+}
+
+// ReportError shows the error status for a few seconds, e.g. from the recovery handler
+func (p *Panel) ReportError() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.errorUntil = time.Now().Add(errorStatusDuration)
+}
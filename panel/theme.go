@@ -0,0 +1,208 @@
+package panel
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// Theme sets the colors and animation speeds the panel draws with, so the panel
+// can be restyled, e.g. with ColorblindTheme, without code changes. Pulsing
+// states take their hue from the color and their peak from the brightness.
+type Theme struct {
+	// Battery sections
+	Charged              color.RGBA // Full battery, pulsing gently
+	ChargedBrightness    uint8      // Peak of the charged pulse for a new battery, lower as it wears
+	Countdown            color.RGBA // Bar emptying while a battery disconnects
+	CountdownEdge        color.RGBA // Flickering edge of the countdown bar
+	Draining             color.RGBA // Bar of a draining battery, midway between the low and high levels
+	DrainingHigh         color.RGBA // Bar of a draining battery at or above DrainingHighLevel
+	DrainingLow          color.RGBA // Bar of a draining battery at or below DrainingLowLevel
+	DrainingHighLevel    float32    // Level (0-100) below which the bar shades from DrainingHigh towards Draining
+	DrainingLowLevel     float32    // Level (0-100) at and below which the bar is DrainingLow
+	Dead                 color.RGBA // Pulse of an empty battery
+	DeadBrightness       uint8      // Peak of the dead pulse
+	Charging             color.RGBA // Bar of a charging battery
+	ChargingIndicator    color.RGBA // Light climbing above the charging bar
+	Idle                 color.RGBA // Bar of a battery holding its level
+	Overheated           color.RGBA // Bar of a battery in thermal shutdown, throbbing while it is hot
+	OverheatedBrightness uint8      // Peak of the overheated bar
+	Unknown              color.RGBA // Pulse of a battery in a state the panel doesn't know
+	UnknownBrightness    uint8      // Peak of the unknown pulse
+
+	// Airlock and alarms
+	AirLockReady   color.RGBA // Sealed with enough power to open
+	AirLockWarning color.RGBA // Sealed without power, or open
+	AirLockCycle   color.RGBA // Pressure bar while cycling
+	Alarm          color.RGBA // Flashing over every section on an abort
+
+	// Animation speeds
+	FlashPeriod time.Duration // One flash cycle, e.g. the charged pulse and countdown flicker
+	PulsePeriod time.Duration // One slow pulse cycle, e.g. the unknown state and standby glow
+
+	// Blink rhythm of each state over its animation, all Solid if zero
+	Cadences StateCadences
+
+	// Caps every channel of every color above, 0 for no cap, e.g. to limit power draw
+	MaxBrightness uint8
+}
+
+// DefaultTheme returns the panel's standard green, yellow and red styling
+func DefaultTheme() Theme {
+	return Theme{
+		Charged:              Green,
+		ChargedBrightness:    132,
+		Countdown:            Green,
+		CountdownEdge:        Yellow,
+		Draining:             Yellow,
+		DrainingHigh:         Green,
+		DrainingLow:          Red,
+		DrainingHighLevel:    60,
+		DrainingLowLevel:     20,
+		Dead:                 Red,
+		DeadBrightness:       80,
+		Charging:             Green,
+		ChargingIndicator:    Yellow,
+		Idle:                 colorutil.Scale(Yellow, 0.5),
+		Overheated:           color.RGBA{R: 255, G: 144, B: 0, A: 255},
+		OverheatedBrightness: 74,
+		Unknown:              Blue,
+		UnknownBrightness:    199,
+
+		AirLockReady:   Green,
+		AirLockWarning: Red,
+		AirLockCycle:   color.RGBA{R: 255, G: 184, B: 0, A: 255},
+		Alarm:          Red,
+
+		FlashPeriod: time.Second,
+		PulsePeriod: 2 * time.Second,
+	}
+}
+
+// ColorblindTheme returns a palette told apart without red-green vision:
+// blue for full and charging, orange for draining and purple for dead
+func ColorblindTheme() Theme {
+	blue := color.RGBA{R: 0, G: 184, B: 255, A: 255}
+	sky := color.RGBA{R: 184, G: 235, B: 255, A: 255}
+	orange := color.RGBA{R: 255, G: 212, B: 0, A: 255}
+	purple := color.RGBA{R: 235, G: 0, B: 255, A: 255}
+
+	t := DefaultTheme()
+	t.Charged = blue
+	t.Countdown = blue
+	t.CountdownEdge = orange
+	t.Draining = orange
+	t.DrainingHigh = sky
+	t.DrainingLow = purple
+	t.Dead = purple
+	t.DeadBrightness = 95
+	t.Charging = sky
+	t.ChargingIndicator = orange
+	t.Idle = colorutil.Scale(orange, 0.5)
+	t.Overheated = color.RGBA{R: 255, G: 255, B: 144, A: 255}
+	t.Unknown = White
+	t.AirLockReady = blue
+	t.AirLockWarning = purple
+	t.AirLockCycle = orange
+	t.Alarm = purple
+	return t
+}
+
+// AccessibleTheme returns ColorblindTheme with a distinct blink rhythm for every
+// state, so states can be told apart without relying on color at all
+func AccessibleTheme() Theme {
+	t := ColorblindTheme()
+	t.Cadences = AccessibleCadences()
+	return t
+}
+
+// ErrUnknownTheme is returned when no built-in theme has the requested name
+var ErrUnknownTheme = errors.New("unknown theme")
+
+// builtinThemes are the themes that can be chosen by name, e.g. from the console
+var builtinThemes = map[string]func() Theme{
+	"default":    DefaultTheme,
+	"colorblind": ColorblindTheme,
+	"accessible": AccessibleTheme,
+}
+
+// BuiltinTheme returns a built-in theme by name
+func BuiltinTheme(name string) (Theme, error) {
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("%w: %s", ErrUnknownTheme, name)
+	}
+	return theme(), nil
+}
+
+// ThemeNames returns the names of the built-in themes
+func ThemeNames() []string {
+	return []string{"default", "colorblind", "accessible"}
+}
+
+// capped returns the theme with every color and brightness limited to MaxBrightness
+func (t Theme) capped() Theme {
+	if t.MaxBrightness == 0 {
+		return t
+	}
+	limit := func(c *color.RGBA) {
+		if brightest := max(c.R, c.G, c.B); brightest > t.MaxBrightness {
+			*c = colorutil.WithPeak(*c, t.MaxBrightness)
+		}
+	}
+	for _, c := range []*color.RGBA{
+		&t.Charged, &t.Countdown, &t.CountdownEdge, &t.Draining, &t.DrainingHigh, &t.DrainingLow, &t.Dead, &t.Charging,
+		&t.ChargingIndicator, &t.Idle, &t.Overheated, &t.Unknown, &t.AirLockReady, &t.AirLockWarning,
+		&t.AirLockCycle, &t.Alarm,
+	} {
+		limit(c)
+	}
+	t.ChargedBrightness = min(t.ChargedBrightness, t.MaxBrightness)
+	t.DeadBrightness = min(t.DeadBrightness, t.MaxBrightness)
+	t.OverheatedBrightness = min(t.OverheatedBrightness, t.MaxBrightness)
+	t.UnknownBrightness = min(t.UnknownBrightness, t.MaxBrightness)
+	return t
+}
+
+// drainingColor returns the color of a draining bar at level (0-100): DrainingHigh
+// down to the high level, shading through Draining to DrainingLow at the low level.
+// Themes without a low level below the high level draw a flat Draining bar.
+func (t Theme) drainingColor(level float32) color.RGBA {
+	if t.DrainingLowLevel >= t.DrainingHighLevel {
+		return t.Draining
+	}
+	shade := (level - t.DrainingLowLevel) / (t.DrainingHighLevel - t.DrainingLowLevel)
+	return colorutil.Palette{t.DrainingLow, t.Draining, t.DrainingHigh}.At(float64(shade))
+}
+
+// SetTheme restyles the panel from the next frame
+func (p *Panel) SetTheme(theme Theme) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.theme = theme.withDefaults().capped()
+	p.fullRedraw = true
+}
+
+// Theme returns the theme the panel draws with
+func (p *Panel) Theme() Theme {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.theme
+}
+
+// withDefaults returns the theme with unset animation periods taken from DefaultTheme
+func (t Theme) withDefaults() Theme {
+	if t == (Theme{}) {
+		return DefaultTheme()
+	}
+	if t.FlashPeriod <= 0 {
+		t.FlashPeriod = DefaultTheme().FlashPeriod
+	}
+	if t.PulsePeriod <= 0 {
+		t.PulsePeriod = DefaultTheme().PulsePeriod
+	}
+	return t
+}
@@ -0,0 +1,24 @@
+package panel
+
+import (
+	"github.com/christophergm/tinyspacewalk/timer"
+)
+
+// GetTimerInfo returns the mission clock, ok is false when the panel has no timer
+func (p *Panel) GetTimerInfo() (info timer.Info, ok bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.timer == nil {
+		return timer.Info{}, false
+	}
+	return p.timer.GetInfo(), true
+}
+
+// renderTimer draws the mission clock on its reserved segment (must be called with mutex locked)
+func (p *Panel) renderTimer() {
+	if p.timer == nil || p.timerSegment.Strip == nil {
+		return
+	}
+	timer.Draw(p.timerSegment, p.timer.GetInfo(), p.flashPhase < 0.5)
+}
@@ -0,0 +1,127 @@
+package panel
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// Transition is a short animation over a battery's section as it enters a new state
+type Transition interface {
+	// Render draws over the section already drawn for the new state, progress runs
+	// from 0.0 to 1.0 over the transition's duration
+	Render(seg Segment, progress float64)
+}
+
+// TransitionFunc adapts a plain function to the Transition interface
+type TransitionFunc func(seg Segment, progress float64)
+
+// Render calls the function
+func (f TransitionFunc) Render(seg Segment, progress float64) {
+	f(seg, progress)
+}
+
+// TransitionEffect is a transition and how long it plays
+type TransitionEffect struct {
+	Transition Transition
+	Duration   time.Duration
+}
+
+// Transitions picks the transition played as a battery enters each state;
+// states without one crossfade or snap as usual
+type Transitions map[battery.SystemState]TransitionEffect
+
+// DefaultTransitions wipes a section to red when its battery dies and sparkles
+// it when the battery reaches Charged
+func DefaultTransitions() Transitions {
+	return Transitions{
+		battery.Dead:    {Transition: WipeTransition(colorutil.WithPeak(Red, 40)), Duration: 800 * time.Millisecond},
+		battery.Charged: {Transition: SparkleTransition(colorutil.WithPeak(White, 60)), Duration: time.Second},
+	}
+}
+
+// activeTransition is a transition playing on a section
+type activeTransition struct {
+	effect    TransitionEffect
+	startedAt time.Time
+}
+
+// startTransition plays the transition for state on a section, returning false
+// when there is none (must be called with mutex locked)
+func (p *Panel) startTransition(batteryIndex int, state battery.SystemState, now time.Time) bool {
+	effect, ok := p.transitions[state]
+	if !ok || effect.Transition == nil || effect.Duration <= 0 {
+		p.playing[batteryIndex] = nil
+		return false
+	}
+	p.playing[batteryIndex] = &activeTransition{effect: effect, startedAt: now}
+	return true
+}
+
+// renderTransition draws a section's running transition over it, dropping it
+// once played (must be called with mutex locked)
+func (p *Panel) renderTransition(batteryIndex int, now time.Time) {
+	active := p.playing[batteryIndex]
+	if active == nil {
+		return
+	}
+	progress := float64(now.Sub(active.startedAt)) / float64(active.effect.Duration)
+	if progress >= 1 {
+		p.playing[batteryIndex] = nil
+		return
+	}
+	active.effect.Transition.Render(p.segments[batteryIndex], progress)
+}
+
+// transitionsPlaying returns whether any section has a transition running (must be called with mutex locked)
+func (p *Panel) transitionsPlaying() bool {
+	for _, active := range p.playing {
+		if active != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// WipeTransition sweeps c down the section from its top over the first half,
+// then fades it out to reveal the new state
+func WipeTransition(c color.RGBA) Transition {
+	return TransitionFunc(func(seg Segment, progress float64) {
+		if progress < 0.5 {
+			front := seg.Length - int(progress*2*float64(seg.Length+1))
+			for i := max(front, 0); i < seg.Length; i++ {
+				seg.setPixel(i, c)
+			}
+			return
+		}
+		for i := 0; i < seg.Length; i++ {
+			seg.setPixel(i, colorutil.Lerp(seg.getPixel(i), c, 2*(1-progress)))
+		}
+	})
+}
+
+// SparkleTransition twinkles pixels of the section in c, thinning out as it ends
+func SparkleTransition(c color.RGBA) Transition {
+	return TransitionFunc(func(seg Segment, progress float64) {
+		// A new set of pixels every twentieth of the transition, the same for every
+		// playback so recordings are repeatable
+		frame := uint32(progress * 20)
+		density := uint32(40 * (1 - progress))
+		for i := 0; i < seg.Length; i++ {
+			if sparkleHash(uint32(i), frame)%100 < density {
+				seg.setPixel(i, c)
+			}
+		}
+	})
+}
+
+// sparkleHash scrambles a pixel and frame into a well mixed number
+func sparkleHash(pixel, frame uint32) uint32 {
+	h := pixel*0x9E3779B1 ^ frame*0x85EBCA77
+	h ^= h >> 15
+	h *= 0x2C1B3C6D
+	h ^= h >> 12
+	return h
+}
@@ -0,0 +1,132 @@
+package patterns
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// BlendMode is how a layer is combined with the layers beneath it
+type BlendMode int
+
+const (
+	// BlendAlpha mixes the layer over the ones beneath by its opacity and each
+	// pixel's alpha; pixels a pattern leaves untouched stay transparent
+	BlendAlpha BlendMode = iota
+	// BlendAdditive adds the layer's light to the ones beneath, saturating at full brightness
+	BlendAdditive
+)
+
+// Layer is one pattern in a Compositor stack
+type Layer struct {
+	Pattern FramePattern
+	Opacity float32 // 0 (invisible) to 1 (opaque)
+	Mode    BlendMode
+}
+
+// Compositor is a FramePattern that stacks other frame patterns, bottom layer
+// first, so scenes like a twinkle background under a moving wave can be built
+// from the simple patterns
+type Compositor struct {
+	mu      sync.Mutex
+	name    string
+	layers  []Layer
+	scratch []color.RGBA // Frame of the layer being blended
+}
+
+// NewCompositor creates a compositor drawing layers from bottom to top
+func NewCompositor(name string, layers ...Layer) *Compositor {
+	return &Compositor{name: name, layers: layers}
+}
+
+func (c *Compositor) Name() string {
+	return c.name
+}
+
+// SetOpacity changes a layer's opacity while the compositor is running
+func (c *Compositor) SetOpacity(layer int, opacity float32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if layer >= 0 && layer < len(c.layers) {
+		c.layers[layer].Opacity = opacity
+	}
+}
+
+// Render draws each layer into a scratch buffer and blends it into buf
+func (c *Compositor) Render(buf []color.RGBA, elapsed time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.scratch) != len(buf) {
+		c.scratch = make([]color.RGBA, len(buf))
+	}
+
+	for _, layer := range c.layers {
+		if layer.Opacity <= 0 {
+			continue
+		}
+		clear(c.scratch)
+		layer.Pattern.Render(c.scratch, elapsed)
+
+		opacity := min(layer.Opacity, 1)
+		for i, src := range c.scratch {
+			buf[i] = blend(buf[i], src, opacity, layer.Mode)
+		}
+	}
+}
+
+// blend combines a source pixel into a destination pixel. Opacity only weights
+// the color mix; the result is always fully opaque since the APA102 driver reads
+// alpha as the pixel's global brightness.
+func blend(dst, src color.RGBA, opacity float32, mode BlendMode) color.RGBA {
+	weight := opacity * float32(src.A) / 255
+	if weight <= 0 {
+		return dst
+	}
+
+	switch mode {
+	case BlendAdditive:
+		return color.RGBA{
+			R: addChannel(dst.R, src.R, weight),
+			G: addChannel(dst.G, src.G, weight),
+			B: addChannel(dst.B, src.B, weight),
+			A: 255,
+		}
+	default:
+		return color.RGBA{
+			R: mixChannel(dst.R, src.R, weight),
+			G: mixChannel(dst.G, src.G, weight),
+			B: mixChannel(dst.B, src.B, weight),
+			A: 255,
+		}
+	}
+}
+
+// mixChannel interpolates from dst towards src by weight
+func mixChannel(dst, src uint8, weight float32) uint8 {
+	return uint8(float32(dst) + (float32(src)-float32(dst))*weight + 0.5)
+}
+
+// addChannel adds src scaled by weight to dst, saturating at 255
+func addChannel(dst, src uint8, weight float32) uint8 {
+	return uint8(min(float32(dst)+float32(src)*weight+0.5, 255))
+}
+
+// renderFunc is a FramePattern backed by a function
+type renderFunc struct {
+	name   string
+	render func(buf []color.RGBA, elapsed time.Duration)
+}
+
+// RenderFunc makes a FramePattern from a function, e.g. a status overlay layer
+func RenderFunc(name string, render func(buf []color.RGBA, elapsed time.Duration)) FramePattern {
+	return &renderFunc{name: name, render: render}
+}
+
+func (f *renderFunc) Name() string {
+	return f.name
+}
+
+func (f *renderFunc) Render(buf []color.RGBA, elapsed time.Duration) {
+	f.render(buf, elapsed)
+}
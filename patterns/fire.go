@@ -0,0 +1,174 @@
+package patterns
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// runSteps drives a frame pattern whose every Render call advances one step,
+// waiting the slider-scaled delay between steps like the other built-in patterns
+func runSteps(p FramePattern, strip peripheral.LedStrip, done <-chan struct{}, delayScale int) error {
+	ticker := time.NewTicker(sliderDelay(delayScale))
+	defer ticker.Stop()
+	sliderMoved, stopWatching := peripheral.WatchSlider()
+	defer stopWatching()
+
+	buf := make([]color.RGBA, strip.NumLEDs())
+	startedAt := time.Now()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-sliderMoved:
+			ticker.Reset(sliderDelay(delayScale))
+		case <-ticker.C:
+			clear(buf)
+			p.Render(buf, time.Since(startedAt))
+			strip.SetBuffer(buf)
+			strip.Show()
+		}
+	}
+}
+
+// FirePalette maps flame heat from cold black through red and orange to hot yellow
+var FirePalette = colorutil.Palette{
+	{R: 0, G: 0, B: 0, A: 255},
+	{R: 103, G: 0, B: 0, A: 255},
+	{R: 119, G: 74, B: 0, A: 255},
+	{R: 125, G: 111, B: 45, A: 255},
+}
+
+// FirePattern simulates flickering flames rising from the start of the strip
+type FirePattern struct {
+	Palette    colorutil.Palette // Colors from cold to hot
+	Cooling    int               // How quickly flames cool, higher gives shorter flames (20-100)
+	Sparking   int               // Percentage chance (0-100) of a new spark each step
+	DelayScale int               // Maximum milliseconds between steps at the slider's top
+	Randomness
+	heat []uint8
+}
+
+// NewFirePattern creates a new fire pattern with default values
+func NewFirePattern() *FirePattern {
+	return &FirePattern{
+		Palette:    FirePalette,
+		Cooling:    55,
+		Sparking:   50,
+		DelayScale: 60,
+		Randomness: newRandomness(),
+	}
+}
+
+func (p *FirePattern) Name() string {
+	return "Fire"
+}
+
+func (p *FirePattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	return runSteps(p, strip, done, p.DelayScale)
+}
+
+// Render advances the heat simulation one step and draws it through the palette
+func (p *FirePattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	numLEDs := len(buf)
+	if numLEDs == 0 {
+		return
+	}
+	if len(p.heat) != numLEDs {
+		p.heat = make([]uint8, numLEDs)
+	}
+
+	// Every cell cools a little
+	maxCooling := min(max(p.Cooling, 0), 255)*10/numLEDs + 2
+	for i := range p.heat {
+		p.heat[i] = uint8(max(int(p.heat[i])-p.Rand.Intn(maxCooling+1), 0))
+	}
+
+	// Heat drifts up and diffuses
+	for i := numLEDs - 1; i >= 2; i-- {
+		p.heat[i] = uint8((int(p.heat[i-1]) + 2*int(p.heat[i-2])) / 3)
+	}
+
+	// Occasionally ignite a new spark near the base
+	if p.Rand.Intn(100) < p.Sparking {
+		i := p.Rand.Intn(min(7, numLEDs))
+		p.heat[i] = uint8(min(int(p.heat[i])+160+p.Rand.Intn(96), 255))
+	}
+
+	for i, heat := range p.heat {
+		buf[i] = p.Palette.At(float64(heat) / 255)
+	}
+}
+
+// MeteorPattern runs a bright head along the strip leaving a randomly fading trail
+type MeteorPattern struct {
+	Palette    colorutil.Palette // Head colors, one per pass along the strip
+	Size       int               // Pixels in the head
+	TrailDecay int               // Percentage (0-100) a trail pixel fades each step
+	DelayScale int               // Maximum milliseconds between steps at the slider's top
+	Randomness
+	trail    []color.RGBA
+	position int
+	pass     int
+}
+
+// NewMeteorPattern creates a new meteor pattern with default values
+func NewMeteorPattern() *MeteorPattern {
+	return &MeteorPattern{
+		Palette: colorutil.Palette{
+			{R: 111, G: 111, B: 119, A: 255},
+			{R: 0, G: 103, B: 119, A: 255},
+			{R: 119, G: 93, B: 0, A: 255},
+		},
+		Size:       3,
+		TrailDecay: 25,
+		DelayScale: 60,
+		Randomness: newRandomness(),
+	}
+}
+
+func (p *MeteorPattern) Name() string {
+	return "Meteor"
+}
+
+func (p *MeteorPattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	return runSteps(p, strip, done, p.DelayScale)
+}
+
+// Render moves the meteor one pixel and fades its trail
+func (p *MeteorPattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	numLEDs := len(buf)
+	if numLEDs == 0 || len(p.Palette) == 0 {
+		return
+	}
+	if len(p.trail) != numLEDs {
+		p.trail = make([]color.RGBA, numLEDs)
+		p.position = 0
+	}
+
+	// Fade trail pixels by random amounts so the tail breaks up like debris
+	for i, c := range p.trail {
+		if p.Rand.Intn(100) < 50 {
+			p.trail[i] = colorutil.Scale(c, 1-float64(p.TrailDecay)/100)
+		}
+	}
+
+	head := p.Palette[p.pass%len(p.Palette)]
+	for i := 0; i < p.Size; i++ {
+		if pos := p.position - i; pos >= 0 && pos < numLEDs {
+			p.trail[pos] = head
+		}
+	}
+
+	// Let the trail run off the end before starting the next pass
+	p.position++
+	if p.position >= numLEDs+numLEDs/2 {
+		p.position = 0
+		p.pass++
+	}
+
+	copy(buf, p.trail)
+}
@@ -0,0 +1,84 @@
+package patterns
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// FramePattern renders one frame at a time into a buffer owned by the caller.
+// Unlike Pattern it has no loop or ticker of its own, so frames can be composed
+// with other patterns or previewed without touching a strip.
+type FramePattern interface {
+	Name() string
+	// Render draws the frame at elapsed time since the pattern started into buf,
+	// which is cleared to black before each call
+	Render(buf []color.RGBA, elapsed time.Duration)
+}
+
+// DefaultFrameRate is the interval between frames rendered by PatternManager
+const DefaultFrameRate = 50 * time.Millisecond
+
+// frameLoop adapts a FramePattern to the legacy Pattern interface by running the
+// render loop on its behalf
+type frameLoop struct {
+	pattern   FramePattern
+	frameRate time.Duration
+}
+
+// AsPattern wraps a FramePattern so it can run anywhere a Pattern is expected
+func AsPattern(pattern FramePattern, frameRate time.Duration) Pattern {
+	if frameRate <= 0 {
+		frameRate = DefaultFrameRate
+	}
+	return &frameLoop{pattern: pattern, frameRate: frameRate}
+}
+
+func (l *frameLoop) Name() string {
+	return l.pattern.Name()
+}
+
+// Seed seeds the wrapped pattern when it has random elements
+func (l *frameLoop) Seed(s int64) {
+	if seeded, ok := l.pattern.(seeder); ok {
+		seeded.Seed(s)
+	}
+}
+
+func (l *frameLoop) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	ticker := time.NewTicker(l.frameRate)
+	defer ticker.Stop()
+
+	buf := make([]color.RGBA, strip.NumLEDs())
+	startedAt := time.Now()
+
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+			clear(buf)
+			l.pattern.Render(buf, time.Since(startedAt))
+			strip.SetBuffer(buf)
+			// Only frames that changed go out, so still patterns leave the bus quiet
+			strip.ShowIfDirty()
+		}
+	}
+}
+
+// SetFrameRate sets the interval between frames for subsequent StartFramePattern calls
+func (pm *PatternManager) SetFrameRate(frameRate time.Duration) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.frameRate = frameRate
+}
+
+// StartFramePattern starts a frame pattern with the manager driving its render loop
+func (pm *PatternManager) StartFramePattern(pattern FramePattern) error {
+	pm.mu.Lock()
+	frameRate := pm.frameRate
+	pm.mu.Unlock()
+
+	return pm.StartPattern(AsPattern(pattern, frameRate))
+}
@@ -1,20 +1,28 @@
 package patterns
 
 import (
+	"errors"
 	"image/color"
-	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/christophergm/tinyspacewalk/battery"
 	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/recovery"
 )
 
 // Pattern represents a LED pattern that can be started and stopped
 type Pattern interface {
-	Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error
+	Start(strip peripheral.LedStrip, done <-chan struct{}) error
 	Name() string
 }
 
+// sliderDelay is the delay between steps for a pattern's delay scale (ms at the
+// top of the slider), never so short the ticker can't keep up
+func sliderDelay(delayScale int) time.Duration {
+	return max(time.Duration(peripheral.ReadSliderInputScaled(delayScale))*time.Millisecond, 10*time.Millisecond)
+}
+
 // PanelStatus represents the status of a solar panel for battery pattern
 type PanelStatus struct {
 	Status int
@@ -40,7 +48,7 @@ func NewBatteryPattern() *BatteryPattern {
 	bat.SetIsDraining(true) // Start draining
 
 	return &BatteryPattern{
-		BackgroundColor:  color.RGBA{R: 50, G: 50, B: 0, A: 255},
+		BackgroundColor:  color.RGBA{R: 143, G: 143, B: 0, A: 255},
 		PanelGapPixels:   3,
 		PanelWidthPixels: 20,
 		Battery:          bat,
@@ -52,9 +60,11 @@ func (p *BatteryPattern) Name() string {
 	return "Battery"
 }
 
-func (p *BatteryPattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error {
-	ticker := time.NewTicker(time.Duration(peripheral.ReadAnalogInputAsDelay(p.DelayScale)) * time.Millisecond)
+func (p *BatteryPattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	ticker := time.NewTicker(sliderDelay(p.DelayScale))
 	defer ticker.Stop()
+	sliderMoved, stopWatching := peripheral.WatchSlider()
+	defer stopWatching()
 
 	numPanels := 5
 
@@ -62,6 +72,8 @@ func (p *BatteryPattern) Start(strip *peripheral.ColorLedStrip, done <-chan stru
 		select {
 		case <-done:
 			return nil
+		case <-sliderMoved:
+			ticker.Reset(sliderDelay(p.DelayScale))
 		case <-ticker.C:
 			// Clear buffer with background color
 			strip.SetAll(p.BackgroundColor)
@@ -78,27 +90,29 @@ func (p *BatteryPattern) Start(strip *peripheral.ColorLedStrip, done <-chan stru
 			case battery.Charged:
 				panelColor = color.RGBA{R: 0, G: 255, B: 0, A: 255} // Green
 			case battery.Charging:
-				panelColor = color.RGBA{R: 0, G: 100, B: 255, A: 255} // Blue
+				panelColor = color.RGBA{R: 0, G: 183, B: 255, A: 255} // Blue
 			case battery.Draining:
 				if batteryInfo.BatteryLevel > 50 {
-					panelColor = color.RGBA{R: 0, G: 200, B: 0, A: 255} // Green
+					panelColor = color.RGBA{R: 0, G: 234, B: 0, A: 255} // Green
 				} else if batteryInfo.BatteryLevel > 20 {
-					panelColor = color.RGBA{R: 255, G: 200, B: 0, A: 255} // Orange
+					panelColor = color.RGBA{R: 255, G: 234, B: 0, A: 255} // Orange
 				} else {
 					panelColor = color.RGBA{R: 255, G: 0, B: 0, A: 255} // Red
 				}
 			case battery.Disconnecting:
-				panelColor = color.RGBA{R: 100, G: 0, B: 0, A: 255} // Dark red
+				panelColor = color.RGBA{R: 183, G: 0, B: 0, A: 255} // Dark red
 			case battery.Dead:
-				panelColor = color.RGBA{R: 50, G: 0, B: 0, A: 255} // Very dark red
+				panelColor = color.RGBA{R: 143, G: 0, B: 0, A: 255} // Very dark red
+			case battery.Idle:
+				panelColor = color.RGBA{R: 183, G: 169, B: 0, A: 255} // Dim yellow
 			default:
-				panelColor = color.RGBA{R: 50, G: 50, B: 50, A: 255} // Gray
+				panelColor = color.RGBA{R: 143, G: 143, B: 143, A: 255} // Gray
 			}
 
 			// Draw panels
 			for i := 0; i < numPanels; i++ {
 				var currentPanelColor color.RGBA
-				if i < activePanels {
+				if float32(i) < activePanels {
 					currentPanelColor = panelColor
 				} else {
 					// Dim color for inactive panels
@@ -125,7 +139,6 @@ func (p *BatteryPattern) Start(strip *peripheral.ColorLedStrip, done <-chan stru
 			}
 
 			strip.Show()
-			ticker.Reset(time.Duration(peripheral.ReadAnalogInputAsDelay(p.DelayScale)) * time.Millisecond)
 		}
 	}
 }
@@ -136,22 +149,23 @@ type SpinPattern struct {
 	TwinkleColor  color.RGBA
 	TwinkleChance int
 	DelayScale    int
-	position      int
-	tailLength    int
+	Randomness
+	position   int
+	tailLength int // Rolled on the first start
 }
 
 // NewSpinPattern creates a new spin pattern with default values
 func NewSpinPattern() *SpinPattern {
 	return &SpinPattern{
 		TailColors: []color.RGBA{
-			{R: 50, G: 50, B: 0, A: 255},  // Tail segment 1
-			{R: 90, G: 10, B: 50, A: 255}, // Tail segment 2
-			{R: 10, G: 0, B: 100, A: 255}, // Tail segment 3
+			{R: 143, G: 143, B: 0, A: 255},  // Tail segment 1
+			{R: 176, G: 80, B: 143, A: 255}, // Tail segment 2
+			{R: 80, G: 0, B: 183, A: 255},   // Tail segment 3
 		},
-		TwinkleColor:  color.RGBA{R: 0, G: 50, B: 0, A: 255},
+		TwinkleColor:  color.RGBA{R: 0, G: 143, B: 0, A: 255},
 		TwinkleChance: 8, // Out of 10
 		DelayScale:    500,
-		tailLength:    rand.Intn(100) + 1,
+		Randomness:    newRandomness(),
 	}
 }
 
@@ -159,14 +173,22 @@ func (p *SpinPattern) Name() string {
 	return "Spin"
 }
 
-func (p *SpinPattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error {
-	ticker := time.NewTicker(time.Duration(peripheral.ReadSliderInputScaled(p.DelayScale)) * time.Millisecond)
+func (p *SpinPattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	if p.tailLength == 0 {
+		p.tailLength = p.Rand.Intn(100) + 1
+	}
+
+	ticker := time.NewTicker(sliderDelay(p.DelayScale))
 	defer ticker.Stop()
+	sliderMoved, stopWatching := peripheral.WatchSlider()
+	defer stopWatching()
 
 	for {
 		select {
 		case <-done:
 			return nil
+		case <-sliderMoved:
+			ticker.Reset(sliderDelay(p.DelayScale))
 		case <-ticker.C:
 			for i := 0; i < strip.NumLEDs(); i++ {
 				var col color.RGBA
@@ -179,7 +201,7 @@ func (p *SpinPattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{
 					col = p.TailColors[2]
 				} else {
 					// Background with occasional twinkle
-					if rand.Intn(10) > p.TwinkleChance {
+					if p.Rand.Intn(10) > p.TwinkleChance {
 						col = p.TwinkleColor
 					} else {
 						col = color.RGBA{R: 0, G: 0, B: 0, A: 255}
@@ -192,7 +214,6 @@ func (p *SpinPattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{
 
 			strip.Show()
 			p.position++
-			ticker.Reset(time.Duration(peripheral.ReadSliderInputScaled(p.DelayScale)) * time.Millisecond)
 		}
 	}
 }
@@ -203,15 +224,19 @@ type TwinklePattern struct {
 	TwinkleColor    color.RGBA
 	TwinkleChance   int // Percentage chance (0-100)
 	DelayScale      int
+	Randomness
+	twinkles []bool        // Lit pixels of the current frame when rendered
+	lastRoll time.Duration // Elapsed time the twinkles were last chosen
 }
 
 // NewTwinklePattern creates a new twinkle pattern with default values
 func NewTwinklePattern() *TwinklePattern {
 	return &TwinklePattern{
-		BackgroundColor: color.RGBA{R: 10, G: 10, B: 0, A: 255},
-		TwinkleColor:    color.RGBA{R: 0, G: 70, B: 0, A: 255},
+		BackgroundColor: color.RGBA{R: 80, G: 80, B: 0, A: 255},
+		TwinkleColor:    color.RGBA{R: 0, G: 161, B: 0, A: 255},
 		TwinkleChance:   20,
 		DelayScale:      1000,
+		Randomness:      newRandomness(),
 	}
 }
 
@@ -219,17 +244,21 @@ func (p *TwinklePattern) Name() string {
 	return "Twinkle"
 }
 
-func (p *TwinklePattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error {
-	ticker := time.NewTicker(time.Duration(peripheral.ReadSliderInputScaled(p.DelayScale)) * time.Millisecond)
+func (p *TwinklePattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	ticker := time.NewTicker(sliderDelay(p.DelayScale))
 	defer ticker.Stop()
+	sliderMoved, stopWatching := peripheral.WatchSlider()
+	defer stopWatching()
 
 	for {
 		select {
 		case <-done:
 			return nil
+		case <-sliderMoved:
+			ticker.Reset(sliderDelay(p.DelayScale))
 		case <-ticker.C:
 			for i := 0; i < strip.NumLEDs(); i++ {
-				if rand.Intn(100) < p.TwinkleChance {
+				if p.Rand.Intn(100) < p.TwinkleChance {
 					strip.SetPixel(i, p.TwinkleColor)
 				} else {
 					strip.SetPixel(i, p.BackgroundColor)
@@ -237,17 +266,40 @@ func (p *TwinklePattern) Start(strip *peripheral.ColorLedStrip, done <-chan stru
 			}
 
 			strip.Show()
-			ticker.Reset(time.Duration(peripheral.ReadSliderInputScaled(p.DelayScale)) * time.Millisecond)
+		}
+	}
+}
+
+// Render draws the twinkles, choosing new ones at the slider-controlled interval
+func (p *TwinklePattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	interval := time.Duration(peripheral.ReadSliderInputScaled(p.DelayScale)) * time.Millisecond
+	if len(p.twinkles) != len(buf) || elapsed < p.lastRoll || elapsed-p.lastRoll >= interval {
+		if len(p.twinkles) != len(buf) {
+			p.twinkles = make([]bool, len(buf))
+		}
+		for i := range p.twinkles {
+			p.twinkles[i] = p.Rand.Intn(100) < p.TwinkleChance
+		}
+		p.lastRoll = elapsed
+	}
+
+	for i, lit := range p.twinkles {
+		if lit {
+			buf[i] = p.TwinkleColor
+		} else {
+			buf[i] = p.BackgroundColor
 		}
 	}
 }
 
 // ExplodePattern creates an explosion effect radiating from a center point
 type ExplodePattern struct {
-	CenterPosition int
+	CenterPosition int // Negative centers the explosion on the strip
 	MaxMagnitude   int
 	Iterations     int
 	IterationDelay time.Duration
+	OnComplete     func() // Called once the effect has played all iterations, not when stopped early; start the next pattern in a new goroutine
+	Randomness
 }
 
 // NewExplodePattern creates a new explode pattern with default values
@@ -257,6 +309,7 @@ func NewExplodePattern(centerPosition int) *ExplodePattern {
 		MaxMagnitude:   10,
 		Iterations:     10,
 		IterationDelay: 20 * time.Millisecond,
+		Randomness:     newRandomness(),
 	}
 }
 
@@ -264,85 +317,301 @@ func (p *ExplodePattern) Name() string {
 	return "Explode"
 }
 
-func (p *ExplodePattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error {
-	for j := 0; j < p.Iterations; j++ {
+func (p *ExplodePattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	if p.CenterPosition < 0 {
+		p.CenterPosition = strip.NumLEDs() / 2
+	}
+
+	ticker := time.NewTicker(p.IterationDelay)
+	defer ticker.Stop()
+
+	// Draw the first frame immediately, then one per tick
+	iteration := 0
+	p.drawFrame(strip, iteration)
+
+	for {
 		select {
 		case <-done:
 			return nil
-		default:
-			for i := 0; i < strip.NumLEDs(); i++ {
-				distance := (p.CenterPosition - i) % strip.NumLEDs()
-				magnitude := 3 * (strip.NumLEDs() - distance) / strip.NumLEDs()
-				magnitude = magnitude + rand.Intn(9) - j
-
-				if magnitude < 0 {
-					magnitude = 0
-				}
-
-				col := color.RGBA{
-					R: uint8(3 * magnitude),
-					G: uint8(2 * magnitude),
-					B: uint8(magnitude),
-					A: 255,
+		case <-ticker.C:
+			iteration++
+			if iteration >= p.Iterations {
+				if p.OnComplete != nil {
+					p.OnComplete()
 				}
-				strip.SetPixel(i, col)
+				return nil
 			}
+			p.drawFrame(strip, iteration)
+		}
+	}
+}
 
-			strip.Show()
-			time.Sleep(p.IterationDelay)
+// drawFrame renders a single iteration of the explosion
+func (p *ExplodePattern) drawFrame(strip peripheral.LedStrip, iteration int) {
+	for i := 0; i < strip.NumLEDs(); i++ {
+		distance := (p.CenterPosition - i) % strip.NumLEDs()
+		magnitude := 3 * (strip.NumLEDs() - distance) / strip.NumLEDs()
+		magnitude = magnitude + p.Rand.Intn(9) - iteration
+
+		if magnitude < 0 {
+			magnitude = 0
 		}
+
+		col := color.RGBA{
+			R: uint8(11 * magnitude),
+			G: uint8(9 * magnitude),
+			B: uint8(7 * magnitude),
+			A: 255,
+		}
+		strip.SetPixel(i, col)
 	}
-	return nil
+
+	strip.Show()
+}
+
+// NightLightPattern renders a very dim, slowly drifting starfield for overnight
+// operation. Only a few pixels are lit at a time so the prop stays visible while
+// drawing minimal current, and the strip is only written when the stars move.
+type NightLightPattern struct {
+	StarColor    color.RGBA
+	MaxStars     int           // Maximum number of pixels lit at once
+	RespawnOdds  int           // Percentage chance (0-100) a star is replaced each update
+	ShowInterval time.Duration // Time between star movements
+	Randomness
+	stars       []int
+	steps       int           // Star movements made so far
+	lastElapsed time.Duration // Elapsed time of the previous frame
+}
+
+// NewNightLightPattern creates a new night light pattern with default values
+func NewNightLightPattern() *NightLightPattern {
+	return &NightLightPattern{
+		StarColor:    color.RGBA{R: 35, G: 35, B: 45, A: 255},
+		MaxStars:     6,
+		RespawnOdds:  10,
+		ShowInterval: 2 * time.Second,
+		Randomness:   newRandomness(),
+	}
+}
+
+func (p *NightLightPattern) Name() string {
+	return "NightLight"
 }
 
-// PatternManager manages multiple patterns and provides control functionality
+// Render draws the starfield, drifting the stars once per ShowInterval
+func (p *NightLightPattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	numLEDs := len(buf)
+	if numLEDs == 0 {
+		return
+	}
+
+	// Scatter the stars on the first frame of a run
+	if elapsed < p.lastElapsed || len(p.stars) == 0 {
+		p.stars = p.stars[:0]
+		p.steps = 0
+		for i := 0; i < p.MaxStars; i++ {
+			p.stars = append(p.stars, p.Rand.Intn(numLEDs))
+		}
+	}
+
+	for ; p.ShowInterval > 0 && p.steps < int(elapsed/p.ShowInterval); p.steps++ {
+		for i, pos := range p.stars {
+			// Drift each star one pixel, occasionally replacing it elsewhere
+			if p.Rand.Intn(100) < p.RespawnOdds {
+				pos = p.Rand.Intn(numLEDs)
+			} else {
+				pos = (pos + 1) % numLEDs
+			}
+			p.stars[i] = pos
+		}
+	}
+
+	p.lastElapsed = elapsed
+
+	for _, pos := range p.stars {
+		buf[pos%numLEDs] = p.StarColor
+	}
+}
+
+// ErrStripOwned is returned by StartPattern when the previous pattern has not
+// released the strip within StopTimeout
+var ErrStripOwned = errors.New("patterns: strip still owned by previous pattern")
+
+// StopTimeout is how long StartPattern waits for the previous pattern to exit
+const StopTimeout = time.Second
+
+// PatternManager manages multiple patterns and provides control functionality.
+// It is safe for concurrent use, but must not be called from a pattern's own
+// goroutine since it waits for patterns to exit.
 type PatternManager struct {
-	strip          *peripheral.ColorLedStrip
+	mu             sync.Mutex
+	wg             sync.WaitGroup // Pattern and transition goroutines
+	strip          peripheral.LedStrip
 	currentPattern Pattern
 	stopChan       chan struct{}
 	running        bool
+
+	// Transitions between patterns; when enabled each pattern renders to an
+	// off-screen strip that is forwarded to the real strip while live
+	transition     Transition
+	currentStrip   peripheral.LedStrip
+	currentWriter  *forwardWriter
+	stopTransition chan struct{}
+	transitionDone chan struct{} // Closed when the latest transition goroutine exits
+
+	// Interval between frames of patterns started with StartFramePattern
+	frameRate time.Duration
 }
 
 // NewPatternManager creates a new pattern manager
-func NewPatternManager(strip *peripheral.ColorLedStrip) *PatternManager {
+func NewPatternManager(strip peripheral.LedStrip) *PatternManager {
 	return &PatternManager{
-		strip: strip,
+		strip:     strip,
+		frameRate: DefaultFrameRate,
 	}
 }
 
-// StartPattern starts a new pattern, stopping any currently running pattern
+// SetTransition sets how subsequent StartPattern calls switch from the running pattern
+func (pm *PatternManager) SetTransition(t Transition) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.transition = t
+}
+
+// StartPattern starts a new pattern, handing over from any currently running
+// pattern using the configured transition. Without a transition the previous
+// pattern must exit first; ErrStripOwned is returned if it doesn't in time.
 func (pm *PatternManager) StartPattern(pattern Pattern) error {
-	pm.StopPattern()
+	pm.mu.Lock()
+	useTransition := pm.transition.Kind != Cut && pm.transition.Duration > 0
+	canBlend := useTransition && pm.running && pm.currentWriter != nil
+
+	if !canBlend {
+		// Nothing to blend from, so the previous pattern must release the strip first
+		pm.signalStop()
+		pm.mu.Unlock()
+		if !waitTimeout(&pm.wg, StopTimeout) {
+			return ErrStripOwned
+		}
+		pm.mu.Lock()
+		if pm.running {
+			// Another StartPattern claimed the strip while we waited
+			pm.mu.Unlock()
+			return ErrStripOwned
+		}
+	}
+	defer pm.mu.Unlock()
 
 	pm.currentPattern = pattern
-	pm.stopChan = make(chan struct{})
+	if !useTransition {
+		pm.currentStrip, pm.currentWriter = nil, nil
+		pm.stopChan = pm.launch(pattern, pm.strip)
+		return nil
+	}
+
+	incoming, incomingWriter := newOffscreenStrip(pm.strip, !canBlend)
+	if canBlend {
+		// Abort a transition still in progress; its outgoing pattern stops immediately.
+		// Wait for it to exit so two transitions never write the strip at once.
+		if pm.stopTransition != nil {
+			close(pm.stopTransition)
+			<-pm.transitionDone
+		}
+		pm.currentWriter.live.Store(false)
+		pm.stopTransition = make(chan struct{})
+		pm.transitionDone = make(chan struct{})
+
+		pm.wg.Add(1)
+		go func(t Transition, outgoingStop chan struct{}, outgoing peripheral.LedStrip, abort, done chan struct{}) {
+			defer pm.wg.Done()
+			defer close(done)
+			pm.runTransition(t, outgoingStop, outgoing, incoming, incomingWriter, abort)
+		}(pm.transition, pm.stopChan, pm.currentStrip, pm.stopTransition, pm.transitionDone)
+	}
+
+	pm.currentStrip, pm.currentWriter = incoming, incomingWriter
+	pm.stopChan = pm.launch(pattern, incoming)
+	return nil
+}
+
+// launch runs a pattern on strip in its own goroutine and returns its stop channel
+// (must be called with mutex locked)
+func (pm *PatternManager) launch(pattern Pattern, strip peripheral.LedStrip) chan struct{} {
+	stop := make(chan struct{})
 	pm.running = true
 
+	pm.wg.Add(1)
 	go func() {
+		defer pm.wg.Done()
 		defer func() {
-			pm.running = false
+			// Only the current pattern exiting clears running, not an outgoing one
+			pm.mu.Lock()
+			if pm.stopChan == stop {
+				pm.running = false
+			}
+			pm.mu.Unlock()
 		}()
-		pattern.Start(pm.strip, pm.stopChan)
+		runRecovering(pattern, strip, stop)
 	}()
 
-	return nil
+	return stop
 }
 
-// StopPattern stops the currently running pattern
-func (pm *PatternManager) StopPattern() {
+// patternRestartDelay is how long a panicked pattern waits before restarting
+const patternRestartDelay = time.Second
+
+// runRecovering runs a pattern until it returns or stop is closed, restarting it
+// after a short delay if it panics
+func runRecovering(pattern Pattern, strip peripheral.LedStrip, stop chan struct{}) {
+	for {
+		err := recovery.Call("pattern "+pattern.Name(), func() {
+			pattern.Start(strip, stop)
+		})
+		if err == nil {
+			return
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(patternRestartDelay):
+		}
+	}
+}
+
+// signalStop tells the current pattern and any transition to exit without
+// waiting for them (must be called with mutex locked)
+func (pm *PatternManager) signalStop() {
+	if pm.stopTransition != nil {
+		close(pm.stopTransition)
+		pm.stopTransition = nil
+	}
 	if pm.running && pm.stopChan != nil {
 		close(pm.stopChan)
 		pm.running = false
 	}
 }
 
+// StopPattern stops the currently running pattern and blocks until it has exited
+func (pm *PatternManager) StopPattern() {
+	pm.mu.Lock()
+	pm.signalStop()
+	pm.mu.Unlock()
+
+	pm.wg.Wait()
+}
+
 // IsRunning returns whether a pattern is currently running
 func (pm *PatternManager) IsRunning() bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	return pm.running
 }
 
 // CurrentPattern returns the currently running pattern
 func (pm *PatternManager) CurrentPattern() Pattern {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
 	return pm.currentPattern
 }
 
@@ -353,6 +622,25 @@ func (pm *PatternManager) ClearStrip() {
 	pm.strip.Show()
 }
 
+// waitTimeout waits for wg, returning false if timeout passes first
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
 // WavePattern creates a wave effect that moves around the strip using SetBufferAt
 type WavePattern struct {
 	WaveColors []color.RGBA
@@ -365,14 +653,14 @@ type WavePattern struct {
 func NewWavePattern() *WavePattern {
 	return &WavePattern{
 		WaveColors: []color.RGBA{
-			{R: 0, G: 0, B: 1, A: 255}, // Dark blue
-			{R: 0, G: 5, B: 1, A: 255}, // Medium blue
-			{R: 0, G: 1, B: 1, A: 255}, // Bright blue
-			{R: 0, G: 0, B: 0, A: 255}, // Light blue
-			{R: 1, G: 1, B: 1, A: 255}, // Very light blue
-			{R: 1, G: 0, B: 0, A: 255}, // Light blue
-			{R: 0, G: 5, B: 5, A: 255}, // Bright blue
-			{R: 0, G: 5, B: 5, A: 255}, // Medium blue
+			{R: 0, G: 0, B: 35, A: 255},   // Dark blue
+			{R: 0, G: 63, B: 35, A: 255},  // Medium blue
+			{R: 0, G: 35, B: 35, A: 255},  // Bright blue
+			{R: 0, G: 0, B: 0, A: 255},    // Light blue
+			{R: 35, G: 35, B: 35, A: 255}, // Very light blue
+			{R: 35, G: 0, B: 0, A: 255},   // Light blue
+			{R: 0, G: 63, B: 63, A: 255},  // Bright blue
+			{R: 0, G: 63, B: 63, A: 255},  // Medium blue
 		},
 		WaveLength: 8,
 		Speed:      100,
@@ -384,14 +672,27 @@ func (p *WavePattern) Name() string {
 	return "Wave"
 }
 
-func (p *WavePattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{}) error {
-	ticker := time.NewTicker(time.Duration(p.Speed) * time.Millisecond)
+func (p *WavePattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	// Speed based on analog input (inverted for more responsive control)
+	delay := func() time.Duration {
+		analogValue := peripheral.ReadSliderInputPercentage()
+		newSpeed := (p.Speed * (100 - analogValue)) / 100
+		if newSpeed < 10 {
+			newSpeed = 10 // Minimum speed
+		}
+		return time.Duration(newSpeed) * time.Millisecond
+	}
+	ticker := time.NewTicker(delay())
 	defer ticker.Stop()
+	sliderMoved, stopWatching := peripheral.WatchSlider()
+	defer stopWatching()
 
 	for {
 		select {
 		case <-done:
 			return nil
+		case <-sliderMoved:
+			ticker.Reset(delay())
 		case <-ticker.C:
 			// Clear the strip
 			strip.Clear()
@@ -404,14 +705,17 @@ func (p *WavePattern) Start(strip *peripheral.ColorLedStrip, done <-chan struct{
 
 			// Move the wave position
 			p.position = (p.position + 1) % strip.NumLEDs()
-
-			// Adjust speed based on analog input (inverted for more responsive control)
-			analogValue := peripheral.ReadSliderInputPercentage()
-			newSpeed := (p.Speed * (100 - analogValue)) / 100
-			if newSpeed < 10 {
-				newSpeed = 10 // Minimum speed
-			}
-			ticker.Reset(time.Duration(newSpeed) * time.Millisecond)
 		}
 	}
 }
+
+// Render draws the wave at the position reached after elapsed, moving one pixel every Speed milliseconds
+func (p *WavePattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	if len(buf) == 0 || p.Speed <= 0 {
+		return
+	}
+	position := int(elapsed/(time.Duration(p.Speed)*time.Millisecond)) % len(buf)
+	for i, c := range p.WaveColors {
+		buf[(position+i)%len(buf)] = c
+	}
+}
@@ -0,0 +1,117 @@
+package patterns
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// sliderSpeed scales a nominal speed by the slider, from a tenth of it at the
+// bottom through nominal at the middle to double at the top
+func sliderSpeed(nominal float64) float64 {
+	return nominal * max(float64(peripheral.ReadSliderInputPercentage())/50, 0.1)
+}
+
+// phaseAdvance returns the time since the previous frame, restarting the phase
+// when elapsed goes backwards because the pattern was started again
+func phaseAdvance(lastElapsed *time.Duration, elapsed time.Duration) (time.Duration, bool) {
+	restarted := elapsed < *lastElapsed
+	dt := elapsed - *lastElapsed
+	if restarted {
+		dt = elapsed
+	}
+	*lastElapsed = elapsed
+	return dt, restarted
+}
+
+// RainbowPattern cycles a full hue wheel along the strip
+type RainbowPattern struct {
+	Saturation       float64 // 0.0 to 1.0
+	Value            float64 // Brightness, 0.0 to 1.0
+	Repeats          float64 // Hue wheels across the strip
+	DegreesPerSecond float64 // Hue rotation at the slider's middle position
+	hue              float64
+	lastElapsed      time.Duration
+}
+
+// NewRainbowPattern creates a new rainbow pattern with default values
+func NewRainbowPattern() *RainbowPattern {
+	return &RainbowPattern{
+		Saturation:       1,
+		Value:            0.45,
+		Repeats:          1,
+		DegreesPerSecond: 60,
+	}
+}
+
+func (p *RainbowPattern) Name() string {
+	return "Rainbow"
+}
+
+// Render draws the hue wheel, rotating it by the slider-scaled speed
+func (p *RainbowPattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	dt, restarted := phaseAdvance(&p.lastElapsed, elapsed)
+	if restarted {
+		p.hue = 0
+	}
+	p.hue += sliderSpeed(p.DegreesPerSecond) * dt.Seconds()
+
+	for i := range buf {
+		offset := 360 * p.Repeats * float64(i) / float64(len(buf))
+		buf[i] = colorutil.FromHSV(p.hue+offset, p.Saturation, p.Value)
+	}
+}
+
+// GradientPattern sweeps a multi-stop gradient along the strip
+type GradientPattern struct {
+	Stops         colorutil.Palette
+	LEDsPerSecond float64 // Sweep speed at the slider's middle position
+	Mirror        bool    // Run the gradient out and back so the sweep has no seam
+	offset        float64
+	lastElapsed   time.Duration
+}
+
+// NewGradientPattern creates a gradient sweep through stops
+func NewGradientPattern(stops ...color.RGBA) *GradientPattern {
+	if len(stops) == 0 {
+		stops = colorutil.SpacePalette
+	}
+	return &GradientPattern{
+		Stops:         stops,
+		LEDsPerSecond: 10,
+		Mirror:        true,
+	}
+}
+
+func (p *GradientPattern) Name() string {
+	return "Gradient"
+}
+
+// Render draws the gradient, shifting it along the strip by the slider-scaled speed
+func (p *GradientPattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	numLEDs := len(buf)
+	if numLEDs == 0 {
+		return
+	}
+
+	dt, restarted := phaseAdvance(&p.lastElapsed, elapsed)
+	if restarted {
+		p.offset = 0
+	}
+	p.offset += sliderSpeed(p.LEDsPerSecond) * dt.Seconds()
+
+	for i := range buf {
+		t := (float64(i) + p.offset) / float64(numLEDs)
+		t -= float64(int(t))
+		if p.Mirror {
+			// Triangle wave so the end of the gradient meets its start
+			t *= 2
+			if t > 1 {
+				t = 2 - t
+			}
+		}
+		buf[i] = p.Stops.At(t)
+	}
+}
@@ -0,0 +1,52 @@
+package patterns
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+var (
+	seedMu sync.Mutex
+	seed   int64 // Seed for patterns created from now on, 0 seeds each from the clock
+)
+
+// SetSeed makes every pattern created afterwards start from the same random
+// sequence, so visual tests and recordings are repeatable; 0 goes back to
+// seeding each pattern from the clock
+func SetSeed(s int64) {
+	seedMu.Lock()
+	defer seedMu.Unlock()
+	seed = s
+}
+
+// NewRand returns a random source seeded with s, or from the clock if s is 0
+func NewRand(s int64) *rand.Rand {
+	if s == 0 {
+		s = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(s))
+}
+
+// Randomness gives a pattern with random elements its own random source, so one
+// pattern's draws don't disturb another's sequence
+type Randomness struct {
+	Rand *rand.Rand // Random source, only used from the pattern's goroutine
+}
+
+// newRandomness returns a source seeded by SetSeed, or from the clock
+func newRandomness() Randomness {
+	seedMu.Lock()
+	defer seedMu.Unlock()
+	return Randomness{Rand: NewRand(seed)}
+}
+
+// Seed restarts the pattern's random sequence from s, e.g. the "seed" parameter
+func (r *Randomness) Seed(s int64) {
+	r.Rand = NewRand(s)
+}
+
+// seeder is a pattern whose random sequence can be seeded
+type seeder interface {
+	Seed(s int64)
+}
@@ -0,0 +1,259 @@
+package patterns
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrUnknownPattern is returned by Create for names that aren't registered
+var ErrUnknownPattern = errors.New("patterns: unknown pattern")
+
+// Params tunes a pattern created by name. Values may be numbers or strings,
+// so they can come straight from a console line or URL query.
+type Params map[string]any
+
+// Factory creates a pattern from its parameters
+type Factory func(params Params) (Pattern, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register adds or replaces a named pattern factory
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = factory
+}
+
+// Create instantiates a registered pattern, applying params over its defaults.
+// A "seed" param makes a pattern with random elements repeat the same run.
+func Create(name string, params map[string]any) (Pattern, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownPattern, name)
+	}
+	pattern, err := factory(params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Any pattern with random elements takes a seed for a repeatable run
+	if _, ok := params["seed"]; ok {
+		if s, ok := pattern.(seeder); ok {
+			r := Params(params).Reader()
+			s.Seed(int64(r.Int("seed", 0)))
+			if err := r.Err(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return pattern, nil
+}
+
+// Names returns the registered pattern names in alphabetical order
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParamReader reads typed values from Params, keeping the first error so a
+// factory can read every field and check once
+type ParamReader struct {
+	params Params
+	err    error
+}
+
+// Reader returns a ParamReader over the params
+func (p Params) Reader() *ParamReader {
+	return &ParamReader{params: p}
+}
+
+// Err returns the first conversion error
+func (r *ParamReader) Err() error {
+	return r.err
+}
+
+// Int reads an integer, returning def when the key is absent or invalid
+func (r *ParamReader) Int(key string, def int) int {
+	switch v := r.params[key].(type) {
+	case nil:
+		return def
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		n, err := strconv.Atoi(v)
+		if err == nil {
+			return n
+		}
+	}
+	r.fail(key)
+	return def
+}
+
+// IntRange reads an integer like Int, rejecting values outside lo..hi
+func (r *ParamReader) IntRange(key string, def, lo, hi int) int {
+	n := r.Int(key, def)
+	if n < lo || n > hi {
+		r.failRange(key, lo, hi)
+		return def
+	}
+	return n
+}
+
+// Float reads a number, returning def when the key is absent or invalid
+func (r *ParamReader) Float(key string, def float64) float64 {
+	switch v := r.params[key].(type) {
+	case nil:
+		return def
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			return f
+		}
+	}
+	r.fail(key)
+	return def
+}
+
+// Duration reads a duration given as a time.Duration, a string like "250ms",
+// or a number of milliseconds, returning def when the key is absent or invalid
+func (r *ParamReader) Duration(key string, def time.Duration) time.Duration {
+	switch v := r.params[key].(type) {
+	case nil:
+		return def
+	case time.Duration:
+		return v
+	case int:
+		return time.Duration(v) * time.Millisecond
+	case float64:
+		return time.Duration(v * float64(time.Millisecond))
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			return d
+		}
+		if ms, err := strconv.Atoi(v); err == nil {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	r.fail(key)
+	return def
+}
+
+// fail records an invalid value for key
+func (r *ParamReader) fail(key string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("patterns: invalid value for %s: %v", key, r.params[key])
+	}
+}
+
+// failRange records a value for key outside lo..hi
+func (r *ParamReader) failRange(key string, lo, hi int) {
+	if r.err == nil {
+		r.err = fmt.Errorf("patterns: %s must be %d-%d, got %v", key, lo, hi, r.params[key])
+	}
+}
+
+// init registers the built-in patterns
+func init() {
+	Register("spin", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewSpinPattern()
+		p.DelayScale = r.Int("delay", p.DelayScale)
+		return p, r.Err()
+	})
+	Register("twinkle", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewTwinklePattern()
+		p.TwinkleChance = r.IntRange("chance", p.TwinkleChance, 0, 100)
+		p.DelayScale = r.Int("delay", p.DelayScale)
+		return p, r.Err()
+	})
+	Register("wave", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewWavePattern()
+		p.Speed = r.Int("speed", p.Speed)
+		return p, r.Err()
+	})
+	Register("explode", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewExplodePattern(r.Int("center", -1))
+		p.Iterations = r.Int("iterations", p.Iterations)
+		return p, r.Err()
+	})
+	Register("fire", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewFirePattern()
+		p.Cooling = r.IntRange("cooling", p.Cooling, 0, 255)
+		p.Sparking = r.IntRange("sparking", p.Sparking, 0, 100)
+		p.DelayScale = r.Int("delay", p.DelayScale)
+		return p, r.Err()
+	})
+	Register("meteor", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewMeteorPattern()
+		p.Size = r.Int("size", p.Size)
+		p.TrailDecay = r.IntRange("decay", p.TrailDecay, 0, 100)
+		p.DelayScale = r.Int("delay", p.DelayScale)
+		return p, r.Err()
+	})
+	Register("night", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewNightLightPattern()
+		p.MaxStars = r.Int("stars", p.MaxStars)
+		p.ShowInterval = r.Duration("interval", p.ShowInterval)
+		return AsPattern(p, time.Second), r.Err()
+	})
+	Register("rainbow", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewRainbowPattern()
+		p.Value = r.Float("brightness", p.Value)
+		p.Repeats = r.Float("repeats", p.Repeats)
+		p.DegreesPerSecond = r.Float("speed", p.DegreesPerSecond)
+		return AsPattern(p, r.Duration("rate", DefaultFrameRate)), r.Err()
+	})
+	Register("gradient", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewGradientPattern()
+		p.LEDsPerSecond = r.Float("speed", p.LEDsPerSecond)
+		return AsPattern(p, r.Duration("rate", DefaultFrameRate)), r.Err()
+	})
+	Register("vu", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		p := NewVUMeterPattern(nil)
+		p.Gain = r.Float("gain", p.Gain)
+		p.Floor = r.Int("floor", p.Floor)
+		p.DecayPerSecond = r.Float("decay", p.DecayPerSecond)
+		p.PeakHold = r.Duration("hold", p.PeakHold)
+		return AsPattern(p, r.Duration("rate", 20*time.Millisecond)), r.Err()
+	})
+	Register("scene", func(params Params) (Pattern, error) {
+		r := params.Reader()
+		scene := NewCompositor("Scene",
+			Layer{Pattern: NewTwinklePattern(), Opacity: float32(r.Float("background", 0.4))},
+			Layer{Pattern: NewWavePattern(), Opacity: float32(r.Float("foreground", 1)), Mode: BlendAdditive},
+		)
+		return AsPattern(scene, r.Duration("rate", DefaultFrameRate)), r.Err()
+	})
+}
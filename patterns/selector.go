@@ -0,0 +1,173 @@
+package patterns
+
+import (
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// SelectorEntry is a pattern the selector can start
+type SelectorEntry struct {
+	Name string
+	New  func() Pattern
+}
+
+// RegistryEntries returns an entry for every registered pattern with its default params
+func RegistryEntries() []SelectorEntry {
+	names := Names()
+	entries := make([]SelectorEntry, len(names))
+	for i, name := range names {
+		entries[i] = SelectorEntry{Name: name, New: func() Pattern {
+			pattern, _ := Create(name, nil)
+			return pattern
+		}}
+	}
+	return entries
+}
+
+// Pauser is something drawing on the strip that steps aside while a pattern runs, e.g. the panel
+type Pauser interface {
+	Pause()
+	Resume()
+}
+
+// PatternSelector lets an operator choose patterns with a rotary encoder: turning
+// cycles through the entries and pressing starts the selected one
+type PatternSelector struct {
+	mu       sync.Mutex
+	manager  *PatternManager
+	entries  []SelectorEntry
+	encoder  peripheral.RotaryReader
+	confirm  peripheral.ButtonReader // nil starts patterns as soon as they are selected
+	panel    Pauser                  // Paused while a selected pattern runs, nil if none
+	selected int
+	pressed  bool
+
+	// OnSelect is called when the selection changes, e.g. to show the name on a display
+	OnSelect func(name string)
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewPatternSelector creates a selector driving manager from encoder and confirm
+func NewPatternSelector(manager *PatternManager, entries []SelectorEntry, encoder peripheral.RotaryReader, confirm peripheral.ButtonReader) *PatternSelector {
+	return &PatternSelector{
+		manager:    manager,
+		entries:    entries,
+		encoder:    encoder,
+		confirm:    confirm,
+		stopTicker: make(chan struct{}),
+	}
+}
+
+// SetPanel sets what is paused while a selected pattern owns the strip
+func (s *PatternSelector) SetPanel(panel Pauser) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.panel = panel
+}
+
+// Selected returns the name of the selected entry
+func (s *PatternSelector) Selected() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return ""
+	}
+	return s.entries[s.selected].Name
+}
+
+// Start begins polling the encoder at the given rate
+func (s *PatternSelector) Start(pollRate time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.running = true
+	s.ticker = time.NewTicker(pollRate)
+	s.stopTicker = make(chan struct{})
+	ticker, stop := s.ticker, s.stopTicker
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Update()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the selector's polling goroutine
+func (s *PatternSelector) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		close(s.stopTicker)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+		s.running = false
+	}
+}
+
+// Update reads the encoder and button once, moving the selection or starting a pattern.
+// It is called by the polling goroutine but may also be driven externally.
+func (s *PatternSelector) Update() {
+	s.mu.Lock()
+	if len(s.entries) == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	var changed, start bool
+	if delta := s.encoder.Delta(); delta != 0 {
+		n := len(s.entries)
+		s.selected = ((s.selected+delta)%n + n) % n
+		changed = true
+		start = s.confirm == nil
+	}
+
+	if s.confirm != nil {
+		pressed := s.confirm.IsPressed()
+		if pressed && !s.pressed {
+			start = true
+		}
+		s.pressed = pressed
+	}
+
+	entry := s.entries[s.selected]
+	onSelect := s.OnSelect
+	panel := s.panel
+	s.mu.Unlock()
+
+	// Call out without the lock; starting a pattern waits for the previous one to exit
+	if changed && onSelect != nil {
+		onSelect(entry.Name)
+	}
+	if !start {
+		return
+	}
+	pattern := entry.New()
+	if pattern == nil {
+		return
+	}
+
+	// The panel steps aside while the pattern owns the strip, and gets it back if it won't start
+	if panel != nil {
+		panel.Pause()
+	}
+	if err := s.manager.StartPattern(pattern); err != nil && panel != nil {
+		panel.Resume()
+	}
+}
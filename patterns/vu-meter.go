@@ -0,0 +1,96 @@
+package patterns
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/render"
+)
+
+// VUMeterStops color the meter green at the bottom through yellow to red at the top
+var VUMeterStops = []render.Stop{
+	{At: 0, Color: color.RGBA{G: 152, A: 255}},
+	{At: 0.6, Color: color.RGBA{R: 152, G: 152, A: 255}},
+	{At: 1, Color: color.RGBA{R: 152, A: 255}},
+}
+
+// VUMeterPattern shows a live analog input, e.g. a microphone envelope or a
+// potentiometer, as a bar with a peak-hold marker. The bar jumps up with the
+// input and falls back at DecayPerSecond; the marker holds the highest level
+// for PeakHold before falling.
+type VUMeterPattern struct {
+	Input          peripheral.AnalogReader // nil reads the package analog input
+	Gain           float64                 // Multiplier on the reading, for quiet microphones
+	Floor          int                     // Readings at or below this percentage show as silence
+	DecayPerSecond float64                 // Percentage points the bar falls per second
+	PeakHold       time.Duration           // Time the peak marker holds before falling
+	PeakColor      color.RGBA
+	Stops          []render.Stop
+
+	level       float64
+	peak        float64
+	peakAt      time.Duration
+	lastElapsed time.Duration
+}
+
+// NewVUMeterPattern creates a meter on input, or on the package analog input when nil
+func NewVUMeterPattern(input peripheral.AnalogReader) *VUMeterPattern {
+	return &VUMeterPattern{
+		Input:          input,
+		Gain:           1,
+		Floor:          3,
+		DecayPerSecond: 120,
+		PeakHold:       time.Second,
+		PeakColor:      color.RGBA{R: 152, G: 152, B: 152, A: 255},
+		Stops:          VUMeterStops,
+	}
+}
+
+func (p *VUMeterPattern) Name() string {
+	return "VUMeter"
+}
+
+// Level returns the bar's current level (0-100), e.g. to charge a battery while players shout
+func (p *VUMeterPattern) Level() float64 {
+	return p.level
+}
+
+// Render samples the input and draws the bar and peak marker
+func (p *VUMeterPattern) Render(buf []color.RGBA, elapsed time.Duration) {
+	dt, restarted := phaseAdvance(&p.lastElapsed, elapsed)
+	if restarted {
+		p.level, p.peak, p.peakAt = 0, 0, 0
+	}
+
+	// Fast attack, slow release
+	reading := p.read()
+	p.level = max(reading, p.level-p.DecayPerSecond*dt.Seconds(), 0)
+
+	if p.level >= p.peak {
+		p.peak = p.level
+		p.peakAt = elapsed
+	} else if elapsed-p.peakAt > p.PeakHold {
+		p.peak = max(p.peak-p.DecayPerSecond*dt.Seconds(), p.level)
+	}
+
+	target := render.Buffer(buf)
+	render.GradientBar(target, p.level, p.Stops)
+	if p.peak > 0 {
+		target.Set(render.Covered(len(buf), p.peak)-1, p.PeakColor)
+	}
+}
+
+// read samples the input as a percentage after the gain and floor
+func (p *VUMeterPattern) read() float64 {
+	var percentage int
+	if p.Input != nil {
+		percentage = p.Input.ReadPercentage()
+	} else {
+		percentage = peripheral.ReadAnalogInput()
+	}
+	if percentage <= p.Floor {
+		return 0
+	}
+	return min(float64(percentage)*p.Gain, 100)
+}
@@ -0,0 +1,17 @@
+//go:build !tinygo
+
+package peripheral
+
+// simulatedAnalog is the host-side stand-in for the slider. It starts mid-range
+// so delay-based patterns don't get a zero ticker period.
+var simulatedAnalog = NewMockAnalogReader(50)
+
+// defaultAnalogInput reads the simulated slider
+func defaultAnalogInput() AnalogReader {
+	return simulatedAnalog
+}
+
+// SetSimulatedAnalogInput sets the value (0-100) returned by analog and slider reads on the host
+func SetSimulatedAnalogInput(percentage int) {
+	simulatedAnalog.SetPercentage(percentage)
+}
@@ -0,0 +1,216 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// AnalogRole is what an analog input controls
+type AnalogRole int
+
+const (
+	AnalogNone         AnalogRole = iota // Unassigned
+	AnalogBrightness                     // Global LED brightness
+	AnalogPatternSpeed                   // Pattern speed, the slider read by the package-level helpers
+	AnalogDrainRate                      // Multiplier on every battery's drain rate
+)
+
+// String returns a string representation of the AnalogRole
+func (r AnalogRole) String() string {
+	switch r {
+	case AnalogNone:
+		return "None"
+	case AnalogBrightness:
+		return "Brightness"
+	case AnalogPatternSpeed:
+		return "PatternSpeed"
+	case AnalogDrainRate:
+		return "DrainRate"
+	default:
+		return "Unknown"
+	}
+}
+
+// ParseAnalogRole returns the role whose String is name
+func ParseAnalogRole(name string) (AnalogRole, bool) {
+	for role := AnalogNone; role <= AnalogDrainRate; role++ {
+		if role.String() == name {
+			return role, true
+		}
+	}
+	return AnalogNone, false
+}
+
+// AnalogChange is published when the smoothed value of a role's input moves
+type AnalogChange struct {
+	Role       AnalogRole
+	Percentage int // New value, 0-100
+}
+
+// AnalogInputsConfig holds options for polling analog inputs
+type AnalogInputsConfig struct {
+	Smoothing float32 // Weight (0-1) given to the previous value, as in AnalogConfig
+	Deadband  int     // Change in percent needed before a new value is published
+}
+
+// DefaultAnalogInputsConfig returns settings that steady a typical potentiometer
+func DefaultAnalogInputsConfig() AnalogInputsConfig {
+	return AnalogInputsConfig{
+		Smoothing: 0.7,
+		Deadband:  2,
+	}
+}
+
+// analogRoleInput is a single polled input
+type analogRoleInput struct {
+	reader AnalogReader
+	filter smoother
+	value  int
+	primed bool
+}
+
+// AnalogInputs polls analog readers assigned to roles, smooths their values,
+// and publishes changes to subscribed channels and callbacks
+type AnalogInputs struct {
+	mu        sync.Mutex
+	config    AnalogInputsConfig
+	inputs    map[AnalogRole]*analogRoleInput
+	channels  []chan<- AnalogChange
+	callbacks []func(AnalogChange)
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewAnalogInputs creates an empty set of role inputs
+func NewAnalogInputs(config AnalogInputsConfig) *AnalogInputs {
+	config.Smoothing = min(max(config.Smoothing, 0), 0.99)
+	config.Deadband = max(config.Deadband, 0)
+	return &AnalogInputs{
+		config: config,
+		inputs: make(map[AnalogRole]*analogRoleInput),
+	}
+}
+
+// Assign sets the reader for a role, replacing any previous one
+func (a *AnalogInputs) Assign(role AnalogRole, reader AnalogReader) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.inputs[role] = &analogRoleInput{
+		reader: reader,
+		filter: smoother{weight: a.config.Smoothing},
+	}
+}
+
+// Reader returns the reader assigned to a role, nil if none
+func (a *AnalogInputs) Reader(role AnalogRole) AnalogReader {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if input, ok := a.inputs[role]; ok {
+		return input.reader
+	}
+	return nil
+}
+
+// Value returns the last published value (0-100) of a role, ok is false if the
+// role has no input or hasn't been polled yet
+func (a *AnalogInputs) Value(role AnalogRole) (percentage int, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	input, ok := a.inputs[role]
+	if !ok || !input.primed {
+		return 0, false
+	}
+	return input.value, true
+}
+
+// Subscribe registers a channel that receives every change.
+// Sends never block the poller; changes are dropped if the channel is full,
+// so use a buffered channel.
+func (a *AnalogInputs) Subscribe(ch chan<- AnalogChange) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.channels = append(a.channels, ch)
+}
+
+// OnChange registers a callback run for every change, on the polling goroutine
+func (a *AnalogInputs) OnChange(callback func(AnalogChange)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.callbacks = append(a.callbacks, callback)
+}
+
+// Start begins polling the inputs at the given rate
+func (a *AnalogInputs) Start(pollRate time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		return
+	}
+	a.running = true
+	a.ticker = time.NewTicker(pollRate)
+	a.stopTicker = make(chan struct{})
+
+	ticker, stop := a.ticker, a.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				a.Poll()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (a *AnalogInputs) Stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.running {
+		close(a.stopTicker)
+		if a.ticker != nil {
+			a.ticker.Stop()
+		}
+		a.running = false
+	}
+}
+
+// Poll reads every input once and publishes values that moved past the deadband.
+// It is called by the poller but may also be driven externally.
+func (a *AnalogInputs) Poll() {
+	a.mu.Lock()
+	var changes []AnalogChange
+	for role, input := range a.inputs {
+		percentage := rawToPercentage(input.filter.add(input.reader.ReadRaw()))
+		delta := percentage - input.value
+		if input.primed && delta <= a.config.Deadband && delta >= -a.config.Deadband {
+			continue
+		}
+		input.value = percentage
+		input.primed = true
+		changes = append(changes, AnalogChange{Role: role, Percentage: percentage})
+	}
+	channels := append([]chan<- AnalogChange(nil), a.channels...)
+	callbacks := make([]func(AnalogChange), len(a.callbacks))
+	copy(callbacks, a.callbacks)
+	a.mu.Unlock()
+
+	for _, change := range changes {
+		for _, ch := range channels {
+			select {
+			case ch <- change:
+			default:
+				// Subscriber isn't keeping up, drop the change
+			}
+		}
+		for _, callback := range callbacks {
+			callback(change)
+		}
+	}
+}
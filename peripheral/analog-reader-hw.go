@@ -0,0 +1,57 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+	"sync"
+)
+
+// initADC enables the ADC peripheral once for all readers
+var initADC sync.Once
+
+// ADCReader reads an analog pin through the on-chip ADC. The pin is configured
+// once when the reader is created rather than on every read.
+type ADCReader struct {
+	adc    machine.ADC
+	filter smoother
+}
+
+// NewADCReader configures pin as an analog input
+func NewADCReader(pin machine.Pin, config AnalogConfig) *ADCReader {
+	initADC.Do(machine.InitADC)
+
+	r := &ADCReader{
+		adc:    machine.ADC{Pin: pin},
+		filter: smoother{weight: min(max(config.Smoothing, 0), 0.99)},
+	}
+	r.adc.Configure(machine.ADCConfig{})
+	return r
+}
+
+// ReadRaw samples the pin and returns the (smoothed) raw reading
+func (r *ADCReader) ReadRaw() uint16 {
+	return r.filter.add(r.adc.Get())
+}
+
+// ReadPercentage samples the pin and returns the (smoothed) reading as 0-100
+func (r *ADCReader) ReadPercentage() int {
+	return rawToPercentage(r.ReadRaw())
+}
+
+// SetAnalogInputPin points the package-level analog and slider helpers at an
+// ADC pin, e.g. the HardwareConfig.AnalogInputs pattern speed slider, lightly smoothed to steady the slider
+func SetAnalogInputPin(pin machine.Pin) {
+	SetAnalogInput(NewADCReader(pin, AnalogConfig{Smoothing: 0.5}))
+}
+
+// defaultAnalogInput reads A0 until SetAnalogInputPin selects another pin
+func defaultAnalogInput() AnalogReader {
+	return NewADCReader(machine.A0, AnalogConfig{})
+}
+
+// NewMicrophoneOnPin creates a microphone on an ADC pin, read without smoothing
+// so the envelope sees the waveform
+func NewMicrophoneOnPin(pin machine.Pin, config MicrophoneConfig) *Microphone {
+	return NewMicrophone(NewADCReader(pin, AnalogConfig{}), config)
+}
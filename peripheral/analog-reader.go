@@ -0,0 +1,141 @@
+package peripheral
+
+import "sync"
+
+// AnalogReader reads an analog input such as the slider
+type AnalogReader interface {
+	ReadRaw() uint16     // Raw 16-bit reading
+	ReadPercentage() int // Reading scaled to 0-100
+}
+
+// AnalogConfig holds analog input options
+type AnalogConfig struct {
+	// Smoothing is the weight (0-1) given to the previous value in an exponential
+	// moving average of readings; 0 disables smoothing, higher values filter more
+	Smoothing float32
+}
+
+// analogFullScale is the largest raw reading
+const analogFullScale = 65535
+
+// rawToPercentage converts a raw reading to 0-100
+func rawToPercentage(raw uint16) int {
+	return int(raw) * 100 / analogFullScale
+}
+
+// smoother applies exponential smoothing to a stream of readings
+type smoother struct {
+	mu     sync.Mutex
+	weight float32
+	value  float32
+	primed bool
+}
+
+// add feeds a reading to the filter and returns the smoothed value
+func (s *smoother) add(raw uint16) uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.weight <= 0 || !s.primed {
+		s.value = float32(raw)
+		s.primed = true
+	} else {
+		s.value = s.weight*s.value + (1-s.weight)*float32(raw)
+	}
+	return uint16(s.value + 0.5)
+}
+
+// MockAnalogReader is an AnalogReader whose value is set directly, for the
+// simulator, demos and bench testing
+type MockAnalogReader struct {
+	mu  sync.Mutex
+	raw uint16
+}
+
+// NewMockAnalogReader creates a mock analog reader at the given percentage
+func NewMockAnalogReader(percentage int) *MockAnalogReader {
+	m := &MockAnalogReader{}
+	m.SetPercentage(percentage)
+	return m
+}
+
+// SetRaw sets the raw reading
+func (m *MockAnalogReader) SetRaw(raw uint16) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.raw = raw
+}
+
+// SetPercentage sets the reading as a percentage (clamped to 0-100)
+func (m *MockAnalogReader) SetPercentage(percentage int) {
+	m.SetRaw(uint16(max(0, min(100, percentage)) * analogFullScale / 100))
+}
+
+// ReadRaw returns the raw reading
+func (m *MockAnalogReader) ReadRaw() uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.raw
+}
+
+// ReadPercentage returns the reading as a percentage
+func (m *MockAnalogReader) ReadPercentage() int {
+	return rawToPercentage(m.ReadRaw())
+}
+
+// analogInput is the reader behind the package-level analog and slider helpers
+var (
+	analogMu    sync.Mutex
+	analogInput AnalogReader
+)
+
+// SetAnalogInput selects the reader used by the package-level analog and slider helpers
+func SetAnalogInput(reader AnalogReader) {
+	analogMu.Lock()
+	defer analogMu.Unlock()
+	analogInput = reader
+}
+
+// currentAnalogInput returns the reader used by the package-level helpers,
+// creating the board default on first use
+func currentAnalogInput() AnalogReader {
+	analogMu.Lock()
+	defer analogMu.Unlock()
+	if analogInput == nil {
+		analogInput = defaultAnalogInput()
+	}
+	return analogInput
+}
+
+// ReadAnalogInput reads the analog input and returns a value between 0-100
+// This can be used for variable delay or other analog input needs
+func ReadAnalogInput() int {
+	return currentAnalogInput().ReadPercentage()
+}
+
+// ReadAnalogInputRaw reads the analog input and returns the raw ADC value
+func ReadAnalogInputRaw() uint16 {
+	return currentAnalogInput().ReadRaw()
+}
+
+// ReadAnalogInputAsDelay reads analog input and converts it to a delay in milliseconds
+// scale: the maximum delay value in milliseconds
+func ReadAnalogInputAsDelay(scale int) int {
+	return (scale * ReadAnalogInput()) / 100
+}
+
+// ReadSliderInputPercentage returns the slider (the analog input) as a value between 0-100,
+// filtered by the package's SliderWatcher so it only changes when the slider is moved
+func ReadSliderInputPercentage() int {
+	return currentSliderWatcher().Value()
+}
+
+// ReadSliderInputRaw reads the slider (the analog input) and returns the raw ADC value
+func ReadSliderInputRaw() uint16 {
+	return ReadAnalogInputRaw()
+}
+
+// ReadSliderInputScaled reads slider input and returns a number between 0 and max
+func ReadSliderInputScaled(max int) int {
+	return (max * ReadSliderInputPercentage()) / 100
+}
@@ -0,0 +1,6 @@
+//go:build !tinygo
+
+package peripheral
+
+// boardPin stands in for machine.Pin on the host, where there are no pins
+type boardPin = uint8
@@ -0,0 +1,8 @@
+//go:build tinygo
+
+package peripheral
+
+import "machine"
+
+// boardPin is a GPIO pin, letting host-buildable peripherals hold one
+type boardPin = machine.Pin
@@ -1,3 +1,5 @@
+//go:build tinygo
+
 package peripheral
 
 import (
@@ -7,6 +9,10 @@ import (
 	"time"
 )
 
+// Compile-time assertion that BoardYellowLight implements Peripheral
+var _ Peripheral = (*BoardYellowLight)(nil)
+
+// BoardYellowLight blinks the onboard single-color LED as a heartbeat
 type BoardYellowLight struct {
 	Led     machine.Pin
 	ctx     context.Context
@@ -15,23 +21,30 @@ type BoardYellowLight struct {
 	mu      sync.Mutex
 }
 
-func (e *BoardYellowLight) Configure() {
-	// Blink yellow board LED
-	e.Led = machine.PC30
+// NewBoardYellowLight creates the heartbeat LED on the given pin (HardwareConfig.StatusLEDPin)
+func NewBoardYellowLight(pin machine.Pin) *BoardYellowLight {
+	return &BoardYellowLight{Led: pin}
+}
+
+// Configure sets up the LED pin as an output
+func (e *BoardYellowLight) Configure() error {
 	e.Led.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	return nil
 }
 
-func (e *BoardYellowLight) StartBlink() {
+// Start blinks the LED until ctx is done or Stop is called
+func (e *BoardYellowLight) Start(ctx context.Context) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	if e.running {
-		return // Already running
+		return nil // Already running
 	}
 
-	e.ctx, e.cancel = context.WithCancel(context.Background())
+	e.ctx, e.cancel = context.WithCancel(ctx)
 	e.running = true
 
+	blinkCtx := e.ctx
 	go func() {
 		defer func() {
 			e.mu.Lock()
@@ -48,7 +61,7 @@ func (e *BoardYellowLight) StartBlink() {
 
 		for {
 			select {
-			case <-e.ctx.Done():
+			case <-blinkCtx.Done():
 				return
 			case <-ticker.C:
 				if ledState {
@@ -60,9 +73,11 @@ func (e *BoardYellowLight) StartBlink() {
 			}
 		}
 	}()
+	return nil
 }
 
-func (e *BoardYellowLight) StopBlink() {
+// Stop stops blinking and turns the LED off
+func (e *BoardYellowLight) Stop() {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
@@ -75,6 +90,7 @@ func (e *BoardYellowLight) StopBlink() {
 	}
 }
 
+// IsRunning returns whether the LED is blinking
 func (e *BoardYellowLight) IsRunning() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
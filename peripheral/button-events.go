@@ -0,0 +1,80 @@
+package peripheral
+
+import "sync/atomic"
+
+// ButtonEvent is a press or release edge captured by a pin interrupt
+type ButtonEvent struct {
+	Pressed bool // true for a press, false for a release
+}
+
+// eventQueueSize is the number of edges an EventQueue holds; a multiple of two
+// so the indices wrap cleanly
+const eventQueueSize = 16
+
+// EventQueue is a fixed-size queue of button edges. It doesn't allocate or
+// lock, so one interrupt handler can push while one reader pops.
+type EventQueue struct {
+	events [eventQueueSize]ButtonEvent
+	head   atomic.Uint32 // Next slot to pop
+	tail   atomic.Uint32 // Next slot to push
+}
+
+// Push adds an edge, dropping it if the queue is full
+func (q *EventQueue) Push(e ButtonEvent) bool {
+	tail := q.tail.Load()
+	if tail-q.head.Load() >= eventQueueSize {
+		return false
+	}
+	q.events[tail%eventQueueSize] = e
+	q.tail.Store(tail + 1)
+	return true
+}
+
+// Pop removes the oldest edge
+func (q *EventQueue) Pop() (ButtonEvent, bool) {
+	head := q.head.Load()
+	if head == q.tail.Load() {
+		return ButtonEvent{}, false
+	}
+	e := q.events[head%eventQueueSize]
+	q.head.Store(head + 1)
+	return e, true
+}
+
+// PressLatcher is implemented by inputs that queue edges between reads, so a
+// press shorter than the reader's polling interval isn't missed
+type PressLatcher interface {
+	// DrainPresses empties the queued edges, reporting whether any was a press
+	DrainPresses() bool
+}
+
+// DrainPresses empties an input's queued edges if it latches them, reporting
+// whether a press happened since the last call
+func DrainPresses(reader ButtonReader) bool {
+	if latcher, ok := reader.(PressLatcher); ok {
+		return latcher.DrainPresses()
+	}
+	return false
+}
+
+// DrainPresses drains every combined input
+func (a anyPressed) DrainPresses() bool {
+	pressed := false
+	for _, reader := range a {
+		if DrainPresses(reader) {
+			pressed = true
+		}
+	}
+	return pressed
+}
+
+// DrainPresses reports whether a debounced press happened since the last call,
+// so a press the sampler accepted still reaches a panel polling more slowly
+func (b *DebouncedButton) DrainPresses() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	pressed := b.latchedPress
+	b.latchedPress = false
+	return pressed
+}
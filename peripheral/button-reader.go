@@ -0,0 +1,75 @@
+package peripheral
+
+import (
+	"sync"
+)
+
+// ButtonReader represents digital input interface
+type ButtonReader interface {
+	// IsPressed returns true if the input is currently pressed/active
+	IsPressed() bool
+}
+
+var _ ButtonReader = (*MockButton)(nil)
+
+// MockButton is a simple implementation for testing
+type MockButton struct {
+	pressed bool // map of battery number to pressed state
+	mu      sync.RWMutex
+}
+
+// NewMockButton creates a new mock input handler
+func NewMockButton() *MockButton {
+	return &MockButton{
+		pressed: false,
+	}
+}
+
+// IsPressed returns the pressed state for a specific battery
+func (m *MockButton) IsPressed() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.pressed
+}
+
+// SetPressed sets the pressed state for a specific battery (for testing)
+func (m *MockButton) SetPressed(pressed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pressed = pressed
+}
+
+// anyPressed combines several inputs into one
+type anyPressed []ButtonReader
+
+// AnyPressed returns a ButtonReader that is pressed while any of readers is pressed,
+// e.g. to let a physical switch and a remote command drive the same input
+func AnyPressed(readers ...ButtonReader) ButtonReader {
+	return anyPressed(readers)
+}
+
+// IsPressed returns true if any of the combined inputs is pressed
+func (a anyPressed) IsPressed() bool {
+	for _, reader := range a {
+		if reader.IsPressed() {
+			return true
+		}
+	}
+	return false
+}
+
+// inverted flips an input
+type inverted struct {
+	reader ButtonReader
+}
+
+// Inverted returns a ButtonReader that is pressed while reader is released,
+// e.g. for a switch wired normally-closed instead of normally-open
+func Inverted(reader ButtonReader) ButtonReader {
+	return inverted{reader: reader}
+}
+
+// IsPressed returns true if the wrapped input is released
+func (i inverted) IsPressed() bool {
+	return !i.reader.IsPressed()
+}
@@ -1,77 +1,135 @@
+//go:build tinygo
+
 package peripheral
 
 import (
+	"context"
 	"machine"
-	"sync"
 )
 
-// ButtonReader represents digital input interface
-type ButtonReader interface {
-	// IsPressed returns true if the input is currently pressed/active
-	IsPressed() bool
+// Compile-time assertion that Button implements ButtonReader and PressLatcher
+var _ ButtonReader = (*Button)(nil)
+var _ PressLatcher = (*Button)(nil)
+var _ Peripheral = (*Button)(nil)
+
+// PullMode selects the pull resistor used for a button input
+type PullMode int
+
+const (
+	PullUp   PullMode = iota // Internal pull-up resistor
+	PullDown                 // Internal pull-down resistor
+	PullNone                 // No internal pull, an external resistor is fitted
+)
+
+// ButtonConfig describes how a single button input is wired
+type ButtonConfig struct {
+	Pin         machine.Pin
+	Pull        PullMode
+	ActiveLow   bool // true if pin reads low when pressed
+	Expander    bool // Read ExpanderPin on the GPIO expander instead of Pin
+	ExpanderPin int  // Expander pin 0-15, only used when Expander is set
+	Touch       bool // Read TouchPad on the capacitive touch controller instead of Pin
+	TouchPad    int  // Touch pad 0-11, only used when Touch is set
+	Interrupt   bool // Latch edges with a pin interrupt so presses between polls aren't missed
 }
 
-// Compile-time assertion that PinInputHandler implements InputHandler
-var _ ButtonReader = (*Button)(nil)
+// Fitted returns whether the button is wired to a board pin, the expander or a touch pad
+func (c ButtonConfig) Fitted() bool {
+	return c.Pin != machine.NoPin || c.Expander || c.Touch
+}
 
 // Button handles digital input from a hardware pin
 type Button struct {
 	pin      machine.Pin
-	inverted bool // true if pin reads low when pressed
+	pull     PullMode
+	inverted bool        // true if pin reads low when pressed
+	useIRQ   bool        // Enable the pin interrupt in Configure
+	events   *EventQueue // Edges latched by the interrupt, nil when polled
 }
 
-// NewButton creates a new hardware pin input handler
+// NewButton creates a new hardware pin input handler using the internal pull-up
 func NewButton(pin machine.Pin, inverted bool) *Button {
+	return NewButtonFromConfig(ButtonConfig{
+		Pin:       pin,
+		Pull:      PullUp,
+		ActiveLow: inverted,
+	})
+}
+
+// NewButtonFromConfig creates a new hardware pin input handler from a wiring config
+func NewButtonFromConfig(config ButtonConfig) *Button {
 	return &Button{
-		pin:      pin,
-		inverted: inverted,
+		pin:      config.Pin,
+		pull:     config.Pull,
+		inverted: config.ActiveLow,
+		useIRQ:   config.Interrupt,
 	}
 }
 
-// Configure sets up the pin as input with pull-up resistor
+// Configure sets up the pin as input with the configured pull resistor
 func (p *Button) Configure() error {
+	mode := machine.PinInputPullup
+	switch p.pull {
+	case PullDown:
+		mode = machine.PinInputPulldown
+	case PullNone:
+		mode = machine.PinInput
+	}
+
 	p.pin.Configure(machine.PinConfig{
-		Mode: machine.PinInputPullup,
+		Mode: mode,
 	})
+	if p.useIRQ {
+		return p.EnableInterrupt()
+	}
 	return nil
 }
 
-// IsPressed returns true if the input is currently pressed/active
-// The battery number parameter is ignored for hardware pins since
-// each pin represents input for all batteries connected to it
-func (p *Button) IsPressed() bool {
-	reading := p.pin.Get()
-	if p.inverted {
-		return !reading
-	}
-	return reading
+// Start is a no-op, the pin is read on demand and edges are latched by the interrupt
+func (p *Button) Start(ctx context.Context) error {
+	return nil
 }
 
-var _ ButtonReader = (*MockButton)(nil)
-
-// MockButton is a simple implementation for testing
-type MockButton struct {
-	pressed bool // map of battery number to pressed state
-	mu      sync.RWMutex
+// Stop disables the pin interrupt, if one was enabled
+func (p *Button) Stop() {
+	if p.events != nil {
+		p.pin.SetInterrupt(0, nil)
+	}
 }
 
-// NewMockButton creates a new mock input handler
-func NewMockButton() *MockButton {
-	return &MockButton{
-		pressed: false,
+// EnableInterrupt latches every edge on the pin into a queue drained by
+// DrainPresses, so presses shorter than the polling interval are still seen
+func (p *Button) EnableInterrupt() error {
+	if p.events == nil {
+		p.events = &EventQueue{}
 	}
+	return p.pin.SetInterrupt(machine.PinToggle, func(machine.Pin) {
+		p.events.Push(ButtonEvent{Pressed: p.IsPressed()})
+	})
 }
 
-// IsPressed returns the pressed state for a specific battery
-func (m *MockButton) IsPressed() bool {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
-	return m.pressed
+// DrainPresses empties the latched edges, reporting whether any was a press
+func (p *Button) DrainPresses() bool {
+	if p.events == nil {
+		return false
+	}
+	pressed := false
+	for {
+		e, ok := p.events.Pop()
+		if !ok {
+			return pressed
+		}
+		pressed = pressed || e.Pressed
+	}
 }
 
-// SetPressed sets the pressed state for a specific battery (for testing)
-func (m *MockButton) SetPressed(pressed bool) {
-	m.mu.Lock()
-	defer m.mu.Unlock()
-	m.pressed = pressed
+// IsPressed returns true if the input is currently pressed/active
+// The battery number parameter is ignored for hardware pins since
+// each pin represents input for all batteries connected to it
+func (p *Button) IsPressed() bool {
+	reading := p.pin.Get()
+	if p.inverted {
+		return !reading
+	}
+	return reading
 }
@@ -0,0 +1,68 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// PWM is the subset of a TinyGo PWM peripheral (e.g. machine.TCC0) used for tones
+type PWM interface {
+	Configure(config machine.PWMConfig) error
+	Channel(pin machine.Pin) (uint8, error)
+	SetPeriod(period uint64) error
+	Top() uint32
+	Set(channel uint8, value uint32)
+}
+
+var _ ToneGenerator = (*Buzzer)(nil)
+
+// Buzzer drives a piezo buzzer from a PWM channel
+type Buzzer struct {
+	pwm     PWM
+	pin     machine.Pin
+	channel uint8
+}
+
+// NewBuzzer creates a buzzer on pin driven by pwm
+func NewBuzzer(pwm PWM, pin machine.Pin) *Buzzer {
+	return &Buzzer{
+		pwm: pwm,
+		pin: pin,
+	}
+}
+
+// Configure sets up the PWM peripheral and channel for the buzzer pin
+func (b *Buzzer) Configure() error {
+	if err := b.pwm.Configure(machine.PWMConfig{}); err != nil {
+		return err
+	}
+
+	ch, err := b.pwm.Channel(b.pin)
+	if err != nil {
+		return err
+	}
+	b.channel = ch
+	b.Off()
+	return nil
+}
+
+// Tone plays frequency (Hz) at volume (0-100); a 50% duty cycle is full volume
+func (b *Buzzer) Tone(frequency uint32, volume uint8) {
+	if frequency == 0 || volume == 0 {
+		b.Off()
+		return
+	}
+	if volume > 100 {
+		volume = 100
+	}
+
+	// Period is in nanoseconds
+	b.pwm.SetPeriod(uint64(1e9 / frequency))
+	b.pwm.Set(b.channel, b.pwm.Top()/2*uint32(volume)/100)
+}
+
+// Off silences the buzzer
+func (b *Buzzer) Off() {
+	b.pwm.Set(b.channel, 0)
+}
@@ -0,0 +1,9 @@
+package peripheral
+
+// ToneGenerator plays square-wave tones, e.g. on a piezo buzzer
+type ToneGenerator interface {
+	// Tone plays frequency (Hz) at volume (0-100) until changed
+	Tone(frequency uint32, volume uint8)
+	// Off silences the output
+	Off()
+}
@@ -0,0 +1,29 @@
+//go:build !tinygo
+
+package peripheral
+
+import (
+	"image"
+	"image/png"
+	"io"
+)
+
+// WriteFramesPNG writes frames as a PNG with one row per frame and one column
+// per pixel, so a glitch shows up as a break in the streaks of an animation.
+// It is host-only to keep the PNG encoder out of the firmware; frames dumped
+// over serial are read back with ParseFrame.
+func WriteFramesPNG(w io.Writer, frames []Frame) error {
+	width := 0
+	for _, frame := range frames {
+		width = max(width, len(frame.Pixels))
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, max(width, 1), max(len(frames), 1)))
+	for y, frame := range frames {
+		for x, c := range frame.Pixels {
+			c.A = 255
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return png.Encode(w, img)
+}
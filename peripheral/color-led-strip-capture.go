@@ -0,0 +1,120 @@
+package peripheral
+
+import (
+	"encoding/hex"
+	"errors"
+	"image/color"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Frame is a timestamped copy of a strip's pixels, as drawn before brightness
+// and white balance
+type Frame struct {
+	At     time.Time
+	Pixels []color.RGBA
+}
+
+// frameHistory is a ring of the last frames shown, allocated up front so
+// recording doesn't allocate on every Show
+type frameHistory struct {
+	mu     sync.Mutex
+	frames []Frame
+	next   int // Index the next frame is recorded at
+	count  int // Number of frames recorded, up to len(frames)
+}
+
+// CaptureFrame returns a timestamped copy of the current buffer
+func (d *ColorLedStrip) CaptureFrame() Frame {
+	return Frame{At: time.Now(), Pixels: d.GetBuffer()}
+}
+
+// SetFrameHistory keeps the last n frames shown so they can be dumped after a
+// glitch is spotted; 0 stops recording and frees the history
+func (d *ColorLedStrip) SetFrameHistory(n int) {
+	d.history.mu.Lock()
+	defer d.history.mu.Unlock()
+
+	d.history.frames = nil
+	d.history.next = 0
+	d.history.count = 0
+	if n <= 0 {
+		return
+	}
+	d.history.frames = make([]Frame, n)
+	for i := range d.history.frames {
+		d.history.frames[i].Pixels = make([]color.RGBA, d.numLEDs)
+	}
+}
+
+// FrameHistory returns copies of the recorded frames, oldest first
+func (d *ColorLedStrip) FrameHistory() []Frame {
+	d.history.mu.Lock()
+	defer d.history.mu.Unlock()
+
+	h := &d.history
+	frames := make([]Frame, h.count)
+	start := (h.next - h.count + len(h.frames)) % max(len(h.frames), 1)
+	for i := range frames {
+		src := h.frames[(start+i)%len(h.frames)]
+		frames[i] = Frame{At: src.At, Pixels: make([]color.RGBA, len(src.Pixels))}
+		copy(frames[i].Pixels, src.Pixels)
+	}
+	return frames
+}
+
+// recordFrame adds the frame just shown to the history, if one is kept
+func (d *ColorLedStrip) recordFrame(pixels []color.RGBA) {
+	d.history.mu.Lock()
+	defer d.history.mu.Unlock()
+
+	h := &d.history
+	if len(h.frames) == 0 {
+		return
+	}
+	h.frames[h.next].At = time.Now()
+	copy(h.frames[h.next].Pixels, pixels)
+	h.next = (h.next + 1) % len(h.frames)
+	h.count = min(h.count+1, len(h.frames))
+}
+
+// String formats the frame as one row of text: its time in Unix milliseconds,
+// then six hex digits (rrggbb) per pixel, e.g. "1718000000123 ff0000ff0000000000"
+func (f Frame) String() string {
+	var sb strings.Builder
+	sb.Grow(20 + len(f.Pixels)*6)
+	sb.WriteString(strconv.FormatInt(f.At.UnixMilli(), 10))
+	sb.WriteByte(' ')
+	var rgb [3]byte
+	var digits [6]byte
+	for _, c := range f.Pixels {
+		rgb = [3]byte{c.R, c.G, c.B}
+		hex.Encode(digits[:], rgb[:])
+		sb.Write(digits[:])
+	}
+	return sb.String()
+}
+
+// ParseFrame parses a row written by Frame.String, e.g. copied from a serial dump
+func ParseFrame(s string) (Frame, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Frame{}, errors.New("frame must be <unix ms> <rrggbb...>")
+	}
+	ms, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return Frame{}, errors.New("invalid frame time " + fields[0])
+	}
+	raw, err := hex.DecodeString(fields[1])
+	if err != nil || len(raw)%3 != 0 {
+		return Frame{}, errors.New("frame pixels must be six hex digits each")
+	}
+
+	frame := Frame{At: time.UnixMilli(ms), Pixels: make([]color.RGBA, len(raw)/3)}
+	for i := range frame.Pixels {
+		frame.Pixels[i] = color.RGBA{R: raw[i*3], G: raw[i*3+1], B: raw[i*3+2], A: 255}
+	}
+	return frame, nil
+}
@@ -0,0 +1,112 @@
+package peripheral
+
+import (
+	"image/color"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// pixelFade is a running fade of one pixel
+type pixelFade struct {
+	from, to color.RGBA
+	start    time.Time
+	duration time.Duration
+	active   bool
+}
+
+// FadePixel fades a pixel from the color last shown to target over duration.
+// The fade advances on every Show and overrides anything drawn on the pixel
+// until it completes; a duration of 0 sets the pixel at once.
+func (d *ColorLedStrip) FadePixel(index int, target color.RGBA, duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fadePixel(index, target, duration)
+}
+
+// fadePixel starts a pixel fade (must be called with mutex locked)
+func (d *ColorLedStrip) fadePixel(index int, target color.RGBA, duration time.Duration) {
+	if index < 0 || index >= d.numLEDs {
+		return
+	}
+	if duration <= 0 {
+		d.cancelFade(index)
+		d.buffer[index] = target
+		return
+	}
+
+	if d.fades == nil {
+		d.fades = make([]pixelFade, d.numLEDs)
+	}
+	if !d.fades[index].active {
+		d.fading++
+	}
+	d.fades[index] = pixelFade{
+		from:     d.frame[index],
+		to:       target,
+		start:    time.Now(),
+		duration: duration,
+		active:   true,
+	}
+}
+
+// FadeAll fades every pixel to target over duration
+func (d *ColorLedStrip) FadeAll(target color.RGBA, duration time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := 0; i < d.numLEDs; i++ {
+		d.fadePixel(i, target, duration)
+	}
+}
+
+// IsFading returns whether any pixel fade is still running
+func (d *ColorLedStrip) IsFading() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.fading > 0
+}
+
+// CancelFades stops every running fade, leaving the pixels to what is drawn next
+func (d *ColorLedStrip) CancelFades() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cancelFades()
+}
+
+// cancelFades stops every running fade (must be called with mutex locked)
+func (d *ColorLedStrip) cancelFades() {
+	for i := range d.fades {
+		d.fades[i].active = false
+	}
+	d.fading = 0
+}
+
+// cancelFade stops the fade of a single pixel (must be called with mutex locked)
+func (d *ColorLedStrip) cancelFade(index int) {
+	if d.fades != nil && d.fades[index].active {
+		d.fades[index].active = false
+		d.fading--
+	}
+}
+
+// applyFades writes the current color of every fading pixel into the buffer (must be called with mutex locked)
+func (d *ColorLedStrip) applyFades(now time.Time) {
+	if d.fading == 0 {
+		return
+	}
+
+	for i := range d.fades {
+		fade := &d.fades[i]
+		if !fade.active {
+			continue
+		}
+		progress := float64(now.Sub(fade.start)) / float64(fade.duration)
+		if progress >= 1 {
+			d.buffer[i] = fade.to
+			fade.active = false
+			d.fading--
+			continue
+		}
+		d.buffer[i] = colorutil.Lerp(fade.from, fade.to, progress)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !tinygo
+
+package peripheral
+
+// Configure is a no-op on the host; attach a writer with NewColorLedStripWithWriter
+func (d *ColorLedStrip) Configure() error {
+	return nil
+}
@@ -0,0 +1,98 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"errors"
+	"machine"
+
+	"tinygo.org/x/drivers/apa102"
+)
+
+// ErrNoStripBus is returned when a StripConfig has neither an SPI bus nor software SPI pins
+var ErrNoStripBus = errors.New("LED strip needs an SPI bus or SCK and SDO pins")
+
+// DefaultStripFrequency is the SPI clock for the strip: 144 APA102s at 20 FPS need
+// well under 1MHz, 4MHz leaves headroom while staying reliable over a long cable
+const DefaultStripFrequency = 4000000
+
+// ColorOrder is the order an APA102 batch expects the color bytes in
+type ColorOrder int
+
+const (
+	OrderBGR ColorOrder = apa102.BGR // Current APA102s
+	OrderBRG ColorOrder = apa102.BRG // Typical of 2015-2017 batches
+	OrderGRB ColorOrder = apa102.GRB // Typical of pre-2015 batches
+)
+
+// StripConfig selects how the APA102 strip is driven: a hardware SPI bus, or
+// any two GPIO pins bit-banged when the wiring can't reach one
+type StripConfig struct {
+	SPI       *machine.SPI // Hardware SPI bus, nil to bit-bang SCK and SDO
+	Frequency uint32       // Hardware SPI clock in Hz, DefaultStripFrequency if 0
+
+	// Clock and data pins: required for software SPI, or 0 to use the hardware bus's default pins
+	SCK           machine.Pin
+	SDO           machine.Pin
+	SoftwareDelay uint32 // Busy-wait loops per quarter clock, 0 for the fastest clock
+
+	Order         ColorOrder // Color byte order of the LEDs, OrderBGR if unset
+	GlobalCurrent uint8      // APA102 5-bit driver current 1-31, 0 for full
+}
+
+// NewColorLedStripFromConfig creates a strip of numLEDs and configures its driver from config
+func NewColorLedStripFromConfig(numLEDs int, config StripConfig) (*ColorLedStrip, error) {
+	strip := NewColorLedStrip(numLEDs)
+	return strip, strip.ConfigureStrip(config)
+}
+
+// Configure initializes the SPI interface and LED strip driver on SPI0, unless a
+// driver is already attached, e.g. by NewColorLedStripFromConfig
+func (d *ColorLedStrip) Configure() error {
+	d.mu.Lock()
+	attached := d.ledStrip != nil
+	d.mu.Unlock()
+	if attached {
+		return nil
+	}
+	return d.ConfigureSPI(machine.SPI0)
+}
+
+// ConfigureStrip initializes the LED strip driver as described by config (HardwareConfig.Strip)
+func (d *ColorLedStrip) ConfigureStrip(config StripConfig) error {
+	var device *apa102.Device
+	if config.SPI != nil {
+		frequency := config.Frequency
+		if frequency == 0 {
+			frequency = DefaultStripFrequency
+		}
+		err := config.SPI.Configure(machine.SPIConfig{
+			Frequency: frequency,
+			SCK:       config.SCK,
+			SDO:       config.SDO,
+		})
+		if err != nil {
+			return err
+		}
+		device = apa102.New(config.SPI)
+	} else {
+		if config.SCK == config.SDO {
+			return ErrNoStripBus
+		}
+		device = apa102.NewSoftwareSPI(config.SCK, config.SDO, config.SoftwareDelay)
+	}
+
+	device.Order = int(config.Order)
+	if config.GlobalCurrent > 0 {
+		d.SetGlobalCurrent(config.GlobalCurrent)
+	}
+	d.mu.Lock()
+	d.ledStrip = device
+	d.mu.Unlock()
+	return nil
+}
+
+// ConfigureSPI initializes the LED strip driver on the given hardware SPI bus with the default clock
+func (d *ColorLedStrip) ConfigureSPI(spi *machine.SPI) error {
+	return d.ConfigureStrip(StripConfig{SPI: spi})
+}
@@ -1,44 +1,115 @@
 package peripheral
 
 import (
+	"context"
 	"image/color"
-	"machine"
-
-	"tinygo.org/x/drivers/apa102"
+	"math"
+	"slices"
+	"sync"
+	"time"
 )
 
-// ColorLedStrip represents an APA102 LED strip peripheral
+// gammaTable maps linear 8-bit channel values to gamma 2.8 corrected output
+var gammaTable [256]uint8
+
+func init() {
+	for i := range gammaTable {
+		gammaTable[i] = uint8(math.Round(math.Pow(float64(i)/255, 2.8) * 255))
+	}
+}
+
+// PixelWriter pushes a frame of colors out to physical (or simulated) LEDs
+type PixelWriter interface {
+	WriteColors(cs []color.RGBA) (n int, err error)
+}
+
+// WhiteBalance holds per-channel calibration multipliers, so strips from
+// different batches render the same color alike; 1.0 leaves a channel unchanged
+type WhiteBalance struct {
+	R, G, B float32
+}
+
+// NeutralWhiteBalance leaves colors uncorrected
+var NeutralWhiteBalance = WhiteBalance{R: 1, G: 1, B: 1}
+
+// IsZero returns whether no calibration was set, e.g. an unset config field
+func (wb WhiteBalance) IsZero() bool {
+	return wb == WhiteBalance{}
+}
+
+// whiteBalanceOne is the fixed-point scale of a channel multiplier of 1.0
+const whiteBalanceOne = 256
+
+// Compile-time assertion that ColorLedStrip implements Peripheral
+var _ Peripheral = (*ColorLedStrip)(nil)
+var _ FaultReporter = (*ColorLedStrip)(nil)
+
+// ColorLedStrip represents an APA102 LED strip peripheral. It is safe to use from
+// several goroutines, e.g. a brightness knob changing the output stage while the
+// panel draws and shows frames.
 type ColorLedStrip struct {
+	mu       sync.Mutex
 	buffer   []color.RGBA
 	numLEDs  int
-	ledStrip *apa102.Device
+	ledStrip PixelWriter
+
+	// Output stage applied in Show, the buffer itself is left untouched
+	brightness uint8        // Global brightness 0-255
+	current    uint8        // APA102 driver current sent as the alpha channel, 255 is full
+	balance    [3]uint16    // White balance per channel, fixed point with whiteBalanceOne as 1.0
+	gamma      bool         // Apply gamma correction after brightness and white balance
+	output     []color.RGBA // Pre-allocated buffer for corrected colors
+
+	// Pixel fades advanced on Show, from the last frame shown before correction
+	frame  []color.RGBA
+	fades  []pixelFade // Allocated on the first fade
+	fading int         // Number of active fades
+
+	// Last frame written to the LEDs, so unchanged frames can be skipped
+	shown      []color.RGBA
+	shownValid bool
+	stats      FrameStats
+	fault      faultState
+
+	// Optional ring of the last frames shown, for debugging animation glitches
+	history frameHistory
+}
+
+// FrameStats counts frames written to the LEDs, frames skipped as unchanged and
+// frames whose write failed even after a retry
+type FrameStats struct {
+	Pushed  uint32
+	Skipped uint32
+	Failed  uint32
 }
 
 // NewColorLedStrip creates a new ColorLedStrip instance
 func NewColorLedStrip(numLEDs int) *ColorLedStrip {
 	return &ColorLedStrip{
-		numLEDs: numLEDs,
-		buffer:  make([]color.RGBA, numLEDs),
+		numLEDs:    numLEDs,
+		buffer:     make([]color.RGBA, numLEDs),
+		brightness: 255,
+		current:    255,
+		balance:    [3]uint16{whiteBalanceOne, whiteBalanceOne, whiteBalanceOne},
+		gamma:      true,
+		output:     make([]color.RGBA, numLEDs),
+		frame:      make([]color.RGBA, numLEDs),
+		shown:      make([]color.RGBA, numLEDs),
 	}
 }
 
-// Configure initializes the SPI interface and LED strip driver
-func (d *ColorLedStrip) Configure() error {
-	spi := machine.SPI0
-	err := spi.Configure(machine.SPIConfig{
-		// Default SPI configuration for APA102
-		// Frequency, pins, and mode can be customized as needed
-	})
-	if err != nil {
-		return err
-	}
-
-	d.ledStrip = apa102.New(spi)
-	return nil
+// NewColorLedStripWithWriter creates a ColorLedStrip that shows frames on the given writer
+// instead of a hardware driver, e.g. a terminal renderer in the simulator
+func NewColorLedStripWithWriter(numLEDs int, writer PixelWriter) *ColorLedStrip {
+	strip := NewColorLedStrip(numLEDs)
+	strip.ledStrip = writer
+	return strip
 }
 
 // SetPixel sets a single pixel to the specified color
 func (d *ColorLedStrip) SetPixel(index int, c color.RGBA) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if index >= 0 && index < d.numLEDs {
 		d.buffer[index] = c
 	}
@@ -46,18 +117,29 @@ func (d *ColorLedStrip) SetPixel(index int, c color.RGBA) {
 
 // SetAll sets all pixels to the specified color
 func (d *ColorLedStrip) SetAll(c color.RGBA) {
-	for i := 0; i < d.numLEDs; i++ {
-		d.buffer[i] = c
-	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fill(c)
 }
 
 // Clear turns off all LEDs (sets them to black)
 func (d *ColorLedStrip) Clear() {
-	d.SetAll(color.RGBA{R: 0, G: 0, B: 0, A: 255})
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fill(color.RGBA{R: 0, G: 0, B: 0, A: 255})
+}
+
+// fill sets every pixel in the buffer to c (must be called with mutex locked)
+func (d *ColorLedStrip) fill(c color.RGBA) {
+	for i := 0; i < d.numLEDs; i++ {
+		d.buffer[i] = c
+	}
 }
 
 // GetPixel returns the color of a specific pixel
 func (d *ColorLedStrip) GetPixel(index int) color.RGBA {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	if index >= 0 && index < d.numLEDs {
 		return d.buffer[index]
 	}
@@ -66,6 +148,8 @@ func (d *ColorLedStrip) GetPixel(index int) color.RGBA {
 
 // GetBuffer returns a copy of the current buffer
 func (d *ColorLedStrip) GetBuffer() []color.RGBA {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	bufferCopy := make([]color.RGBA, len(d.buffer))
 	copy(bufferCopy, d.buffer)
 	return bufferCopy
@@ -73,6 +157,8 @@ func (d *ColorLedStrip) GetBuffer() []color.RGBA {
 
 // SetBuffer sets the entire buffer to the provided colors
 func (d *ColorLedStrip) SetBuffer(colors []color.RGBA) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	minLen := len(colors)
 	if minLen > d.numLEDs {
 		minLen = d.numLEDs
@@ -90,6 +176,8 @@ func (d *ColorLedStrip) SetBufferAt(startIndex int, colors []color.RGBA) {
 	if len(colors) == 0 {
 		return
 	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
 
 	// Normalize start index to valid range
 	startIndex = startIndex % d.numLEDs
@@ -109,11 +197,161 @@ func (d *ColorLedStrip) SetBufferAt(startIndex int, colors []color.RGBA) {
 	}
 }
 
+// SetBrightness sets the global brightness (0-255) applied to every pixel on Show
+func (d *ColorLedStrip) SetBrightness(brightness uint8) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.brightness = brightness
+}
+
+// Brightness returns the global brightness
+func (d *ColorLedStrip) Brightness() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.brightness
+}
+
+// SetGammaCorrection turns gamma correction on Show on or off. It is on by default,
+// so colors are given in full perceptual range (e.g. {0, 128, 0} for half-bright
+// green); turn it off to write raw channel values to the LEDs.
+func (d *ColorLedStrip) SetGammaCorrection(enabled bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.gamma = enabled
+}
+
+// SetGlobalCurrent sets the APA102's 5-bit driver current (1-31, 31 is full). Lowering
+// it dims the strip in hardware, keeping the full 8-bit color range for dim scenes.
+func (d *ColorLedStrip) SetGlobalCurrent(level uint8) {
+	level = min(max(level, 1), 31)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.current = level<<3 | level>>2
+}
+
+// SetWhiteBalance sets the calibration multipliers (0-4) applied to each channel on Show
+func (d *ColorLedStrip) SetWhiteBalance(wb WhiteBalance) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i, f := range [3]float32{wb.R, wb.G, wb.B} {
+		d.balance[i] = uint16(min(max(f, 0), 4)*whiteBalanceOne + 0.5)
+	}
+}
+
+// WhiteBalance returns the calibration multipliers
+func (d *ColorLedStrip) WhiteBalance() WhiteBalance {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return WhiteBalance{
+		R: float32(d.balance[0]) / whiteBalanceOne,
+		G: float32(d.balance[1]) / whiteBalanceOne,
+		B: float32(d.balance[2]) / whiteBalanceOne,
+	}
+}
+
 // Show updates the LED strip with the current buffer contents
 func (d *ColorLedStrip) Show() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.push(d.outputColors())
+}
+
+// ShowIfDirty updates the LED strip only if the frame differs from the last one
+// written, saving SPI bandwidth when the display is static. It returns whether
+// the frame was written.
+func (d *ColorLedStrip) ShowIfDirty() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := d.outputColors()
+	if d.shownValid && slices.Equal(out, d.shown) {
+		d.stats.Skipped++
+		return false
+	}
+	d.push(out)
+	return true
+}
+
+// Stats returns the number of frames pushed and skipped
+func (d *ColorLedStrip) Stats() FrameStats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.stats
+}
+
+// ReportFaults sends a Fault on faults when writes to the LEDs start failing, and
+// again when they recover
+func (d *ColorLedStrip) ReportFaults(faults chan<- Fault, source string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.fault = faultState{faults: faults, source: source}
+}
+
+// push writes a frame to the LEDs and remembers it. A failed write is retried
+// once, e.g. after a glitch on a long cable; if that fails too the frame is
+// dropped and the next ShowIfDirty writes it again. (must be called with mutex locked)
+func (d *ColorLedStrip) push(out []color.RGBA) {
 	if d.ledStrip != nil {
-		d.ledStrip.WriteColors(d.buffer)
+		_, err := d.ledStrip.WriteColors(out)
+		if err != nil {
+			_, err = d.ledStrip.WriteColors(out)
+		}
+		d.fault.update(err)
+		if err != nil {
+			d.shownValid = false
+			d.stats.Failed++
+			return
+		}
+	}
+	copy(d.shown, out)
+	d.shownValid = true
+	d.stats.Pushed++
+	d.recordFrame(d.frame)
+}
+
+// outputColors advances pixel fades, then applies brightness, white balance and
+// gamma correction to the buffer (must be called with mutex locked)
+func (d *ColorLedStrip) outputColors() []color.RGBA {
+	d.applyFades(time.Now())
+	copy(d.frame, d.buffer)
+
+	neutral := d.balance == [3]uint16{whiteBalanceOne, whiteBalanceOne, whiteBalanceOne}
+	if d.brightness == 255 && d.current == 255 && !d.gamma && neutral {
+		return d.buffer
+	}
+
+	for i, c := range d.buffer {
+		d.output[i] = color.RGBA{
+			R: d.correct(c.R, 0),
+			G: d.correct(c.G, 1),
+			B: d.correct(c.B, 2),
+			A: uint8(uint16(c.A) * uint16(d.current) / 255),
+		}
 	}
+	return d.output
+}
+
+// correct scales a single channel by the brightness and its white balance, then
+// applies gamma (must be called with mutex locked)
+func (d *ColorLedStrip) correct(v uint8, channel int) uint8 {
+	scaled := uint8(min(uint32(v)*uint32(d.brightness)/255*uint32(d.balance[channel])/whiteBalanceOne, 255))
+	if d.gamma {
+		return gammaTable[scaled]
+	}
+	return scaled
+}
+
+// Start is a no-op, frames are pushed by whoever draws on the strip
+func (d *ColorLedStrip) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop cancels any fades and blanks the LEDs
+func (d *ColorLedStrip) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.cancelFades()
+	d.fill(color.RGBA{A: 255})
+	d.push(d.outputColors())
 }
 
 // NumLEDs returns the number of LEDs in the strip
@@ -0,0 +1,211 @@
+package peripheral
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrEmptyCombo is returned when adding a combo that watches no buttons
+var ErrEmptyCombo = errors.New("peripheral: combo needs at least one button")
+
+// ComboKind selects how the buttons of a combo must be pressed
+type ComboKind int
+
+const (
+	Simultaneous ComboKind = iota // All buttons held together for HoldTime
+	Sequential                    // Buttons pressed in order, each within Window of the last
+)
+
+// Combo describes a hidden multi-button gesture, e.g. reset+connect1 held
+// for 3 seconds, or connect1, connect2, connect3 pressed in order
+type Combo struct {
+	Name     string
+	Kind     ComboKind
+	Buttons  []ButtonReader
+	HoldTime time.Duration     // Simultaneous: how long all buttons must be held
+	Window   time.Duration     // Sequential: maximum time between consecutive presses
+	OnFire   func(name string) // Called from the detector goroutine when the combo completes
+
+	// Detection state
+	heldSince  time.Time
+	fired      bool
+	step       int
+	lastStepAt time.Time
+}
+
+// ComboDetector watches a set of ButtonReaders and fires combos
+type ComboDetector struct {
+	mu          sync.Mutex
+	combos      []*Combo
+	lastPressed map[ButtonReader]bool
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewComboDetector creates a new combo detector with no combos registered
+func NewComboDetector() *ComboDetector {
+	return &ComboDetector{
+		lastPressed: make(map[ButtonReader]bool),
+		stopTicker:  make(chan struct{}),
+	}
+}
+
+// Add registers a combo with the detector. A combo without buttons is rejected,
+// as it would fire on every poll.
+func (d *ComboDetector) Add(combo *Combo) error {
+	if len(combo.Buttons) == 0 {
+		return ErrEmptyCombo
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.combos = append(d.combos, combo)
+	return nil
+}
+
+// Start begins polling the buttons at the given rate
+func (d *ComboDetector) Start(pollRate time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		return
+	}
+
+	d.running = true
+	d.ticker = time.NewTicker(pollRate)
+	d.stopTicker = make(chan struct{})
+	ticker, stop := d.ticker, d.stopTicker
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				d.Update(time.Now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the detector's polling goroutine
+func (d *ComboDetector) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.running {
+		close(d.stopTicker)
+		if d.ticker != nil {
+			d.ticker.Stop()
+		}
+		d.running = false
+	}
+}
+
+// Update samples every watched button once and advances all combos.
+// It is called by the polling goroutine but may also be driven externally.
+func (d *ComboDetector) Update(now time.Time) {
+	d.mu.Lock()
+
+	// Sample each button once so combos sharing a button see the same reading
+	pressed := make(map[ButtonReader]bool)
+	rising := make(map[ButtonReader]bool)
+	for _, combo := range d.combos {
+		for _, button := range combo.Buttons {
+			if _, ok := pressed[button]; ok {
+				continue
+			}
+			state := button.IsPressed()
+			pressed[button] = state
+			rising[button] = state && !d.lastPressed[button]
+			d.lastPressed[button] = state
+		}
+	}
+
+	var fired []*Combo
+	for _, combo := range d.combos {
+		var done bool
+		switch combo.Kind {
+		case Simultaneous:
+			done = combo.updateSimultaneous(now, pressed)
+		case Sequential:
+			done = combo.updateSequential(now, rising)
+		}
+		if done {
+			fired = append(fired, combo)
+		}
+	}
+	d.mu.Unlock()
+
+	// Fire callbacks without holding the lock so they may call back into the detector
+	for _, combo := range fired {
+		if combo.OnFire != nil {
+			combo.OnFire(combo.Name)
+		}
+	}
+}
+
+// updateSimultaneous returns true once all buttons have been held for HoldTime
+func (c *Combo) updateSimultaneous(now time.Time, pressed map[ButtonReader]bool) bool {
+	for _, button := range c.Buttons {
+		if !pressed[button] {
+			c.heldSince = time.Time{}
+			c.fired = false
+			return false
+		}
+	}
+
+	if c.heldSince.IsZero() {
+		c.heldSince = now
+	}
+
+	// Fire once per hold; buttons must be released before it can fire again
+	if !c.fired && now.Sub(c.heldSince) >= c.HoldTime {
+		c.fired = true
+		return true
+	}
+	return false
+}
+
+// updateSequential returns true once every button has been pressed in order
+func (c *Combo) updateSequential(now time.Time, rising map[ButtonReader]bool) bool {
+	if len(c.Buttons) == 0 {
+		return false
+	}
+
+	// Give up on a partial sequence that took too long
+	if c.step > 0 && c.Window > 0 && now.Sub(c.lastStepAt) > c.Window {
+		c.step = 0
+	}
+
+	if rising[c.Buttons[c.step]] {
+		c.step++
+		c.lastStepAt = now
+		if c.step == len(c.Buttons) {
+			c.step = 0
+			return true
+		}
+		return false
+	}
+
+	// Any other button of the combo breaks the sequence
+	for _, button := range c.Buttons {
+		if !rising[button] {
+			continue
+		}
+		if button == c.Buttons[0] {
+			// Out of order, but a valid start of a new sequence
+			c.step = 1
+			c.lastStepAt = now
+		} else {
+			c.step = 0
+		}
+		break
+	}
+	return false
+}
@@ -0,0 +1,154 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDebounce is a debounce interval suitable for typical mechanical switches
+const DefaultDebounce = 20 * time.Millisecond
+
+var _ ButtonReader = (*DebouncedButton)(nil)
+
+// DebouncedButton filters contact bounce from another ButtonReader and adds
+// press/release edge detection and hold timing, driven by an internal sampler
+type DebouncedButton struct {
+	mu       sync.Mutex
+	input    ButtonReader
+	debounce time.Duration
+
+	// Debounce state
+	stable         bool      // Debounced pressed state
+	stableSince    time.Time // When the debounced state last changed
+	candidate      bool      // Most recent raw reading
+	candidateSince time.Time // When the raw reading last changed
+
+	// Latched edges, cleared when read
+	pressedEdge  bool
+	releasedEdge bool
+	latchedPress bool // Debounced press not yet seen by DrainPresses
+
+	// Ticker for sampling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewDebouncedButton wraps input so its state must be steady for debounce before it is accepted
+func NewDebouncedButton(input ButtonReader, debounce time.Duration) *DebouncedButton {
+	if debounce < 0 {
+		debounce = 0
+	}
+
+	now := time.Now()
+	return &DebouncedButton{
+		input:          input,
+		debounce:       debounce,
+		stableSince:    now,
+		candidateSince: now,
+	}
+}
+
+// Start begins sampling the input at the given rate
+func (b *DebouncedButton) Start(sampleRate time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		return
+	}
+
+	b.running = true
+	b.ticker = time.NewTicker(sampleRate)
+	b.stopTicker = make(chan struct{})
+
+	ticker, stop := b.ticker, b.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				b.Sample(time.Now())
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the internal sampler
+func (b *DebouncedButton) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.running {
+		close(b.stopTicker)
+		if b.ticker != nil {
+			b.ticker.Stop()
+		}
+		b.running = false
+	}
+}
+
+// Sample reads the raw input once and updates the debounced state.
+// It is called by the sampler but may also be driven externally.
+func (b *DebouncedButton) Sample(now time.Time) {
+	reading := b.input.IsPressed()
+
+	// Raw edges latched by the input's interrupt include bounce, so they're
+	// discarded; only presses that settle below are latched for the panel
+	DrainPresses(b.input)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if reading != b.candidate {
+		b.candidate = reading
+		b.candidateSince = now
+	}
+
+	// Accept the reading once it has been steady for the debounce interval
+	if b.candidate != b.stable && now.Sub(b.candidateSince) >= b.debounce {
+		b.stable = b.candidate
+		b.stableSince = now
+		if b.stable {
+			b.pressedEdge = true
+			b.latchedPress = true
+		} else {
+			b.releasedEdge = true
+		}
+	}
+}
+
+// IsPressed returns the debounced pressed state
+func (b *DebouncedButton) IsPressed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stable
+}
+
+// WasPressed returns true once for each debounced press
+func (b *DebouncedButton) WasPressed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	edge := b.pressedEdge
+	b.pressedEdge = false
+	return edge
+}
+
+// WasReleased returns true once for each debounced release
+func (b *DebouncedButton) WasReleased() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	edge := b.releasedEdge
+	b.releasedEdge = false
+	return edge
+}
+
+// HeldFor returns true if the button is pressed and has been for at least d
+func (b *DebouncedButton) HeldFor(d time.Duration) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stable && time.Since(b.stableSince) >= d
+}
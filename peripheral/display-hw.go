@@ -0,0 +1,74 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"image/color"
+	"machine"
+
+	"tinygo.org/x/drivers/ssd1306"
+	"tinygo.org/x/drivers/st7789"
+)
+
+// OLEDConfig describes an SSD1306 OLED on an I2C bus
+type OLEDConfig struct {
+	Bus     *machine.I2C // nil if no OLED is fitted
+	Address uint16       // I2C address, ssd1306.Address if zero
+	Width   int16        // 128 if zero
+	Height  int16        // 64 if zero
+}
+
+// NewOLEDDisplay configures the I2C bus and an SSD1306 OLED for text
+func NewOLEDDisplay(config OLEDConfig) (*Display, error) {
+	if config.Address == 0 {
+		config.Address = ssd1306.Address
+	}
+	if config.Width == 0 {
+		config.Width = 128
+	}
+	if config.Height == 0 {
+		config.Height = 64
+	}
+	if err := config.Bus.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz}); err != nil {
+		return nil, err
+	}
+
+	dev := ssd1306.NewI2C(config.Bus)
+	dev.Configure(ssd1306.Config{Width: config.Width, Height: config.Height, Address: config.Address})
+	dev.ClearDisplay()
+	return NewDisplay(&dev, 1), nil
+}
+
+// TFTConfig describes an ST7789 TFT on an SPI bus
+type TFTConfig struct {
+	Bus          *machine.SPI // nil if no TFT is fitted
+	ResetPin     machine.Pin
+	DCPin        machine.Pin
+	CSPin        machine.Pin
+	BacklightPin machine.Pin
+	Width        int16 // 240 if zero
+	Height       int16 // 240 if zero
+	Scale        int   // Text size multiplier, 2 if zero
+}
+
+// NewTFTDisplay configures the SPI bus and an ST7789 TFT for text
+func NewTFTDisplay(config TFTConfig) (*Display, error) {
+	if config.Width == 0 {
+		config.Width = 240
+	}
+	if config.Height == 0 {
+		config.Height = 240
+	}
+	if config.Scale == 0 {
+		config.Scale = 2
+	}
+	if err := config.Bus.Configure(machine.SPIConfig{Frequency: 8 * machine.MHz, Mode: 0}); err != nil {
+		return nil, err
+	}
+
+	dev := st7789.New(config.Bus, config.ResetPin, config.DCPin, config.CSPin, config.BacklightPin)
+	dev.Configure(st7789.Config{Width: config.Width, Height: config.Height})
+	dev.FillScreen(color.RGBA{0, 0, 0, 255})
+	dev.EnableBacklight(true)
+	return NewDisplay(&dev, config.Scale), nil
+}
@@ -0,0 +1,134 @@
+package peripheral
+
+import (
+	"image/color"
+	"strings"
+	"sync"
+
+	"tinygo.org/x/drivers"
+)
+
+// Display draws lines of text on a pixel display such as an SSD1306 OLED or
+// ST7789 TFT, using a built-in 5x7 font. Only rows whose text changed are
+// redrawn, since pixel writes to SPI and I2C screens are slow.
+type Display struct {
+	mu         sync.Mutex
+	screen     drivers.Displayer
+	scale      int16
+	foreground color.RGBA
+	background color.RGBA
+	lines      []string // Text currently drawn on each row
+	dirty      bool
+}
+
+// NewDisplay wraps a display driver, drawing text scale times the font size
+func NewDisplay(screen drivers.Displayer, scale int) *Display {
+	d := &Display{
+		screen:     screen,
+		scale:      int16(max(scale, 1)),
+		foreground: color.RGBA{255, 255, 255, 255},
+		background: color.RGBA{0, 0, 0, 255},
+	}
+	d.lines = make([]string, d.Rows())
+	return d
+}
+
+// SetColors sets the text and background colors, e.g. for a TFT; monochrome
+// screens light any pixel that isn't black
+func (d *Display) SetColors(foreground, background color.RGBA) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.foreground = foreground
+	d.background = background
+	// Redraw every row across the full width in the new colors
+	for i := range d.lines {
+		d.lines[i] = strings.Repeat("\x00", d.Columns())
+	}
+}
+
+// Rows returns how many lines of text fit on the screen
+func (d *Display) Rows() int {
+	_, height := d.screen.Size()
+	return int(height / (cellHeight * d.scale))
+}
+
+// Columns returns how many characters fit on a line
+func (d *Display) Columns() int {
+	width, _ := d.screen.Size()
+	return int(width / (cellWidth * d.scale))
+}
+
+// SetLine sets the text of a row (0-based), truncated to fit; rows off the screen are ignored
+func (d *Display) SetLine(row int, text string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if row < 0 || row >= len(d.lines) {
+		return
+	}
+	columns := d.Columns()
+	if len(text) > columns {
+		text = text[:columns]
+	}
+	if d.lines[row] == text {
+		return
+	}
+
+	// Pad with spaces to erase the rest of the old text
+	previous := len(d.lines[row])
+	d.lines[row] = text
+	for i := 0; i < columns && (i < len(text) || i < previous); i++ {
+		ch := ' '
+		if i < len(text) {
+			ch = rune(text[i])
+		}
+		d.drawChar(int16(i)*cellWidth*d.scale, int16(row)*cellHeight*d.scale, ch)
+	}
+	d.dirty = true
+}
+
+// Clear blanks every row
+func (d *Display) Clear() {
+	for row := 0; row < d.Rows(); row++ {
+		d.SetLine(row, "")
+	}
+}
+
+// Show sends changed rows to the screen
+func (d *Display) Show() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.dirty {
+		return nil
+	}
+	d.dirty = false
+	return d.screen.Display()
+}
+
+// drawChar draws a character cell with its top-left corner at x, y (must be called with mutex locked)
+func (d *Display) drawChar(x, y int16, ch rune) {
+	columns := glyph(ch)
+	for col := int16(0); col < cellWidth; col++ {
+		var bits byte
+		if col < glyphWidth {
+			bits = columns[col]
+		}
+		for row := int16(0); row < cellHeight; row++ {
+			c := d.background
+			if bits&(1<<row) != 0 {
+				c = d.foreground
+			}
+			d.fillCell(x+col*d.scale, y+row*d.scale, c)
+		}
+	}
+}
+
+// fillCell sets a scale x scale block of pixels (must be called with mutex locked)
+func (d *Display) fillCell(x, y int16, c color.RGBA) {
+	for dy := int16(0); dy < d.scale; dy++ {
+		for dx := int16(0); dx < d.scale; dx++ {
+			d.screen.SetPixel(x+dx, y+dy, c)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// ElevatorConfig selects the pins of the elevator button and its lights
+type ElevatorConfig struct {
+	Door    ButtonConfig // Door switch, pressed while open; Pin is machine.NoPin if no elevator is fitted
+	BlueLED machine.Pin  // Breathing blue button light
+	RedLED  machine.Pin  // Red door-closed light
+	PWM     PWM          // PWM peripheral driving both lights, not shared with the buzzer
+}
+
+// NewElevatorFromConfig configures the elevator lights on their PWM channels and
+// returns an elevator reading door, the configured (e.g. debounced) door switch
+func NewElevatorFromConfig(config ElevatorConfig, door ButtonReader) (*Elevator, error) {
+	if err := config.PWM.Configure(machine.PWMConfig{Period: pwmLightPeriod}); err != nil {
+		return nil, err
+	}
+
+	lights := make([]*PwmLight, 2)
+	for i, pin := range []machine.Pin{config.BlueLED, config.RedLED} {
+		pin.Configure(machine.PinConfig{Mode: machine.PinTimer})
+		ch, err := config.PWM.Channel(pin)
+		if err != nil {
+			return nil, err
+		}
+		lights[i] = NewPwmLightOnChannel(config.PWM, ch)
+	}
+	return NewElevator(door, lights[0], lights[1]), nil
+}
@@ -1,83 +1,220 @@
 package peripheral
 
 import (
-	"machine"
+	"context"
+	"sync"
 	"time"
 )
 
-type Elevator struct {
-	Period   int32
-	pwmTimer *machine.TCC
-	// Blue Channel
-	chB uint8
-	// Red Channel
-	chR         uint8
-	ButtonInput machine.Pin
+// DoorState is whether the elevator door is open or closed
+type DoorState int
+
+const (
+	DoorClosed DoorState = iota
+	DoorOpen
+)
+
+// String returns a string representation of the DoorState
+func (s DoorState) String() string {
+	switch s {
+	case DoorClosed:
+		return "Closed"
+	case DoorOpen:
+		return "Open"
+	default:
+		return "Unknown"
+	}
 }
 
-func (e *Elevator) Configure() {
-	// Configure the pins
-	buttonLedB := machine.PC17
-	buttonLedB.Configure(machine.PinConfig{Mode: machine.PinTimer})
+// DoorEvent is published when the elevator door opens or closes
+type DoorEvent struct {
+	State DoorState
+	At    time.Time
+}
 
-	buttonLedR := machine.PC16
-	buttonLedR.Configure(machine.PinConfig{Mode: machine.PinTimer})
+// DefaultElevatorPollRate polls the door and steps the light animation every 25ms
+const DefaultElevatorPollRate = 25 * time.Millisecond
 
-	buttonInput := machine.PB13
-	buttonInput.Configure(machine.PinConfig{Mode: machine.PinInputPulldown})
-	e.ButtonInput = buttonInput
+// Compile-time assertion that Elevator implements Peripheral
+var _ Peripheral = (*Elevator)(nil)
 
-	// Set up PWM timer
-	e.pwmTimer = machine.TCC0
-	e.pwmTimer.Configure(machine.PWMConfig{})
+const (
+	elevatorBreatheStep = 26  // Blue brightness change per poll, a full breath takes about 20 polls
+	elevatorRedLevel    = 112 // Red brightness while the door is closed, about 10% duty after gamma
+)
 
-	ch, err := e.pwmTimer.Channel(buttonLedB)
-	if err != nil {
-		println("Failed to get PWM channel:", err)
-		return
-	}
-	e.chB = ch
+// Elevator animates the elevator button lights, blue breathing and red lit while
+// the door is closed, and publishes the door switch opening and closing
+type Elevator struct {
+	mu        sync.Mutex
+	door      ButtonReader // Pressed while the door is open
+	blue, red *PwmLight
+	state     DoorState
+	primed    bool
+	level     int // Blue breathing brightness
+	direction int
+	channels  []chan<- DoorEvent
+	callbacks []func(DoorEvent)
 
-	ch, err = e.pwmTimer.Channel(buttonLedR)
-	if err != nil {
-		println("Failed to get PWM channel:", err)
-		return
+	// Ticker for polling
+	pollRate   time.Duration
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewElevator creates an elevator on a door switch (pressed while open) and its
+// blue and red button lights; either light may be nil
+func NewElevator(door ButtonReader, blue, red *PwmLight) *Elevator {
+	return &Elevator{
+		door:      door,
+		blue:      blue,
+		red:       red,
+		direction: 1,
+		pollRate:  DefaultElevatorPollRate,
 	}
-	e.chR = ch
+}
 
+// SetPollRate sets how often Start polls the door and steps the animation, from the next Start
+func (e *Elevator) SetPollRate(pollRate time.Duration) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pollRate = pollRate
 }
 
-func (e *Elevator) Run() {
-	// Set the PWM period (frequency)
-	e.pwmTimer.SetPeriod(1000) // 1 kHz
-
-	// Ramp up and down the PWM duty cycle
-	max := e.pwmTimer.Top()
-
-	i := uint32(0)
-	onCount := max / uint32(10)
-	direction := 1
-	e.pwmTimer.Set(e.chR, max/10)
-	for {
-		if direction == 1 {
-			i = i + onCount
-		} else {
-			i = i - onCount
-		}
-		if i >= max {
-			i = max
-			direction = -1
+// Configure is a no-op, the lights are set up by NewElevatorFromConfig and the
+// door switch by its reader
+func (e *Elevator) Configure() error {
+	return nil
+}
+
+// State returns the door state as of the last poll
+func (e *Elevator) State() DoorState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.state
+}
+
+// Subscribe registers a channel that receives every door change.
+// Sends never block the poller; changes are dropped if the channel is full,
+// so use a buffered channel.
+func (e *Elevator) Subscribe(ch chan<- DoorEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.channels = append(e.channels, ch)
+}
+
+// OnDoor registers a callback run for every door change, on the polling goroutine
+func (e *Elevator) OnDoor(callback func(DoorEvent)) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.callbacks = append(e.callbacks, callback)
+}
+
+// Start begins polling the door and animating the lights at the poll rate,
+// until ctx is done or Stop is called
+func (e *Elevator) Start(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return nil
+	}
+	e.running = true
+	e.ticker = time.NewTicker(e.pollRate)
+	e.stopTicker = make(chan struct{})
+
+	ticker, stop := e.ticker, e.stopTicker
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				e.Poll(now)
+			case <-stop:
+				return
+			case <-ctx.Done():
+				e.mu.Lock()
+				if e.stopTicker == stop {
+					e.stop()
+				}
+				e.mu.Unlock()
+				return
+			}
 		}
-		if i <= 0 {
-			i = 0
-			direction = 1
+	}()
+	return nil
+}
+
+// Stop stops polling and turns the lights off
+func (e *Elevator) Stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stop()
+}
+
+// stop stops the poller and turns the lights off (must be called with mutex locked)
+func (e *Elevator) stop() {
+	if e.running {
+		close(e.stopTicker)
+		if e.ticker != nil {
+			e.ticker.Stop()
 		}
-		if e.ButtonInput.Get() == true {
-			e.pwmTimer.Set(e.chR, 0)
-		} else {
-			e.pwmTimer.Set(e.chR, max/10)
+		e.running = false
+	}
+	e.setLight(e.blue, 0)
+	e.setLight(e.red, 0)
+}
+
+// Poll reads the door, steps the animation and publishes a door change.
+// It is called by the poller but may also be driven externally.
+func (e *Elevator) Poll(now time.Time) {
+	e.mu.Lock()
+	state := DoorClosed
+	if e.door != nil && e.door.IsPressed() {
+		state = DoorOpen
+	}
+	changed := !e.primed || state != e.state
+	e.state = state
+	e.primed = true
+
+	// Blue breathes up and down, red shows the door is closed
+	e.level += e.direction * elevatorBreatheStep
+	if e.level >= 255 {
+		e.level, e.direction = 255, -1
+	} else if e.level <= 0 {
+		e.level, e.direction = 0, 1
+	}
+	e.setLight(e.blue, uint8(e.level))
+	if state == DoorClosed {
+		e.setLight(e.red, elevatorRedLevel)
+	} else {
+		e.setLight(e.red, 0)
+	}
+
+	channels := append([]chan<- DoorEvent(nil), e.channels...)
+	callbacks := make([]func(DoorEvent), len(e.callbacks))
+	copy(callbacks, e.callbacks)
+	e.mu.Unlock()
+
+	if !changed {
+		return
+	}
+	event := DoorEvent{State: state, At: now}
+	for _, ch := range channels {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber isn't keeping up, drop the change
 		}
-		e.pwmTimer.Set(e.chB, i)
-		time.Sleep(time.Millisecond * 25)
+	}
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}
+
+// setLight sets a light if it is fitted (must be called with mutex locked)
+func (e *Elevator) setLight(light *PwmLight, brightness uint8) {
+	if light != nil {
+		light.SetBrightness(brightness)
 	}
 }
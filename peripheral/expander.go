@@ -0,0 +1,144 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"errors"
+	"machine"
+
+	"tinygo.org/x/drivers/mcp23017"
+)
+
+// DefaultExpanderAddress is the MCP23017 I2C address with A0-A2 tied low
+const DefaultExpanderAddress = 0x20
+
+// ExpanderConfig describes an MCP23017 GPIO expander on an I2C bus
+type ExpanderConfig struct {
+	Bus     *machine.I2C // nil if no expander is fitted
+	Address uint8        // I2C address, DefaultExpanderAddress if zero
+}
+
+// GPIOExpander adds 16 inputs/outputs over I2C using an MCP23017
+type GPIOExpander struct {
+	dev *mcp23017.Device
+}
+
+// NewGPIOExpander configures the I2C bus and connects to the expander
+func NewGPIOExpander(config ExpanderConfig) (*GPIOExpander, error) {
+	if config.Address == 0 {
+		config.Address = DefaultExpanderAddress
+	}
+	if err := config.Bus.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz}); err != nil {
+		return nil, err
+	}
+
+	dev, err := mcp23017.NewI2C(config.Bus, config.Address)
+	if err != nil {
+		return nil, err
+	}
+	return &GPIOExpander{dev: dev}, nil
+}
+
+// Button returns a button on expander pin 0-15
+func (e *GPIOExpander) Button(pin int, pull PullMode, activeLow bool) *ExpanderButton {
+	return &ExpanderButton{
+		pin:       e.dev.Pin(pin),
+		pull:      pull,
+		activeLow: activeLow,
+	}
+}
+
+// ErrNoExpander is returned when a button is wired to a GPIO expander that isn't fitted
+var ErrNoExpander = errors.New("peripheral: button needs a GPIO expander")
+
+// ErrNoTouchPad is returned when a button is wired to a touch controller that isn't
+// fitted, or to a pad it doesn't have
+var ErrNoTouchPad = errors.New("peripheral: button needs a touch pad 0-11")
+
+// ConfigureButton creates and configures a button from its wiring config, reading
+// a board pin, a pin on expander or a pad on touch (either may be nil if not fitted).
+// Touch pads are read as the touch sensor polls them, so it must be started.
+func ConfigureButton(config ButtonConfig, expander *GPIOExpander, touch *TouchSensor) (ButtonReader, error) {
+	if config.Touch {
+		if touch == nil || config.TouchPad < 0 || config.TouchPad >= TouchPads {
+			return nil, ErrNoTouchPad
+		}
+		return touch.Pad(config.TouchPad), nil
+	}
+	if !config.Expander {
+		button := NewButtonFromConfig(config)
+		return button, button.Configure()
+	}
+
+	if expander == nil {
+		return nil, ErrNoExpander
+	}
+	button := expander.Button(config.ExpanderPin, config.Pull, config.ActiveLow)
+	return button, button.Configure()
+}
+
+// Output returns an output on expander pin 0-15
+func (e *GPIOExpander) Output(pin int) *ExpanderOutput {
+	return &ExpanderOutput{pin: e.dev.Pin(pin)}
+}
+
+// Compile-time assertion that ExpanderButton implements ButtonReader and FaultReporter
+var _ ButtonReader = (*ExpanderButton)(nil)
+var _ FaultReporter = (*ExpanderButton)(nil)
+
+// ExpanderButton reads a button wired to a GPIO expander pin
+type ExpanderButton struct {
+	pin       mcp23017.Pin
+	pull      PullMode
+	activeLow bool
+	fault     faultState
+}
+
+// ReportFaults sends a Fault on faults when I2C reads start failing, and again when they recover
+func (b *ExpanderButton) ReportFaults(faults chan<- Fault, source string) {
+	b.fault = faultState{faults: faults, source: source}
+}
+
+// Configure sets the pin as an input; the MCP23017 only has pull-ups, so
+// PullDown behaves like PullNone
+func (b *ExpanderButton) Configure() error {
+	mode := mcp23017.Input
+	if b.pull == PullUp {
+		mode |= mcp23017.Pullup
+	}
+	return b.pin.SetMode(mode)
+}
+
+// IsPressed returns true if the input is currently pressed/active.
+// A failed I2C read reports the button as released.
+func (b *ExpanderButton) IsPressed() bool {
+	reading, err := b.pin.Get()
+	b.fault.update(err)
+	if err != nil {
+		return false
+	}
+	if b.activeLow {
+		return !reading
+	}
+	return reading
+}
+
+// ExpanderOutput drives a GPIO expander pin, e.g. for an indicator lamp or relay
+type ExpanderOutput struct {
+	pin mcp23017.Pin
+}
+
+// Configure sets the pin as an output
+func (o *ExpanderOutput) Configure() error {
+	return o.pin.SetMode(mcp23017.Output)
+}
+
+// Set drives the pin high (true) or low (false)
+func (o *ExpanderOutput) Set(value bool) error {
+	return o.pin.Set(value)
+}
+
+// Toggle inverts the pin
+func (o *ExpanderOutput) Toggle() error {
+	return o.pin.Toggle()
+}
@@ -0,0 +1,50 @@
+package peripheral
+
+import "time"
+
+// Fault reports a peripheral failing after startup, e.g. an SPI write or I2C
+// read erroring, or recovering again
+type Fault struct {
+	Source string // Name the peripheral reports under, e.g. "LED strip"
+	Err    error  // nil when the peripheral has recovered
+	At     time.Time
+}
+
+// String returns the fault as "<source>: <err>" or "<source> recovered"
+func (f Fault) String() string {
+	if f.Err == nil {
+		return f.Source + " recovered"
+	}
+	return f.Source + ": " + f.Err.Error()
+}
+
+// FaultReporter is a peripheral that reports failures after startup on a
+// channel shared by every peripheral, e.g. one consumed by the panel
+type FaultReporter interface {
+	ReportFaults(faults chan<- Fault, source string)
+}
+
+// faultState tracks whether a peripheral is failing, so a persistent failure
+// is reported once rather than on every write
+type faultState struct {
+	faults  chan<- Fault
+	source  string
+	failing bool
+}
+
+// update records the result of an operation, reporting the peripheral starting
+// to fail or recovering. Sends never block; faults are dropped if the channel is full.
+func (s *faultState) update(err error) {
+	failing := err != nil
+	if failing == s.failing {
+		return
+	}
+	s.failing = failing
+	if s.faults == nil {
+		return
+	}
+	select {
+	case s.faults <- Fault{Source: s.source, Err: err, At: time.Now()}:
+	default:
+	}
+}
@@ -0,0 +1,340 @@
+package peripheral
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInputEvent is returned when a recorded input line can't be parsed
+var ErrInputEvent = errors.New("input event must be <milliseconds> <input> <value>")
+
+// InputEvent is a single recorded input change
+type InputEvent struct {
+	At    time.Duration // Offset from the start of the recording
+	Input string        // Input name, e.g. "connect1" or an analog role such as "Brightness"
+	Value int           // 0 or 1 for buttons, 0-100 for analog inputs
+}
+
+// String formats the event as a line ParseInputEvent reads back
+func (e InputEvent) String() string {
+	return fmt.Sprintf("%d %s %d", e.At.Milliseconds(), e.Input, e.Value)
+}
+
+// ParseInputEvent parses a line written by InputEvent.String, e.g. "1250 connect2 1"
+func ParseInputEvent(line string) (InputEvent, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return InputEvent{}, ErrInputEvent
+	}
+	ms, err := strconv.Atoi(fields[0])
+	if err != nil || ms < 0 {
+		return InputEvent{}, ErrInputEvent
+	}
+	value, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return InputEvent{}, ErrInputEvent
+	}
+	return InputEvent{At: time.Duration(ms) * time.Millisecond, Input: fields[1], Value: value}, nil
+}
+
+// InputRecorderConfig holds input recorder options
+type InputRecorderConfig struct {
+	MaxEvents int       // Events kept in memory, the oldest are dropped beyond this
+	Output    io.Writer // Optional, every event is also written here as a line as it happens
+	Prefix    string    // Written before each line on Output, e.g. a console command that loads it back
+}
+
+// DefaultInputRecorderConfig returns settings that keep a few minutes of a live show in memory
+func DefaultInputRecorderConfig() InputRecorderConfig {
+	return InputRecorderConfig{
+		MaxEvents: 512,
+	}
+}
+
+// recordedButton is a button watched by the recorder
+type recordedButton struct {
+	name    string
+	reader  ButtonReader
+	pressed bool
+}
+
+// InputRecorder watches named buttons and analog values and logs every change
+// with its time offset, so a live show can be reproduced later with an InputReplayer
+type InputRecorder struct {
+	mu        sync.Mutex
+	config    InputRecorderConfig
+	buttons   []*recordedButton
+	analogs   map[string]int
+	events    []InputEvent
+	recording bool
+	startedAt time.Time
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewInputRecorder creates a recorder with no inputs watched
+func NewInputRecorder(config InputRecorderConfig) *InputRecorder {
+	config.MaxEvents = max(config.MaxEvents, 1)
+	return &InputRecorder{
+		config:  config,
+		analogs: make(map[string]int),
+	}
+}
+
+// AddButton watches a button under name
+func (r *InputRecorder) AddButton(name string, reader ButtonReader) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buttons = append(r.buttons, &recordedButton{name: name, reader: reader, pressed: reader.IsPressed()})
+}
+
+// RecordAnalog notes an analog value, e.g. from an AnalogInputs change callback
+func (r *InputRecorder) RecordAnalog(name string, percentage int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.analogs[name] = percentage
+	if r.recording {
+		r.record(time.Now(), name, percentage)
+	}
+}
+
+// StartRecording clears the previous recording and starts a new one, beginning
+// with the current state of every input so replay starts from the same place
+func (r *InputRecorder) StartRecording() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.events = r.events[:0]
+	r.recording = true
+	r.startedAt = now
+	for _, button := range r.buttons {
+		r.record(now, button.name, boolValue(button.pressed))
+	}
+	for name, percentage := range r.analogs {
+		r.record(now, name, percentage)
+	}
+}
+
+// StopRecording ends the recording and returns its events
+func (r *InputRecorder) StopRecording() []InputEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = false
+	return append([]InputEvent(nil), r.events...)
+}
+
+// IsRecording returns whether a recording is in progress
+func (r *InputRecorder) IsRecording() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.recording
+}
+
+// Events returns a copy of the current or last recording
+func (r *InputRecorder) Events() []InputEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]InputEvent(nil), r.events...)
+}
+
+// Start begins polling the buttons at the given rate
+func (r *InputRecorder) Start(pollRate time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+	r.running = true
+	r.ticker = time.NewTicker(pollRate)
+	r.stopTicker = make(chan struct{})
+
+	ticker, stop := r.ticker, r.stopTicker
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				r.Poll(now)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (r *InputRecorder) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		close(r.stopTicker)
+		if r.ticker != nil {
+			r.ticker.Stop()
+		}
+		r.running = false
+	}
+}
+
+// Poll reads every button once and records the ones that changed.
+// It is called by the poller but may also be driven externally.
+func (r *InputRecorder) Poll(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, button := range r.buttons {
+		pressed := button.reader.IsPressed()
+		if pressed == button.pressed {
+			continue
+		}
+		button.pressed = pressed
+		if r.recording {
+			r.record(now, button.name, boolValue(pressed))
+		}
+	}
+}
+
+// record appends an event and writes it to the output (must be called with mutex locked)
+func (r *InputRecorder) record(now time.Time, name string, value int) {
+	event := InputEvent{At: max(now.Sub(r.startedAt), 0), Input: name, Value: value}
+	if len(r.events) >= r.config.MaxEvents {
+		copy(r.events, r.events[1:])
+		r.events = r.events[:len(r.events)-1]
+	}
+	r.events = append(r.events, event)
+
+	if r.config.Output != nil {
+		io.WriteString(r.config.Output, r.config.Prefix+event.String()+"\r\n")
+	}
+}
+
+// boolValue converts a button state to an event value
+func boolValue(pressed bool) int {
+	if pressed {
+		return 1
+	}
+	return 0
+}
+
+// InputReplayer feeds a recording back through mock buttons and an analog
+// callback at the pace it was recorded
+type InputReplayer struct {
+	mu       sync.Mutex
+	buttons  map[string]*MockButton
+	onAnalog func(name string, percentage int)
+	stop     chan struct{}
+	playing  bool
+}
+
+// NewInputReplayer creates a replayer with no inputs
+func NewInputReplayer() *InputReplayer {
+	return &InputReplayer{
+		buttons: make(map[string]*MockButton),
+	}
+}
+
+// Button returns the mock button replayed events named name drive, created on
+// first use. Combine it with the physical input using AnyPressed.
+func (p *InputReplayer) Button(name string) *MockButton {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	button, ok := p.buttons[name]
+	if !ok {
+		button = NewMockButton()
+		p.buttons[name] = button
+	}
+	return button
+}
+
+// OnAnalog sets the callback replayed events for inputs without a button are passed to
+func (p *InputReplayer) OnAnalog(callback func(name string, percentage int)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onAnalog = callback
+}
+
+// Play starts replaying events, returning false if a replay is already running
+func (p *InputReplayer) Play(events []InputEvent) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.playing {
+		return false
+	}
+	p.playing = true
+	p.stop = make(chan struct{})
+	go p.run(append([]InputEvent(nil), events...), p.stop)
+	return true
+}
+
+// Stop ends a replay early, releasing every replayed button
+func (p *InputReplayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.playing {
+		close(p.stop)
+		p.playing = false
+		p.releaseAll()
+	}
+}
+
+// IsPlaying returns whether a replay is running
+func (p *InputReplayer) IsPlaying() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.playing
+}
+
+// run applies each event at its offset from the start of the replay
+func (p *InputReplayer) run(events []InputEvent, stop chan struct{}) {
+	start := time.Now()
+	for _, event := range events {
+		if wait := event.At - time.Since(start); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				return
+			}
+		}
+		p.apply(event)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stop == stop && p.playing {
+		p.playing = false
+		p.releaseAll()
+	}
+}
+
+// apply sets the input an event names
+func (p *InputReplayer) apply(event InputEvent) {
+	p.mu.Lock()
+	button, ok := p.buttons[event.Input]
+	onAnalog := p.onAnalog
+	p.mu.Unlock()
+
+	if ok {
+		button.SetPressed(event.Value != 0)
+	} else if onAnalog != nil {
+		onAnalog(event.Input, event.Value)
+	}
+}
+
+// releaseAll leaves every replayed button released once playback ends (must be called with mutex locked)
+func (p *InputReplayer) releaseAll() {
+	for _, button := range p.buttons {
+		button.SetPressed(false)
+	}
+}
@@ -1,7 +1,12 @@
 package peripheral
 
-// PeriphConfiger is an interface that defines the Config method
-// that all peripherals must implement
-type PeriphConfiger interface {
-	Config()
+import "context"
+
+// Peripheral is the lifecycle shared by the board's peripherals: Configure sets
+// up the hardware once, Start begins any background work until ctx is done or
+// Stop is called, and Stop halts it and leaves the outputs off
+type Peripheral interface {
+	Configure() error
+	Start(ctx context.Context) error
+	Stop()
 }
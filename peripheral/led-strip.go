@@ -0,0 +1,87 @@
+package peripheral
+
+import (
+	"image/color"
+	"sync"
+	"time"
+)
+
+// LedStrip is a buffered strip of pixels shown in frames. ColorLedStrip drives
+// real (or simulated) LEDs; MockStrip records frames so rendering can be checked.
+type LedStrip interface {
+	NumLEDs() int
+	SetPixel(index int, c color.RGBA)
+	SetAll(c color.RGBA)
+	Clear()
+	GetPixel(index int) color.RGBA
+	GetBuffer() []color.RGBA
+	SetBuffer(colors []color.RGBA)
+	SetBufferAt(startIndex int, colors []color.RGBA)
+	Show()
+	ShowIfDirty() bool
+}
+
+// PixelFader is a strip that can fade pixels over the next frames, as ColorLedStrip does
+type PixelFader interface {
+	FadePixel(index int, target color.RGBA, duration time.Duration)
+	FadeAll(target color.RGBA, duration time.Duration)
+	IsFading() bool
+	CancelFades()
+}
+
+var _ PixelFader = (*ColorLedStrip)(nil)
+
+// MockStrip is a LedStrip that records every frame shown instead of driving LEDs
+type MockStrip struct {
+	*ColorLedStrip
+	recorder *frameRecorder
+}
+
+// NewMockStrip creates a mock strip of numLEDs pixels
+func NewMockStrip(numLEDs int) *MockStrip {
+	recorder := &frameRecorder{}
+	return &MockStrip{
+		ColorLedStrip: NewColorLedStripWithWriter(numLEDs, recorder),
+		recorder:      recorder,
+	}
+}
+
+// Frames returns copies of all frames shown so far, oldest first
+func (m *MockStrip) Frames() [][]color.RGBA {
+	m.recorder.mu.Lock()
+	defer m.recorder.mu.Unlock()
+	return append([][]color.RGBA(nil), m.recorder.frames...)
+}
+
+// LastFrame returns the most recently shown frame, or nil if none has been shown
+func (m *MockStrip) LastFrame() []color.RGBA {
+	m.recorder.mu.Lock()
+	defer m.recorder.mu.Unlock()
+	if len(m.recorder.frames) == 0 {
+		return nil
+	}
+	return m.recorder.frames[len(m.recorder.frames)-1]
+}
+
+// ResetFrames discards the recorded frames
+func (m *MockStrip) ResetFrames() {
+	m.recorder.mu.Lock()
+	defer m.recorder.mu.Unlock()
+	m.recorder.frames = nil
+}
+
+// frameRecorder is a PixelWriter keeping a copy of each frame written
+type frameRecorder struct {
+	mu     sync.Mutex
+	frames [][]color.RGBA
+}
+
+func (r *frameRecorder) WriteColors(cs []color.RGBA) (int, error) {
+	frame := make([]color.RGBA, len(cs))
+	copy(frame, cs)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.frames = append(r.frames, frame)
+	return len(cs), nil
+}
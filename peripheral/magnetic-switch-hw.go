@@ -0,0 +1,31 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+	"time"
+)
+
+// MagneticSwitchConfig describes how a hall-effect sensor or reed switch is wired
+type MagneticSwitchConfig struct {
+	Pin       machine.Pin   // machine.NoPin if not fitted
+	Pull      PullMode      // PullUp for open-collector hall sensors such as the A3144
+	ActiveLow bool          // true if the pin reads low with the magnet present, as hall sensors do
+	Debounce  time.Duration // DefaultMagnetDebounce if 0
+}
+
+// HallSensorConfig returns the wiring of a typical open-collector hall sensor on pin,
+// pulled up and reading low with the magnet present
+func HallSensorConfig(pin machine.Pin) MagneticSwitchConfig {
+	return MagneticSwitchConfig{Pin: pin, Pull: PullUp, ActiveLow: true}
+}
+
+// NewMagneticSwitchFromConfig creates a magnetic switch on a board pin, set up by Configure
+func NewMagneticSwitchFromConfig(config MagneticSwitchConfig) *MagneticSwitch {
+	if config.Debounce == 0 {
+		config.Debounce = DefaultMagnetDebounce
+	}
+	sensor := NewButtonFromConfig(ButtonConfig{Pin: config.Pin, Pull: config.Pull, ActiveLow: config.ActiveLow})
+	return NewMagneticSwitch(sensor, config.Debounce)
+}
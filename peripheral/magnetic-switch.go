@@ -0,0 +1,109 @@
+package peripheral
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMagnetDebounce is longer than DefaultDebounce: a magnet sliding into a
+// dock crosses the sensor's threshold several times before it is seated
+const DefaultMagnetDebounce = 60 * time.Millisecond
+
+// magnetSampleRate is how often a magnetic switch samples its sensor
+const magnetSampleRate = 5 * time.Millisecond
+
+// Compile-time assertion that MagneticSwitch implements ButtonReader and Peripheral
+var _ ButtonReader = (*MagneticSwitch)(nil)
+var _ Peripheral = (*MagneticSwitch)(nil)
+
+// configurer is an input that needs setting up before it is read, e.g. a Button
+type configurer interface {
+	Configure() error
+}
+
+// MagneticSwitch reads a hall-effect sensor or reed switch that detects a magnet,
+// e.g. one in the base of a battery seated in its charging dock. It is pressed
+// while the magnet is present, debounced so a battery being seated reads cleanly.
+type MagneticSwitch struct {
+	mu        sync.Mutex
+	sensor    ButtonReader
+	debounced *DebouncedButton
+
+	stop    chan struct{}
+	running bool
+}
+
+// NewMagneticSwitch debounces sensor, which reads pressed while the magnet is present
+func NewMagneticSwitch(sensor ButtonReader, debounce time.Duration) *MagneticSwitch {
+	return &MagneticSwitch{
+		sensor:    sensor,
+		debounced: NewDebouncedButton(sensor, debounce),
+	}
+}
+
+// Configure sets up the sensor input, if it needs it
+func (s *MagneticSwitch) Configure() error {
+	if c, ok := s.sensor.(configurer); ok {
+		return c.Configure()
+	}
+	return nil
+}
+
+// Start samples the sensor until ctx is done or Stop is called
+func (s *MagneticSwitch) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+	s.debounced.Start(magnetSampleRate)
+
+	stop := s.stop
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.mu.Lock()
+			if s.stop == stop {
+				s.halt()
+			}
+			s.mu.Unlock()
+		case <-stop:
+		}
+	}()
+	return nil
+}
+
+// Stop stops sampling the sensor
+func (s *MagneticSwitch) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halt()
+}
+
+// halt stops the sampler (must be called with mutex locked)
+func (s *MagneticSwitch) halt() {
+	if s.running {
+		close(s.stop)
+		s.debounced.Stop()
+		s.running = false
+	}
+}
+
+// IsPressed returns whether the magnet is present, debounced
+func (s *MagneticSwitch) IsPressed() bool {
+	return s.debounced.IsPressed()
+}
+
+// WasSeated returns true once each time the magnet arrives, e.g. a battery is docked
+func (s *MagneticSwitch) WasSeated() bool {
+	return s.debounced.WasPressed()
+}
+
+// WasRemoved returns true once each time the magnet leaves
+func (s *MagneticSwitch) WasRemoved() bool {
+	return s.debounced.WasReleased()
+}
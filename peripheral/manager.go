@@ -0,0 +1,136 @@
+package peripheral
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// PeripheralError reports a peripheral that failed to configure or start
+type PeripheralError struct {
+	Name string
+	Op   string // "configure" or "start"
+	Err  error
+}
+
+// Error returns the failure as "<name> <op>: <err>"
+func (e *PeripheralError) Error() string {
+	return e.Name + " " + e.Op + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *PeripheralError) Unwrap() error {
+	return e.Err
+}
+
+// PeripheralStatus is where a managed peripheral is in its lifecycle
+type PeripheralStatus struct {
+	Name       string
+	Configured bool
+	Started    bool
+	Err        error // Last configure or start failure, nil if none
+}
+
+// managedPeripheral is a peripheral and its lifecycle status
+type managedPeripheral struct {
+	peripheral Peripheral
+	status     PeripheralStatus
+}
+
+// PeripheralManager configures and starts a set of peripherals, keeps going past
+// any that fail so one missing breakout doesn't stop the show, and stops the
+// started ones in reverse order
+type PeripheralManager struct {
+	mu          sync.Mutex
+	peripherals []*managedPeripheral
+	faults      chan<- Fault
+}
+
+// NewPeripheralManager creates an empty peripheral manager
+func NewPeripheralManager() *PeripheralManager {
+	return &PeripheralManager{}
+}
+
+// Add registers a peripheral under a name used in failures and status, e.g. "neopixel".
+// It is configured and started by the next Start.
+func (m *PeripheralManager) Add(name string, p Peripheral) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.peripherals = append(m.peripherals, &managedPeripheral{
+		peripheral: p,
+		status:     PeripheralStatus{Name: name},
+	})
+	if reporter, ok := p.(FaultReporter); ok && m.faults != nil {
+		reporter.ReportFaults(m.faults, name)
+	}
+}
+
+// ReportFaults has every peripheral that can report failures after startup, added
+// before or after, send them on faults under its name
+func (m *PeripheralManager) ReportFaults(faults chan<- Fault) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.faults = faults
+	for _, mp := range m.peripherals {
+		if reporter, ok := mp.peripheral.(FaultReporter); ok {
+			reporter.ReportFaults(faults, mp.status.Name)
+		}
+	}
+}
+
+// Start configures and starts every peripheral not started yet, so it can be called
+// again after adding more. It returns the failures joined, each a *PeripheralError;
+// a peripheral that failed is retried by the next Start.
+func (m *PeripheralManager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var failures []error
+	for _, mp := range m.peripherals {
+		if mp.status.Started {
+			continue
+		}
+		if !mp.status.Configured {
+			if err := mp.peripheral.Configure(); err != nil {
+				mp.status.Err = &PeripheralError{Name: mp.status.Name, Op: "configure", Err: err}
+				failures = append(failures, mp.status.Err)
+				continue
+			}
+			mp.status.Configured = true
+		}
+		if err := mp.peripheral.Start(ctx); err != nil {
+			mp.status.Err = &PeripheralError{Name: mp.status.Name, Op: "start", Err: err}
+			failures = append(failures, mp.status.Err)
+			continue
+		}
+		mp.status.Started = true
+		mp.status.Err = nil
+	}
+	return errors.Join(failures...)
+}
+
+// Stop stops every started peripheral, last started first
+func (m *PeripheralManager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := len(m.peripherals) - 1; i >= 0; i-- {
+		mp := m.peripherals[i]
+		if mp.status.Started {
+			mp.peripheral.Stop()
+			mp.status.Started = false
+		}
+	}
+}
+
+// Status returns the lifecycle status of every peripheral, in the order added
+func (m *PeripheralManager) Status() []PeripheralStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]PeripheralStatus, len(m.peripherals))
+	for i, mp := range m.peripherals {
+		statuses[i] = mp.status
+	}
+	return statuses
+}
@@ -0,0 +1,137 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// MicrophoneConfig holds options for following a microphone's loudness
+type MicrophoneConfig struct {
+	SamplesPerTick int     // Readings taken back to back on every tick, to catch the waveform's peaks
+	Attack         float32 // Weight (0-1) a louder reading gets in the envelope, 1 follows instantly
+	Release        float32 // Weight (0-1) a quieter reading gets, small values fall away slowly
+	Gain           float32 // Multiplier on the envelope, for quiet microphone boards
+}
+
+// DefaultMicrophoneConfig returns settings for an electret breakout such as the MAX4466
+func DefaultMicrophoneConfig() MicrophoneConfig {
+	return MicrophoneConfig{
+		SamplesPerTick: 16,
+		Attack:         0.5,
+		Release:        0.05,
+		Gain:           2,
+	}
+}
+
+// dcWeight is how slowly the microphone's resting level is tracked
+const dcWeight = 0.999
+
+// Microphone follows the loudness of an analog microphone on an ADC. The audio
+// swings around a resting level; the envelope is how far it swings, rising
+// quickly with sound and falling away slowly. It is an AnalogReader of that
+// envelope, so it can drive a VU meter, an AnalogInputs role or a charge boost.
+type Microphone struct {
+	mu       sync.Mutex
+	reader   AnalogReader
+	config   MicrophoneConfig
+	dc       float32 // Resting level of the signal
+	envelope float32 // Loudness, in raw units
+	primed   bool
+
+	// Ticker for sampling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewMicrophone creates a microphone on an unsmoothed reader of the raw signal
+func NewMicrophone(reader AnalogReader, config MicrophoneConfig) *Microphone {
+	config.SamplesPerTick = max(config.SamplesPerTick, 1)
+	config.Attack = min(max(config.Attack, 0.01), 1)
+	config.Release = min(max(config.Release, 0.001), 1)
+	if config.Gain <= 0 {
+		config.Gain = 1
+	}
+	return &Microphone{
+		reader: reader,
+		config: config,
+	}
+}
+
+// Start begins sampling at the given rate; a millisecond or two keeps up with speech
+func (m *Microphone) Start(sampleRate time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		return
+	}
+	m.running = true
+	m.ticker = time.NewTicker(sampleRate)
+	m.stopTicker = make(chan struct{})
+
+	ticker, stop := m.ticker, m.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				m.Sample()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sampling
+func (m *Microphone) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.running {
+		close(m.stopTicker)
+		if m.ticker != nil {
+			m.ticker.Stop()
+		}
+		m.running = false
+	}
+}
+
+// Sample takes one tick's readings and updates the envelope.
+// It is called by the sampler but may also be driven externally.
+func (m *Microphone) Sample() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Loudest swing away from the resting level in this burst of readings
+	var swing float32
+	for i := 0; i < m.config.SamplesPerTick; i++ {
+		raw := float32(m.reader.ReadRaw())
+		if !m.primed {
+			m.dc = raw
+			m.primed = true
+		}
+		m.dc = dcWeight*m.dc + (1-dcWeight)*raw
+		swing = max(swing, raw-m.dc, m.dc-raw)
+	}
+
+	weight := m.config.Release
+	if swing > m.envelope {
+		weight = m.config.Attack
+	}
+	m.envelope += (swing - m.envelope) * weight
+}
+
+// ReadRaw returns the envelope scaled by the gain, where full scale is a swing
+// across the whole ADC range
+func (m *Microphone) ReadRaw() uint16 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// A swing can be at most half the range either side of the resting level
+	return uint16(min(m.envelope*2*m.config.Gain, analogFullScale))
+}
+
+// ReadPercentage returns the loudness as 0-100
+func (m *Microphone) ReadPercentage() int {
+	return rawToPercentage(m.ReadRaw())
+}
@@ -0,0 +1,8 @@
+//go:build !tinygo
+
+package peripheral
+
+// Configure is a no-op on the host; set NeoPixelDriver to a simulated writer
+func (d *NeoPixel) Configure() error {
+	return nil
+}
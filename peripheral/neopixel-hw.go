@@ -0,0 +1,31 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"errors"
+	"machine"
+
+	"tinygo.org/x/drivers/ws2812"
+)
+
+// ErrNoNeoPixelPin is returned when configuring a NeoPixel created without a pin
+var ErrNoNeoPixelPin = errors.New("NeoPixel has no data pin")
+
+// NewNeoPixel creates the status pixel on the given pin (HardwareConfig.NeoPixelPin), set up by Configure
+func NewNeoPixel(pin machine.Pin) *NeoPixel {
+	return &NeoPixel{pin: pin, hasPin: true}
+}
+
+// Configure sets up the NeoPixel driver on its pin, unless a driver is already attached
+func (d *NeoPixel) Configure() error {
+	if d.NeoPixelDriver != nil {
+		return nil
+	}
+	if !d.hasPin {
+		return ErrNoNeoPixelPin
+	}
+	d.pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	d.NeoPixelDriver = ws2812.NewWS2812(d.pin)
+	return nil
+}
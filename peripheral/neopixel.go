@@ -1,23 +1,37 @@
 package peripheral
 
 import (
+	"context"
 	"image/color"
-	"machine"
 	"time"
 
 	"golang.org/x/exp/rand"
-	"tinygo.org/x/drivers/ws2812"
 )
 
+// NeoPixelWriter writes colors to a chain of WS2812 pixels
+type NeoPixelWriter interface {
+	WriteColors(buf []color.RGBA) error
+}
+
+// Compile-time assertion that NeoPixel implements Peripheral
+var _ Peripheral = (*NeoPixel)(nil)
+
+// NeoPixel is the onboard WS2812 status pixel
 type NeoPixel struct {
-	NeoPixelDriver ws2812.Device
+	NeoPixelDriver NeoPixelWriter
+
+	pin    boardPin // Data pin driven by Configure, see NewNeoPixel
+	hasPin bool
 }
 
-func (d *NeoPixel) Configure() {
-	// Configure the onboard NeoPixel
-	neoPixelPin := machine.PC24
-	neoPixelPin.Configure(machine.PinConfig{Mode: machine.PinOutput})
-	d.NeoPixelDriver = ws2812.NewWS2812(neoPixelPin)
+// Start is a no-op, the pixel only changes when written
+func (d *NeoPixel) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop turns the pixel off
+func (d *NeoPixel) Stop() {
+	d.writeColor(color.RGBA{})
 }
 
 // SetRandomColor sets the NeoPixel to a random color
@@ -28,7 +42,7 @@ func (d *NeoPixel) SetRandomColorAndPause(pauseMilliseconds int) {
 	b := uint8(rand.Intn(10))
 
 	// Write the color to the NeoPixel
-	d.NeoPixelDriver.WriteColors([]color.RGBA{{r, g, b, 50}})
+	d.writeColor(color.RGBA{r, g, b, 50})
 	if pauseMilliseconds > 0 {
 		time.Sleep(time.Millisecond * time.Duration(pauseMilliseconds))
 	}
@@ -36,8 +50,15 @@ func (d *NeoPixel) SetRandomColorAndPause(pauseMilliseconds int) {
 
 func (d *NeoPixel) SetColorAndPause(col color.RGBA, pauseMilliseconds int) {
 	// Write the color to the NeoPixel
-	d.NeoPixelDriver.WriteColors([]color.RGBA{{col.R, col.G, col.B, 20}})
+	d.writeColor(color.RGBA{col.R, col.G, col.B, 20})
 	if pauseMilliseconds > 0 {
 		time.Sleep(time.Millisecond * time.Duration(pauseMilliseconds))
 	}
 }
+
+// writeColor writes a single color, ignoring the call if no driver is attached
+func (d *NeoPixel) writeColor(col color.RGBA) {
+	if d.NeoPixelDriver != nil {
+		d.NeoPixelDriver.WriteColors([]color.RGBA{col})
+	}
+}
@@ -0,0 +1,32 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// pwmLightPeriod is the PWM period in nanoseconds, 1kHz is flicker free
+const pwmLightPeriod = 1e6
+
+// PwmLightConfig selects the pin and PWM peripheral of an auxiliary light
+type PwmLightConfig struct {
+	Pin machine.Pin // machine.NoPin if not fitted
+	PWM PWM         // PWM peripheral able to drive Pin, not shared with the buzzer
+}
+
+// NewPwmLight configures pin for PWM and returns a light on it, starting off
+func NewPwmLight(config PwmLightConfig) (*PwmLight, error) {
+	config.Pin.Configure(machine.PinConfig{Mode: machine.PinTimer})
+	if err := config.PWM.Configure(machine.PWMConfig{Period: pwmLightPeriod}); err != nil {
+		return nil, err
+	}
+
+	ch, err := config.PWM.Channel(config.Pin)
+	if err != nil {
+		return nil, err
+	}
+	light := NewPwmLightOnChannel(config.PWM, ch)
+	light.SetBrightness(0)
+	return light, nil
+}
@@ -0,0 +1,116 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// PwmOutput is the part of a PWM peripheral a PwmLight drives once its channel is set up
+type PwmOutput interface {
+	Top() uint32
+	Set(channel uint8, value uint32)
+}
+
+// pwmFadeStep is how often a fade updates the duty cycle
+const pwmFadeStep = 10 * time.Millisecond
+
+// PwmLight is a dimmable single-color light on a PWM channel, e.g. cabinet
+// lighting or a button halo. Brightness is gamma corrected so fades look even.
+type PwmLight struct {
+	mu         sync.Mutex
+	pwm        PwmOutput
+	channel    uint8
+	brightness uint8
+
+	// Running fade, stopped by the next SetBrightness or FadeTo
+	stopFade chan struct{}
+	fading   bool
+}
+
+// NewPwmLightOnChannel creates a light on a channel of an already configured PWM peripheral
+func NewPwmLightOnChannel(pwm PwmOutput, channel uint8) *PwmLight {
+	return &PwmLight{
+		pwm:     pwm,
+		channel: channel,
+	}
+}
+
+// SetBrightness sets the brightness (0-255) immediately, cancelling any fade
+func (l *PwmLight) SetBrightness(brightness uint8) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cancelFade()
+	l.set(brightness)
+}
+
+// Brightness returns the current brightness, part way through a fade if one is running
+func (l *PwmLight) Brightness() uint8 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.brightness
+}
+
+// FadeTo moves the brightness to target over duration in the background
+func (l *PwmLight) FadeTo(target uint8, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cancelFade()
+	if duration <= pwmFadeStep || target == l.brightness {
+		l.set(target)
+		return
+	}
+
+	l.fading = true
+	l.stopFade = make(chan struct{})
+	go l.fade(l.brightness, target, duration, l.stopFade)
+}
+
+// fade steps the brightness from start to target until done or stopped
+func (l *PwmLight) fade(start, target uint8, duration time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(pwmFadeStep)
+	defer ticker.Stop()
+
+	began := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			progress := min(float64(now.Sub(began))/float64(duration), 1)
+			level := uint8(float64(start) + (float64(target)-float64(start))*progress + 0.5)
+
+			l.mu.Lock()
+			select {
+			case <-stop:
+				// Cancelled while waiting for the lock
+				l.mu.Unlock()
+				return
+			default:
+			}
+			l.set(level)
+			if progress >= 1 {
+				l.fading = false
+			}
+			l.mu.Unlock()
+
+			if progress >= 1 {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// cancelFade stops a running fade (must be called with mutex locked)
+func (l *PwmLight) cancelFade() {
+	if l.fading {
+		close(l.stopFade)
+		l.fading = false
+	}
+}
+
+// set writes the duty cycle for brightness (must be called with mutex locked)
+func (l *PwmLight) set(brightness uint8) {
+	l.brightness = brightness
+	l.pwm.Set(l.channel, uint32(uint64(l.pwm.Top())*uint64(gammaTable[brightness])/255))
+}
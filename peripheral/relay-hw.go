@@ -0,0 +1,21 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+	"time"
+)
+
+// RelayConfig selects the pin driving a relay module or solenoid driver
+type RelayConfig struct {
+	Pin       machine.Pin   // machine.NoPin if not fitted
+	ActiveLow bool          // true if the module energizes on a low input
+	MaxOn     time.Duration // Longest the relay stays energized, DefaultRelayMaxOn if 0
+}
+
+// NewRelay configures the pin as an output and returns a released relay on it
+func NewRelay(config RelayConfig) *Relay {
+	config.Pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	return NewRelayOnOutput(config.Pin, config.ActiveLow, config.MaxOn)
+}
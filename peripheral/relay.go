@@ -0,0 +1,168 @@
+package peripheral
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultRelayMaxOn is how long a relay stays energized unless told otherwise:
+// long enough for players to push a released door open, short enough that a
+// solenoid doesn't overheat if nobody comes
+const DefaultRelayMaxOn = 15 * time.Second
+
+// RelayOutput is the digital output a relay driver is wired to, e.g. a machine.Pin
+type RelayOutput interface {
+	Set(value bool)
+}
+
+// Compile-time assertion that Relay implements Peripheral
+var _ Peripheral = (*Relay)(nil)
+
+// Relay switches a relay or solenoid driver for a physical payoff, e.g. a door
+// release or a maglock. It never stays energized longer than its max on time,
+// so a stuck mission or forgotten console command can't cook a coil.
+type Relay struct {
+	mu        sync.Mutex
+	output    RelayOutput
+	activeLow bool // true if the driver board energizes on a low input, as many relay modules do
+	maxOn     time.Duration
+	energized bool
+	since     time.Time   // When the relay last changed
+	release   *time.Timer // Releases the relay at the end of its on time
+	callbacks []func(energized bool)
+}
+
+// NewRelayOnOutput creates a released relay on an already configured output,
+// with maxOn capping every energize (DefaultRelayMaxOn if 0)
+func NewRelayOnOutput(output RelayOutput, activeLow bool, maxOn time.Duration) *Relay {
+	if maxOn <= 0 {
+		maxOn = DefaultRelayMaxOn
+	}
+	r := &Relay{output: output, activeLow: activeLow, maxOn: maxOn, since: time.Now()}
+	r.write(false)
+	return r
+}
+
+// OnChange registers a callback run when the relay is energized or released,
+// including by its max on time; callbacks run outside the relay's lock
+func (r *Relay) OnChange(callback func(energized bool)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.callbacks = append(r.callbacks, callback)
+}
+
+// Energize energizes the relay for its max on time
+func (r *Relay) Energize() {
+	r.EnergizeFor(0)
+}
+
+// EnergizeFor energizes the relay for d, capped at its max on time (0 for the max).
+// Energizing again restarts the on time.
+func (r *Relay) EnergizeFor(d time.Duration) {
+	if d <= 0 || d > r.maxOn {
+		d = r.maxOn
+	}
+
+	r.mu.Lock()
+	if r.release != nil {
+		r.release.Stop()
+	}
+	changed := r.set(true)
+	var release *time.Timer
+	release = time.AfterFunc(d, func() {
+		r.mu.Lock()
+		if r.release != release {
+			// Superseded by a later energize or release
+			r.mu.Unlock()
+			return
+		}
+		r.release = nil
+		changed := r.set(false)
+		callbacks := r.callbacks
+		r.mu.Unlock()
+		if changed {
+			notifyRelay(callbacks, false)
+		}
+	})
+	r.release = release
+	callbacks := r.callbacks
+	r.mu.Unlock()
+
+	if changed {
+		notifyRelay(callbacks, true)
+	}
+}
+
+// Release de-energizes the relay
+func (r *Relay) Release() {
+	r.mu.Lock()
+	if r.release != nil {
+		r.release.Stop()
+		r.release = nil
+	}
+	changed := r.set(false)
+	callbacks := r.callbacks
+	r.mu.Unlock()
+
+	if changed {
+		notifyRelay(callbacks, false)
+	}
+}
+
+// IsEnergized returns whether the relay is energized
+func (r *Relay) IsEnergized() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.energized
+}
+
+// Since returns when the relay was last energized or released
+func (r *Relay) Since() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.since
+}
+
+// MaxOn returns the longest the relay stays energized
+func (r *Relay) MaxOn() time.Duration {
+	return r.maxOn
+}
+
+// Configure is a no-op, the output is set up by NewRelay
+func (r *Relay) Configure() error {
+	return nil
+}
+
+// Start is a no-op, the relay only changes when told to
+func (r *Relay) Start(ctx context.Context) error {
+	return nil
+}
+
+// Stop releases the relay
+func (r *Relay) Stop() {
+	r.Release()
+}
+
+// set drives the output, returning whether the state changed (must be called with mutex locked)
+func (r *Relay) set(energized bool) bool {
+	if energized == r.energized {
+		return false
+	}
+	r.energized = energized
+	r.since = time.Now()
+	r.write(energized)
+	return true
+}
+
+// write drives the output for energized, allowing for an active-low driver
+func (r *Relay) write(energized bool) {
+	r.output.Set(energized != r.activeLow)
+}
+
+// notifyRelay runs the change callbacks
+func notifyRelay(callbacks []func(bool), energized bool) {
+	for _, callback := range callbacks {
+		callback(energized)
+	}
+}
@@ -0,0 +1,40 @@
+//go:build tinygo && atsamd51
+
+package peripheral
+
+import "device/sam"
+
+// Reset cause bits of the SAMD51 RSTC.RCAUSE register
+const (
+	resetPowerOn  = 0x01
+	resetBODCore  = 0x02
+	resetBODVDD   = 0x04
+	resetNVM      = 0x08
+	resetExternal = 0x10
+	resetWatchdog = 0x20
+	resetSystem   = 0x40
+	resetBackup   = 0x80
+)
+
+// ResetCause returns why the board last reset, e.g. "watchdog" after the panel loop hung
+func ResetCause() string {
+	cause := sam.RSTC.RCAUSE.Get()
+	switch {
+	case cause&resetWatchdog != 0:
+		return "watchdog"
+	case cause&(resetBODCore|resetBODVDD) != 0:
+		return "brownout"
+	case cause&resetExternal != 0:
+		return "reset-button"
+	case cause&resetSystem != 0:
+		return "software"
+	case cause&resetPowerOn != 0:
+		return "power-on"
+	case cause&resetNVM != 0:
+		return "nvm"
+	case cause&resetBackup != 0:
+		return "backup"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !(tinygo && atsamd51)
+
+package peripheral
+
+// ResetCause returns why the board last reset; only SAMD51 boards can tell
+func ResetCause() string {
+	return "unknown"
+}
@@ -0,0 +1,81 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// Compile-time assertion that RotaryEncoder implements RotaryReader
+var _ RotaryReader = (*RotaryEncoder)(nil)
+
+// RotaryEncoderConfig describes how a quadrature rotary encoder is wired
+type RotaryEncoderConfig struct {
+	PinA           machine.Pin
+	PinB           machine.Pin
+	Button         ButtonConfig // Push switch, Pin is machine.NoPin if not fitted
+	StepsPerDetent int          // Quadrature steps per click, 4 for most encoders
+}
+
+// RotaryEncoder decodes a quadrature encoder using pin interrupts, so no
+// turns are missed between polls
+type RotaryEncoder struct {
+	pinA    machine.Pin
+	pinB    machine.Pin
+	button  *Button
+	decoder quadratureDecoder
+}
+
+// NewRotaryEncoder creates a rotary encoder from a wiring config
+func NewRotaryEncoder(config RotaryEncoderConfig) *RotaryEncoder {
+	if config.StepsPerDetent <= 0 {
+		config.StepsPerDetent = 4
+	}
+
+	e := &RotaryEncoder{
+		pinA: config.PinA,
+		pinB: config.PinB,
+	}
+	e.decoder.stepsPerDetent = int32(config.StepsPerDetent)
+	if config.Button.Pin != machine.NoPin {
+		e.button = NewButtonFromConfig(config.Button)
+	}
+	return e
+}
+
+// Configure sets up the channel pins with pull-ups and attaches the edge interrupts
+func (e *RotaryEncoder) Configure() error {
+	e.pinA.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	e.pinB.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	e.decoder.update(e.pinA.Get(), e.pinB.Get())
+
+	if err := e.pinA.SetInterrupt(machine.PinToggle, e.handleEdge); err != nil {
+		return err
+	}
+	if err := e.pinB.SetInterrupt(machine.PinToggle, e.handleEdge); err != nil {
+		return err
+	}
+
+	if e.button != nil {
+		return e.button.Configure()
+	}
+	return nil
+}
+
+// handleEdge runs in interrupt context on every channel edge
+func (e *RotaryEncoder) handleEdge(machine.Pin) {
+	e.decoder.update(e.pinA.Get(), e.pinB.Get())
+}
+
+// Delta returns the detents turned since the last call, positive clockwise
+func (e *RotaryEncoder) Delta() int {
+	return e.decoder.Delta()
+}
+
+// Button returns the encoder's push switch, or nil if it has none
+func (e *RotaryEncoder) Button() ButtonReader {
+	if e.button == nil {
+		return nil
+	}
+	return e.button
+}
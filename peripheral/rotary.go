@@ -0,0 +1,50 @@
+package peripheral
+
+import "sync/atomic"
+
+// RotaryReader reports how far a rotary control has turned
+type RotaryReader interface {
+	// Delta returns the detents turned since the last call, positive clockwise
+	Delta() int
+}
+
+// quadratureSteps maps (previous state << 2 | current state) to a step of -1, 0 or +1.
+// Invalid transitions (both channels changing at once) count as 0.
+var quadratureSteps = [16]int8{0, -1, 1, 0, 1, 0, 0, -1, -1, 0, 0, 1, 0, 1, -1, 0}
+
+// quadratureDecoder turns A/B channel edges into detents. update may be called
+// from an interrupt handler while Delta is read from a goroutine.
+type quadratureDecoder struct {
+	state          uint8
+	steps          atomic.Int32 // Quarter steps not yet reported by Delta
+	stepsPerDetent int32
+}
+
+// update feeds the current channel levels to the decoder
+func (d *quadratureDecoder) update(a, b bool) {
+	current := uint8(0)
+	if a {
+		current |= 2
+	}
+	if b {
+		current |= 1
+	}
+	if step := quadratureSteps[d.state<<2|current]; step != 0 {
+		d.steps.Add(int32(step))
+	}
+	d.state = current
+}
+
+// Delta returns whole detents turned since the last call, keeping any remainder
+func (d *quadratureDecoder) Delta() int {
+	for {
+		steps := d.steps.Load()
+		detents := steps / d.stepsPerDetent
+		if detents == 0 {
+			return 0
+		}
+		if d.steps.CompareAndSwap(steps, steps-detents*d.stepsPerDetent) {
+			return int(detents)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+	"time"
+)
+
+// ServoConfig selects the pin and PWM peripheral of a servo and its travel
+type ServoConfig struct {
+	Pin machine.Pin // machine.NoPin if not fitted
+	PWM PWM         // PWM peripheral able to drive Pin at 50Hz, not shared with the lights or buzzer
+
+	// Pulse widths at the ends of travel, DefaultServoMinPulse and DefaultServoMaxPulse if 0
+	MinPulse time.Duration
+	MaxPulse time.Duration
+}
+
+// NewServo configures pin for 50Hz PWM and returns a servo on it, not yet holding a position
+func NewServo(config ServoConfig) (*Servo, error) {
+	config.Pin.Configure(machine.PinConfig{Mode: machine.PinTimer})
+	if err := config.PWM.Configure(machine.PWMConfig{Period: uint64(servoPeriod)}); err != nil {
+		return nil, err
+	}
+
+	ch, err := config.PWM.Channel(config.Pin)
+	if err != nil {
+		return nil, err
+	}
+	return NewServoOnChannel(config.PWM, ch, config.MinPulse, config.MaxPulse), nil
+}
@@ -0,0 +1,146 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// Default servo pulse widths for the ends of travel; most hobby servos accept
+// 1-2ms, some reach further either side for a wider sweep
+const (
+	DefaultServoMinPulse = 1000 * time.Microsecond
+	DefaultServoMaxPulse = 2000 * time.Microsecond
+)
+
+// servoPeriod is the 50Hz frame hobby servos expect
+const servoPeriod = 20 * time.Millisecond
+
+// servoSweepStep is how often a sweep moves the servo
+const servoSweepStep = 20 * time.Millisecond
+
+// Servo is a hobby servo on a PWM channel, e.g. moving the needle of an analog
+// gauge. Positions run from 0.0 at the minimum pulse to 1.0 at the maximum.
+type Servo struct {
+	mu       sync.Mutex
+	pwm      PwmOutput
+	channel  uint8
+	minPulse time.Duration
+	maxPulse time.Duration
+	position float32
+	holding  bool // A pulse is being sent, false once released
+
+	// Running sweep, stopped by the next SetPosition, SweepTo or Release
+	stopSweep chan struct{}
+	sweeping  bool
+}
+
+// NewServoOnChannel creates a servo on a channel of a PWM peripheral already
+// configured for a 20ms period, moving between the given pulse widths
+func NewServoOnChannel(pwm PwmOutput, channel uint8, minPulse, maxPulse time.Duration) *Servo {
+	if minPulse <= 0 {
+		minPulse = DefaultServoMinPulse
+	}
+	if maxPulse <= 0 {
+		maxPulse = DefaultServoMaxPulse
+	}
+	return &Servo{
+		pwm:      pwm,
+		channel:  channel,
+		minPulse: minPulse,
+		maxPulse: maxPulse,
+	}
+}
+
+// SetPosition moves the servo to position (0.0-1.0) immediately, cancelling any sweep
+func (s *Servo) SetPosition(position float32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelSweep()
+	s.set(position)
+}
+
+// Position returns the position last sent, part way through a sweep if one is running
+func (s *Servo) Position() float32 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position
+}
+
+// SweepTo moves the servo to position (0.0-1.0) over duration in the background,
+// so a gauge needle glides instead of snapping
+func (s *Servo) SweepTo(position float32, duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelSweep()
+	position = min(max(position, 0), 1)
+	if duration <= servoSweepStep || (s.holding && position == s.position) {
+		s.set(position)
+		return
+	}
+
+	s.sweeping = true
+	s.stopSweep = make(chan struct{})
+	go s.sweep(s.position, position, duration, s.stopSweep)
+}
+
+// Release stops sending pulses, so the servo stops holding its position and
+// any hum or jitter goes quiet; the next move resumes them
+func (s *Servo) Release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelSweep()
+	s.pwm.Set(s.channel, 0)
+	s.holding = false
+}
+
+// sweep steps the position from start to target until done or stopped
+func (s *Servo) sweep(start, target float32, duration time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(servoSweepStep)
+	defer ticker.Stop()
+
+	began := time.Now()
+	for {
+		select {
+		case now := <-ticker.C:
+			progress := float32(min(float64(now.Sub(began))/float64(duration), 1))
+
+			s.mu.Lock()
+			select {
+			case <-stop:
+				// Cancelled while waiting for the lock
+				s.mu.Unlock()
+				return
+			default:
+			}
+			s.set(start + (target-start)*progress)
+			if progress >= 1 {
+				s.sweeping = false
+			}
+			s.mu.Unlock()
+
+			if progress >= 1 {
+				return
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// cancelSweep stops a running sweep (must be called with mutex locked)
+func (s *Servo) cancelSweep() {
+	if s.sweeping {
+		close(s.stopSweep)
+		s.sweeping = false
+	}
+}
+
+// set writes the pulse width for position (must be called with mutex locked)
+func (s *Servo) set(position float32) {
+	position = min(max(position, 0), 1)
+	s.position = position
+	s.holding = true
+	pulse := s.minPulse + time.Duration(float32(s.maxPulse-s.minPulse)*position)
+	s.pwm.Set(s.channel, uint32(uint64(s.pwm.Top())*uint64(pulse)/uint64(servoPeriod)))
+}
@@ -0,0 +1,22 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// SevenSegmentConfig describes an HT16K33 seven-segment backpack on an I2C bus
+type SevenSegmentConfig struct {
+	Bus     *machine.I2C // nil if no display is fitted
+	Address uint16       // I2C address, DefaultSevenSegmentAddress if zero
+}
+
+// NewSevenSegmentDisplay configures the I2C bus and the seven-segment display
+func NewSevenSegmentDisplay(config SevenSegmentConfig) (*SevenSegment, error) {
+	if err := config.Bus.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz}); err != nil {
+		return nil, err
+	}
+	s := NewSevenSegment(config.Bus, config.Address)
+	return s, s.Configure()
+}
@@ -0,0 +1,97 @@
+package peripheral
+
+import (
+	"errors"
+
+	"tinygo.org/x/drivers"
+)
+
+// DefaultSevenSegmentAddress is the HT16K33 I2C address with no address jumpers bridged
+const DefaultSevenSegmentAddress = 0x70
+
+// HT16K33 commands
+const (
+	ht16k33OscillatorOn = 0x21
+	ht16k33DisplayOn    = 0x81
+	ht16k33Brightness   = 0xE0
+)
+
+// ErrSevenSegmentText is returned for text that doesn't fit the four digits
+var ErrSevenSegmentText = errors.New("peripheral: text doesn't fit a 4-digit display")
+
+// sevenSegmentDigits are the segment patterns for 0-9
+var sevenSegmentDigits = [10]byte{0x3F, 0x06, 0x5B, 0x4F, 0x66, 0x6D, 0x7D, 0x07, 0x7F, 0x6F}
+
+// segmentDot lights a digit's decimal point
+const segmentDot = 0x80
+
+// SevenSegment drives a 4-digit seven-segment display on an HT16K33 backpack
+type SevenSegment struct {
+	bus     drivers.I2C
+	address uint16
+	buffer  [11]byte // RAM address then two bytes for each of digits 0, 1, colon, 2, 3
+}
+
+// NewSevenSegment creates a display on an already configured I2C bus
+func NewSevenSegment(bus drivers.I2C, address uint16) *SevenSegment {
+	if address == 0 {
+		address = DefaultSevenSegmentAddress
+	}
+	return &SevenSegment{bus: bus, address: address}
+}
+
+// Configure starts the oscillator and turns the display on at full brightness
+func (s *SevenSegment) Configure() error {
+	for _, command := range []byte{ht16k33OscillatorOn, ht16k33DisplayOn, ht16k33Brightness | 15} {
+		if err := s.bus.Tx(s.address, []byte{command}, nil); err != nil {
+			return err
+		}
+	}
+	return s.ShowText("")
+}
+
+// SetBrightness sets the brightness, 0-15
+func (s *SevenSegment) SetBrightness(brightness uint8) error {
+	return s.bus.Tx(s.address, []byte{ht16k33Brightness | min(brightness, 15)}, nil)
+}
+
+// ShowText shows up to four characters right-aligned: digits, spaces and '-',
+// each optionally followed by '.' to light its decimal point
+func (s *SevenSegment) ShowText(text string) error {
+	var digits [4]byte
+	count := 0
+	for i := len(text) - 1; i >= 0; i-- {
+		dot := false
+		if text[i] == '.' {
+			dot = true
+			if i > 0 && text[i-1] != '.' {
+				i--
+			}
+		}
+		if count == len(digits) {
+			return ErrSevenSegmentText
+		}
+
+		var segments byte
+		switch ch := text[i]; {
+		case ch >= '0' && ch <= '9':
+			segments = sevenSegmentDigits[ch-'0']
+		case ch == '-':
+			segments = 0x40
+		case ch == ' ', ch == '.':
+		default:
+			return ErrSevenSegmentText
+		}
+		if dot {
+			segments |= segmentDot
+		}
+		count++
+		digits[len(digits)-count] = segments
+	}
+
+	// Digits sit at positions 0, 1, 3 and 4; position 2 is the colon
+	for i, position := range [4]int{0, 1, 3, 4} {
+		s.buffer[1+position*2] = digits[i]
+	}
+	return s.bus.Tx(s.address, s.buffer[:], nil)
+}
@@ -0,0 +1,121 @@
+//go:build !tinygo
+
+package sim
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Keyboard maps terminal key presses onto simulated buttons and actions.
+// Terminals don't report key releases, so each key press toggles its button.
+type Keyboard struct {
+	mu      sync.Mutex
+	in      io.Reader
+	buttons map[byte]*peripheral.MockButton
+	actions map[byte]func()
+}
+
+// NewKeyboard creates a keyboard reading key presses from in
+func NewKeyboard(in io.Reader) *Keyboard {
+	return &Keyboard{
+		in:      in,
+		buttons: make(map[byte]*peripheral.MockButton),
+		actions: make(map[byte]func()),
+	}
+}
+
+// Button returns the simulated button toggled by key
+func (k *Keyboard) Button(key byte) peripheral.ButtonReader {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	button, ok := k.buttons[key]
+	if !ok {
+		button = peripheral.NewMockButton()
+		k.buttons[key] = button
+	}
+	return button
+}
+
+// OnKey registers an action run whenever key is pressed
+func (k *Keyboard) OnKey(key byte, action func()) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.actions[key] = action
+}
+
+// Run reads key presses until the context is cancelled or input ends
+func (k *Keyboard) Run(ctx context.Context) {
+	reader := bufio.NewReader(k.in)
+	keys := make(chan byte)
+
+	go func() {
+		defer close(keys)
+		for {
+			key, err := reader.ReadByte()
+			if err != nil {
+				return
+			}
+			keys <- key
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case key, ok := <-keys:
+			if !ok {
+				return
+			}
+			k.handleKey(key)
+		}
+	}
+}
+
+// handleKey toggles the mapped button and runs the mapped action for a key
+func (k *Keyboard) handleKey(key byte) {
+	k.mu.Lock()
+	button := k.buttons[key]
+	action := k.actions[key]
+	k.mu.Unlock()
+
+	if button != nil {
+		button.SetPressed(!button.IsPressed())
+	}
+	if action != nil {
+		action()
+	}
+}
+
+// EnableRawMode switches the controlling terminal to unbuffered, no-echo input
+// so single key presses are delivered immediately. The returned function restores
+// the previous terminal settings.
+func EnableRawMode() (func(), error) {
+	saved, err := stty("-g")
+	if err != nil {
+		return func() {}, err
+	}
+	if _, err := stty("cbreak", "-echo"); err != nil {
+		return func() {}, err
+	}
+	return func() {
+		stty(strings.TrimSpace(saved))
+	}, nil
+}
+
+// stty runs stty against the process's standard input
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return string(out), err
+}
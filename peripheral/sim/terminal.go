@@ -0,0 +1,136 @@
+//go:build !tinygo
+
+// Package sim provides host-side stand-ins for the hardware peripherals so the
+// panel, pattern, and battery logic can be run and debugged without flashing a board.
+package sim
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"sync"
+
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// Compile-time assertions that the terminal adapters satisfy the peripheral writers
+var (
+	_ peripheral.PixelWriter    = (*stripWriter)(nil)
+	_ peripheral.NeoPixelWriter = (*neoPixelWriter)(nil)
+)
+
+// Terminal renders the LED strip and status NeoPixel as ANSI color blocks
+type Terminal struct {
+	mu       sync.Mutex
+	out      io.Writer
+	columns  int     // LEDs per terminal row
+	gain     float64 // Brightness multiplier, panel colors are tuned very dim
+	strip    []color.RGBA
+	status   color.RGBA
+	footer   string
+	drawnOne bool
+}
+
+// NewTerminal creates a terminal renderer writing to out
+// columns sets how many LEDs are drawn per row, gain scales the dim LED colors up for visibility
+func NewTerminal(out io.Writer, columns int, gain float64) *Terminal {
+	if columns <= 0 {
+		columns = 72
+	}
+	if gain <= 0 {
+		gain = 1
+	}
+	return &Terminal{
+		out:     out,
+		columns: columns,
+		gain:    gain,
+	}
+}
+
+// StripWriter returns a writer to attach to a ColorLedStrip
+func (t *Terminal) StripWriter() peripheral.PixelWriter {
+	return (*stripWriter)(t)
+}
+
+// NeoPixelWriter returns a writer to assign to NeoPixel.NeoPixelDriver
+func (t *Terminal) NeoPixelWriter() peripheral.NeoPixelWriter {
+	return (*neoPixelWriter)(t)
+}
+
+// SetFooter sets a line of text drawn below the strip, e.g. key bindings
+func (t *Terminal) SetFooter(footer string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.footer = footer
+}
+
+// stripWriter adapts Terminal to peripheral.PixelWriter
+type stripWriter Terminal
+
+func (w *stripWriter) WriteColors(cs []color.RGBA) (int, error) {
+	t := (*Terminal)(w)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.strip = append(t.strip[:0], cs...)
+	return len(cs), t.render()
+}
+
+// neoPixelWriter adapts Terminal to peripheral.NeoPixelWriter
+type neoPixelWriter Terminal
+
+func (w *neoPixelWriter) WriteColors(buf []color.RGBA) error {
+	t := (*Terminal)(w)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(buf) > 0 {
+		t.status = buf[0]
+	}
+	return t.render()
+}
+
+// render redraws the whole display (must be called with mutex locked)
+func (t *Terminal) render() error {
+	w := bufio.NewWriter(t.out)
+
+	if !t.drawnOne {
+		// Clear the screen once, afterwards just move the cursor home
+		w.WriteString("\x1b[2J")
+		t.drawnOne = true
+	}
+	w.WriteString("\x1b[H")
+
+	w.WriteString("status ")
+	t.writeBlock(w, t.status)
+	w.WriteString("\x1b[0m\x1b[K\r\n")
+
+	for i, c := range t.strip {
+		t.writeBlock(w, c)
+		if (i+1)%t.columns == 0 || i == len(t.strip)-1 {
+			w.WriteString("\x1b[0m\x1b[K\r\n")
+		}
+	}
+
+	if t.footer != "" {
+		w.WriteString(t.footer)
+		w.WriteString("\x1b[K\r\n")
+	}
+
+	return w.Flush()
+}
+
+// writeBlock writes a single colored block for one LED
+func (t *Terminal) writeBlock(w *bufio.Writer, c color.RGBA) {
+	fmt.Fprintf(w, "\x1b[38;2;%d;%d;%dm█", t.scale(c.R), t.scale(c.G), t.scale(c.B))
+}
+
+// scale applies the display gain to a single channel
+func (t *Terminal) scale(v uint8) uint8 {
+	scaled := float64(v) * t.gain
+	if scaled > 255 {
+		return 255
+	}
+	return uint8(scaled)
+}
@@ -0,0 +1,213 @@
+package peripheral
+
+import (
+	"sync"
+	"time"
+)
+
+// SliderWatcherConfig holds options for watching the slider
+type SliderWatcherConfig struct {
+	Smoothing float32       // Weight (0-1) given to the previous value, as in AnalogConfig
+	Threshold int           // Change in percent needed before a new value is published
+	PollRate  time.Duration // How often the package-level watcher samples the slider
+}
+
+// DefaultSliderWatcherConfig returns settings that hold a jittery slider steady
+func DefaultSliderWatcherConfig() SliderWatcherConfig {
+	return SliderWatcherConfig{
+		Smoothing: 0.7,
+		Threshold: 2,
+		PollRate:  50 * time.Millisecond,
+	}
+}
+
+// SliderWatcher samples an analog input on a timer, smooths it, and only
+// publishes a new value once it moves by more than the threshold, so patterns
+// can react to the slider being moved rather than to every jittery reading
+type SliderWatcher struct {
+	mu        sync.Mutex
+	reader    AnalogReader
+	threshold int
+	filter    smoother
+	value     int
+	primed    bool
+	channels  []chan<- int
+	callbacks []func(int)
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewSliderWatcher creates a watcher on reader
+func NewSliderWatcher(reader AnalogReader, config SliderWatcherConfig) *SliderWatcher {
+	return &SliderWatcher{
+		reader:    reader,
+		threshold: max(config.Threshold, 0),
+		filter:    smoother{weight: min(max(config.Smoothing, 0), 0.99)},
+	}
+}
+
+// Value returns the last published value (0-100), reading the slider first if
+// it hasn't been polled yet
+func (w *SliderWatcher) Value() int {
+	w.mu.Lock()
+	primed := w.primed
+	w.mu.Unlock()
+
+	if !primed {
+		w.Poll()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.value
+}
+
+// Subscribe registers a channel that receives every new value.
+// Sends never block the poller; values are dropped if the channel is full,
+// so use a buffered channel.
+func (w *SliderWatcher) Subscribe(ch chan<- int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.channels = append(w.channels, ch)
+}
+
+// Unsubscribe removes a channel previously registered with Subscribe
+func (w *SliderWatcher) Unsubscribe(ch chan<- int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for i, subscriber := range w.channels {
+		if subscriber == ch {
+			w.channels = append(w.channels[:i], w.channels[i+1:]...)
+			return
+		}
+	}
+}
+
+// OnChange registers a callback run for every new value, on the polling goroutine
+func (w *SliderWatcher) OnChange(callback func(int)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.callbacks = append(w.callbacks, callback)
+}
+
+// Start begins polling the slider at the given rate
+func (w *SliderWatcher) Start(pollRate time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		return
+	}
+	w.running = true
+	w.ticker = time.NewTicker(pollRate)
+	w.stopTicker = make(chan struct{})
+
+	ticker, stop := w.ticker, w.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				w.Poll()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops polling
+func (w *SliderWatcher) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.running {
+		close(w.stopTicker)
+		if w.ticker != nil {
+			w.ticker.Stop()
+		}
+		w.running = false
+	}
+}
+
+// Poll reads the slider once and publishes the value if it moved past the threshold.
+// It is called by the poller but may also be driven externally.
+func (w *SliderWatcher) Poll() {
+	w.mu.Lock()
+	percentage := rawToPercentage(w.filter.add(w.reader.ReadRaw()))
+	delta := percentage - w.value
+	if w.primed && delta <= w.threshold && delta >= -w.threshold {
+		w.mu.Unlock()
+		return
+	}
+	w.value = percentage
+	w.primed = true
+	channels := append([]chan<- int(nil), w.channels...)
+	callbacks := make([]func(int), len(w.callbacks))
+	copy(callbacks, w.callbacks)
+	w.mu.Unlock()
+
+	for _, ch := range channels {
+		select {
+		case ch <- percentage:
+		default:
+			// Subscriber isn't keeping up, drop the value
+		}
+	}
+	for _, callback := range callbacks {
+		callback(percentage)
+	}
+}
+
+// packageAnalogInput reads whichever reader SetAnalogInput last selected, so
+// the package-level watcher follows it
+type packageAnalogInput struct{}
+
+func (packageAnalogInput) ReadRaw() uint16 {
+	return currentAnalogInput().ReadRaw()
+}
+
+func (packageAnalogInput) ReadPercentage() int {
+	return currentAnalogInput().ReadPercentage()
+}
+
+// sliderWatcher is the watcher behind the package-level slider helpers
+var (
+	sliderMu      sync.Mutex
+	sliderWatcher *SliderWatcher
+)
+
+// SetSliderWatcher replaces the watcher behind the package-level slider helpers,
+// e.g. to change its threshold; the caller starts and stops it
+func SetSliderWatcher(w *SliderWatcher) {
+	sliderMu.Lock()
+	defer sliderMu.Unlock()
+	sliderWatcher = w
+}
+
+// currentSliderWatcher returns the watcher behind the package-level slider
+// helpers, starting one on the analog input with the default settings on first use
+func currentSliderWatcher() *SliderWatcher {
+	sliderMu.Lock()
+	defer sliderMu.Unlock()
+	if sliderWatcher == nil {
+		config := DefaultSliderWatcherConfig()
+		sliderWatcher = NewSliderWatcher(packageAnalogInput{}, config)
+		sliderWatcher.Start(config.PollRate)
+	}
+	return sliderWatcher
+}
+
+// WatchSlider subscribes to slider moves, for patterns that should only
+// recompute their timing when the slider is moved. Call stop when done watching.
+func WatchSlider() (moved <-chan int, stop func()) {
+	w := currentSliderWatcher()
+	ch := make(chan int, 1)
+	w.Subscribe(ch)
+	return ch, func() {
+		w.Unsubscribe(ch)
+	}
+}
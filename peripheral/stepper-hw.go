@@ -0,0 +1,130 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+	"time"
+)
+
+// StepperDriverKind is the kind of driver board a stepper is wired through
+type StepperDriverKind int
+
+const (
+	NoStepper StepperDriverKind = iota // Not fitted
+	A4988                              // Step and direction inputs, e.g. an A4988 or DRV8825 with a NEMA 17
+	ULN2003                            // Four coil inputs, e.g. the board sold with the 28BYJ-48
+)
+
+// StepperConfig describes a stepper motor's driver board, pins and motion
+type StepperConfig struct {
+	Driver StepperDriverKind
+
+	// A4988 step and direction pins, and an optional active-low enable pin (machine.NoPin if tied low)
+	Step   machine.Pin
+	Dir    machine.Pin
+	Enable machine.Pin
+
+	// ULN2003 IN1-IN4 pins
+	Coils [4]machine.Pin
+
+	Motion StepperMotion // Zero fields use DefaultStepperMotion
+}
+
+// NewStepperFromConfig configures the driver pins and returns a stepper at rest at empty
+func NewStepperFromConfig(config StepperConfig) *Stepper {
+	var driver StepDriver
+	switch config.Driver {
+	case ULN2003:
+		driver = newCoilDriver(config.Coils)
+	default:
+		driver = newStepDirDriver(config.Step, config.Dir, config.Enable)
+	}
+	return NewStepper(driver, config.Motion)
+}
+
+// a4988PulseWidth is the step pulse width; the A4988 needs at least 1us high
+const a4988PulseWidth = 2 * time.Microsecond
+
+// stepDirDriver drives a step and direction driver board like the A4988
+type stepDirDriver struct {
+	step, dir, enable machine.Pin
+}
+
+// newStepDirDriver configures the pins, starting with the driver disabled
+func newStepDirDriver(step, dir, enable machine.Pin) *stepDirDriver {
+	for _, pin := range []machine.Pin{step, dir, enable} {
+		if pin != machine.NoPin {
+			pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+		}
+	}
+	d := &stepDirDriver{step: step, dir: dir, enable: enable}
+	d.Release()
+	return d
+}
+
+// Step enables the driver and pulses the step pin once in the given direction
+func (d *stepDirDriver) Step(forward bool) {
+	if d.enable != machine.NoPin {
+		d.enable.Low()
+	}
+	d.dir.Set(forward)
+	d.step.High()
+	time.Sleep(a4988PulseWidth)
+	d.step.Low()
+}
+
+// Release disables the driver outputs, if the enable pin is wired
+func (d *stepDirDriver) Release() {
+	if d.enable != machine.NoPin {
+		d.enable.High()
+	}
+}
+
+// halfStepSequence energizes the four coils in turn, two at a time between
+// single coils, for twice the resolution of full stepping
+var halfStepSequence = [8][4]bool{
+	{true, false, false, false},
+	{true, true, false, false},
+	{false, true, false, false},
+	{false, true, true, false},
+	{false, false, true, false},
+	{false, false, true, true},
+	{false, false, false, true},
+	{true, false, false, true},
+}
+
+// coilDriver half-steps a unipolar motor through a ULN2003 darlington array
+type coilDriver struct {
+	coils [4]machine.Pin
+	phase int // Index into halfStepSequence
+}
+
+// newCoilDriver configures the coil pins, starting with every coil off
+func newCoilDriver(coils [4]machine.Pin) *coilDriver {
+	for _, pin := range coils {
+		pin.Configure(machine.PinConfig{Mode: machine.PinOutput})
+	}
+	d := &coilDriver{coils: coils}
+	d.Release()
+	return d
+}
+
+// Step moves to the next half step in the given direction
+func (d *coilDriver) Step(forward bool) {
+	if forward {
+		d.phase = (d.phase + 1) % len(halfStepSequence)
+	} else {
+		d.phase = (d.phase + len(halfStepSequence) - 1) % len(halfStepSequence)
+	}
+	for i, on := range halfStepSequence[d.phase] {
+		d.coils[i].Set(on)
+	}
+}
+
+// Release turns every coil off; the next step re-energizes from the same phase
+func (d *coilDriver) Release() {
+	for _, pin := range d.coils {
+		pin.Low()
+	}
+}
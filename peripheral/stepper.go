@@ -0,0 +1,238 @@
+package peripheral
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// StepDriver moves a stepper motor one step at a time, e.g. an A4988 or ULN2003 board
+type StepDriver interface {
+	Step(forward bool)
+	Release() // De-energize the coils so an idle motor doesn't heat up
+}
+
+// StepperMotion describes a stepper's travel and how hard it may be driven
+type StepperMotion struct {
+	Travel       int     // Steps from empty to full scale
+	MaxSpeed     float64 // Steps per second
+	Acceleration float64 // Steps per second per second, for both speeding up and slowing down
+}
+
+// DefaultStepperMotion suits a 28BYJ-48 half-stepped on a ULN2003 turning a 270°
+// dial: 4096 steps a revolution, gentle enough that the needle doesn't wobble
+func DefaultStepperMotion() StepperMotion {
+	return StepperMotion{
+		Travel:       3072,
+		MaxSpeed:     600,
+		Acceleration: 1200,
+	}
+}
+
+// Compile-time assertion that Stepper implements Peripheral
+var _ Peripheral = (*Stepper)(nil)
+
+// Stepper positions a stepper motor, accelerating towards its target and
+// slowing in time to stop on it, e.g. to turn the needle of a physical dial.
+// It has no position sensor: the needle must rest at empty when it starts,
+// or be zeroed with SetZero.
+type Stepper struct {
+	mu       sync.Mutex
+	driver   StepDriver
+	motion   StepperMotion
+	position int     // Steps from empty
+	target   int     // Steps from empty
+	speed    float64 // Current speed in steps per second, 0 at rest
+	dir      int     // Direction of travel while moving, +1 or -1
+
+	wake    chan struct{} // Signals the mover that the target changed
+	stop    chan struct{}
+	running bool
+}
+
+// NewStepper creates a stepper at rest at position 0 on driver
+func NewStepper(driver StepDriver, motion StepperMotion) *Stepper {
+	defaults := DefaultStepperMotion()
+	if motion.Travel <= 0 {
+		motion.Travel = defaults.Travel
+	}
+	if motion.MaxSpeed <= 0 {
+		motion.MaxSpeed = defaults.MaxSpeed
+	}
+	if motion.Acceleration <= 0 {
+		motion.Acceleration = defaults.Acceleration
+	}
+	return &Stepper{
+		driver: driver,
+		motion: motion,
+		dir:    1,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// MoveTo sets the target in steps from empty, clamped to the travel
+func (s *Stepper) MoveTo(target int) {
+	s.mu.Lock()
+	s.target = min(max(target, 0), s.motion.Travel)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// SweepTo moves to position (0.0-1.0) of the travel, so a stepper dial can be a
+// panel gauge's needle. The speed is set by the acceleration limits, not duration.
+func (s *Stepper) SweepTo(position float32, duration time.Duration) {
+	s.MoveTo(int(math.Round(float64(position) * float64(s.motion.Travel))))
+}
+
+// Position returns the current position in steps from empty
+func (s *Stepper) Position() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.position
+}
+
+// Target returns the position being moved to
+func (s *Stepper) Target() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.target
+}
+
+// SetZero takes the current position as empty, e.g. after parking the needle by hand
+func (s *Stepper) SetZero() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.target -= s.position
+	s.position = 0
+}
+
+// Configure is a no-op, the driver pins are set up by NewStepperFromConfig
+func (s *Stepper) Configure() error {
+	return nil
+}
+
+// Start moves the motor towards its target in the background until ctx is done or Stop is called
+func (s *Stepper) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.stop = make(chan struct{})
+
+	stop := s.stop
+	go s.run(ctx, stop)
+	return nil
+}
+
+// Stop stops the motor where it is and releases its coils
+func (s *Stepper) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.halt()
+}
+
+// halt stops the mover (must be called with mutex locked)
+func (s *Stepper) halt() {
+	if s.running {
+		close(s.stop)
+		s.running = false
+	}
+}
+
+// run steps towards the target, sleeping between steps for the current speed
+// and waiting with the coils released while at rest
+func (s *Stepper) run(ctx context.Context, stop chan struct{}) {
+	defer s.driver.Release()
+
+	for {
+		s.mu.Lock()
+		forward, interval, moving := s.next()
+		s.mu.Unlock()
+
+		if !moving {
+			s.driver.Release()
+			select {
+			case <-s.wake:
+				continue
+			case <-stop:
+				return
+			case <-ctx.Done():
+				s.mu.Lock()
+				if s.stop == stop {
+					s.halt()
+				}
+				s.mu.Unlock()
+				return
+			}
+		}
+
+		if interval > 0 {
+			s.driver.Step(forward)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-ctx.Done():
+			s.mu.Lock()
+			if s.stop == stop {
+				s.halt()
+			}
+			s.mu.Unlock()
+			return
+		case <-time.After(interval):
+		}
+	}
+}
+
+// next works out the next step: its direction, the time until the step after,
+// and whether the motor is moving at all. A step changes the speed squared by
+// twice the acceleration, so the motor speeds up and slows down evenly and
+// starts slowing early enough to stop on the target. (must be called with mutex locked)
+func (s *Stepper) next() (forward bool, interval time.Duration, moving bool) {
+	distance := s.target - s.position
+	stepSpeed := 2 * s.motion.Acceleration // Change in speed squared per step
+
+	if distance == 0 {
+		// Arriving at the starting speed or slower, so the motor can stop dead
+		s.speed = 0
+		return false, 0, false
+	}
+	want := 1
+	if distance < 0 {
+		want = -1
+	}
+
+	if s.speed > 0 && want != s.dir {
+		// Overshooting or reversing: slow down in the current direction first
+		squared := s.speed*s.speed - stepSpeed
+		if squared <= 0 {
+			s.speed = 0
+			return false, 0, true
+		}
+		s.speed = math.Sqrt(squared)
+	} else {
+		s.dir = want
+		stopping := math.Sqrt(stepSpeed * float64(abs(distance)))
+		s.speed = min(math.Sqrt(s.speed*s.speed+stepSpeed), s.motion.MaxSpeed, max(stopping, math.Sqrt(stepSpeed)))
+	}
+
+	s.position += s.dir
+	return s.dir > 0, time.Duration(float64(time.Second) / s.speed), true
+}
+
+// abs returns the absolute value of n
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
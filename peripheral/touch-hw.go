@@ -0,0 +1,36 @@
+//go:build tinygo
+
+package peripheral
+
+import (
+	"machine"
+)
+
+// TouchConfig describes an MPR121 capacitive touch controller on an I2C bus
+type TouchConfig struct {
+	Bus     *machine.I2C // nil if no touch controller is fitted
+	Address uint16       // I2C address, DefaultTouchAddress if zero
+
+	// Touch and release thresholds, DefaultTouchThreshold and DefaultReleaseThreshold if zero
+	TouchThreshold   uint8
+	ReleaseThreshold uint8
+}
+
+// NewTouchSensorFromConfig configures the I2C bus and returns the touch sensor on it, set up by Configure
+func NewTouchSensorFromConfig(config TouchConfig) (*TouchSensor, error) {
+	if err := config.Bus.Configure(machine.I2CConfig{Frequency: 400 * machine.KHz}); err != nil {
+		return nil, err
+	}
+	s := NewTouchSensor(config.Bus, config.Address)
+	if config.TouchThreshold > 0 || config.ReleaseThreshold > 0 {
+		touch, release := config.TouchThreshold, config.ReleaseThreshold
+		if touch == 0 {
+			touch = DefaultTouchThreshold
+		}
+		if release == 0 {
+			release = DefaultReleaseThreshold
+		}
+		s.SetThresholds(touch, release)
+	}
+	return s, nil
+}
@@ -0,0 +1,250 @@
+package peripheral
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"tinygo.org/x/drivers"
+)
+
+// DefaultTouchAddress is the MPR121 I2C address with ADDR tied to ground
+const DefaultTouchAddress = 0x5A
+
+// TouchPads is the number of electrodes on an MPR121
+const TouchPads = 12
+
+// Default MPR121 thresholds: a pad is touched when its reading drops this far
+// below the baseline and released when it recovers to within the release threshold
+const (
+	DefaultTouchThreshold   = 12
+	DefaultReleaseThreshold = 6
+)
+
+// DefaultTouchPollRate reads the pads often enough for the debouncers sampling them
+const DefaultTouchPollRate = 10 * time.Millisecond
+
+// MPR121 registers
+const (
+	mpr121TouchStatus    = 0x00
+	mpr121BaselineFilter = 0x2B // MHDR to FDLT, rising, falling and touched filters
+	mpr121TouchThreshold = 0x41 // Touch then release threshold for each electrode
+	mpr121Debounce       = 0x5B
+	mpr121Config1        = 0x5C
+	mpr121Config2        = 0x5D
+	mpr121ElectrodeCfg   = 0x5E
+	mpr121SoftReset      = 0x80
+)
+
+// mpr121BaselineSettings are the baseline filter values from the MPR121 quick
+// start guide, written from mpr121BaselineFilter on
+var mpr121BaselineSettings = []byte{0x01, 0x01, 0x0E, 0x00, 0x01, 0x05, 0x01, 0x00, 0x00, 0x00, 0x00}
+
+// ErrNoTouchSensor is returned when the MPR121 doesn't come out of reset as expected
+var ErrNoTouchSensor = errors.New("peripheral: MPR121 touch sensor not found")
+
+// Compile-time assertion that TouchSensor implements Peripheral and FaultReporter
+var _ Peripheral = (*TouchSensor)(nil)
+var _ FaultReporter = (*TouchSensor)(nil)
+
+// TouchSensor reads up to 12 capacitive touch pads on an MPR121, polling the
+// touch status so each pad's IsPressed doesn't cost an I2C transaction
+type TouchSensor struct {
+	mu       sync.Mutex
+	bus      drivers.I2C
+	address  uint16
+	touch    uint8
+	release  uint8
+	pollRate time.Duration
+	touched  uint16 // Bit per pad, as of the last poll
+	fault    faultState
+	buf      [2]byte
+
+	// Ticker for polling
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewTouchSensor creates a touch sensor on an already configured I2C bus
+func NewTouchSensor(bus drivers.I2C, address uint16) *TouchSensor {
+	if address == 0 {
+		address = DefaultTouchAddress
+	}
+	return &TouchSensor{
+		bus:      bus,
+		address:  address,
+		touch:    DefaultTouchThreshold,
+		release:  DefaultReleaseThreshold,
+		pollRate: DefaultTouchPollRate,
+	}
+}
+
+// SetThresholds sets how far a pad's reading must drop to count as touched and
+// recover to count as released, e.g. lower for pads behind a thick acrylic panel.
+// It takes effect on the next Configure.
+func (s *TouchSensor) SetThresholds(touch, release uint8) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.touch = touch
+	s.release = release
+}
+
+// SetPollRate sets how often Start reads the touch status, from the next Start
+func (s *TouchSensor) SetPollRate(pollRate time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pollRate = pollRate
+}
+
+// ReportFaults sends a Fault on faults when reading the touch status starts failing, and again when it recovers
+func (s *TouchSensor) ReportFaults(faults chan<- Fault, source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fault = faultState{faults: faults, source: source}
+}
+
+// Configure resets the MPR121, sets the thresholds and starts it measuring all
+// 12 electrodes with baseline tracking
+func (s *TouchSensor) Configure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writeRegister(mpr121SoftReset, 0x63); err != nil {
+		return err
+	}
+	time.Sleep(time.Millisecond)
+	if err := s.writeRegister(mpr121ElectrodeCfg, 0x00); err != nil {
+		return err
+	}
+
+	// CONFIG2 reads 0x24 after a reset, anything else isn't an MPR121
+	if err := s.bus.Tx(s.address, []byte{mpr121Config2}, s.buf[:1]); err != nil {
+		return err
+	}
+	if s.buf[0] != 0x24 {
+		return ErrNoTouchSensor
+	}
+
+	for pad := 0; pad < TouchPads; pad++ {
+		register := byte(mpr121TouchThreshold + 2*pad)
+		if err := s.bus.Tx(s.address, []byte{register, s.touch, s.release}, nil); err != nil {
+			return err
+		}
+	}
+	if err := s.bus.Tx(s.address, append([]byte{mpr121BaselineFilter}, mpr121BaselineSettings...), nil); err != nil {
+		return err
+	}
+
+	// No on-chip debounce (inputs are debounced like any button), 16uA charge
+	// current, 0.5us charge time, then run with baseline tracking on all electrodes
+	for _, setting := range [][2]byte{
+		{mpr121Debounce, 0x00},
+		{mpr121Config1, 0x10},
+		{mpr121Config2, 0x20},
+		{mpr121ElectrodeCfg, 0x80 | TouchPads},
+	} {
+		if err := s.writeRegister(setting[0], setting[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeRegister writes one register (must be called with mutex locked)
+func (s *TouchSensor) writeRegister(register, value byte) error {
+	return s.bus.Tx(s.address, []byte{register, value}, nil)
+}
+
+// Start begins polling the touch status at the poll rate, until ctx is done or Stop is called
+func (s *TouchSensor) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return nil
+	}
+	s.running = true
+	s.ticker = time.NewTicker(s.pollRate)
+	s.stopTicker = make(chan struct{})
+
+	ticker, stop := s.ticker, s.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Poll()
+			case <-stop:
+				return
+			case <-ctx.Done():
+				s.mu.Lock()
+				if s.stopTicker == stop {
+					s.stop()
+				}
+				s.mu.Unlock()
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops polling; pads keep their last state
+func (s *TouchSensor) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stop()
+}
+
+// stop stops the poller (must be called with mutex locked)
+func (s *TouchSensor) stop() {
+	if s.running {
+		close(s.stopTicker)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+		s.running = false
+	}
+}
+
+// Poll reads the touch status of every pad. A failed read reports every pad released.
+// It is called by the poller but may also be driven externally.
+func (s *TouchSensor) Poll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	err := s.bus.Tx(s.address, []byte{mpr121TouchStatus}, s.buf[:2])
+	s.fault.update(err)
+	if err != nil {
+		s.touched = 0
+		return
+	}
+	s.touched = (uint16(s.buf[0]) | uint16(s.buf[1])<<8) & (1<<TouchPads - 1)
+}
+
+// Touched returns a bit per pad, set while the pad is touched
+func (s *TouchSensor) Touched() uint16 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.touched
+}
+
+// Pad returns a button reading pad 0-11
+func (s *TouchSensor) Pad(pad int) *TouchPad {
+	return &TouchPad{sensor: s, pad: pad}
+}
+
+// Compile-time assertion that TouchPad implements ButtonReader
+var _ ButtonReader = (*TouchPad)(nil)
+
+// TouchPad is a single capacitive pad on a TouchSensor, pressed while touched
+type TouchPad struct {
+	sensor *TouchSensor
+	pad    int
+}
+
+// IsPressed returns true while the pad is touched, as of the sensor's last poll
+func (p *TouchPad) IsPressed() bool {
+	return p.sensor.Touched()&(1<<p.pad) != 0
+}
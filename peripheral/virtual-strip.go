@@ -0,0 +1,93 @@
+package peripheral
+
+import (
+	"image/color"
+)
+
+// VirtualStripConfig selects how a virtual strip's pixels land on the physical strip
+type VirtualStripConfig struct {
+	Mirror  bool // Show the buffer across the first half and reflected across the second
+	Reverse bool // Start the buffer at the far end of the physical strip
+}
+
+// VirtualStrip is a LedStrip of a fixed logical length scaled onto a physical
+// strip of any length, so the same rendering drives a short test rig and the
+// full installation. Shorter physical strips average neighbouring pixels,
+// longer ones repeat them.
+type VirtualStrip struct {
+	*ColorLedStrip
+	physical LedStrip
+	config   VirtualStripConfig
+}
+
+// NewVirtualStrip creates a numLEDs pixel strip shown on physical
+func NewVirtualStrip(numLEDs int, physical LedStrip, config VirtualStripConfig) *VirtualStrip {
+	return &VirtualStrip{
+		ColorLedStrip: NewColorLedStrip(numLEDs),
+		physical:      physical,
+		config:        config,
+	}
+}
+
+// Physical returns the strip the virtual strip is shown on
+func (v *VirtualStrip) Physical() LedStrip {
+	return v.physical
+}
+
+// Show maps the buffer onto the physical strip and shows it
+func (v *VirtualStrip) Show() {
+	v.render()
+	v.physical.Show()
+}
+
+// ShowIfDirty maps the buffer onto the physical strip and shows it if it changed
+func (v *VirtualStrip) ShowIfDirty() bool {
+	v.render()
+	return v.physical.ShowIfDirty()
+}
+
+// render writes the scaled buffer, after brightness and white balance, into the physical strip
+func (v *VirtualStrip) render() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	logical := v.outputColors()
+	physicalLEDs := v.physical.NumLEDs()
+	if len(logical) == 0 || physicalLEDs == 0 {
+		return
+	}
+
+	span := physicalLEDs
+	if v.config.Mirror {
+		span = (physicalLEDs + 1) / 2
+	}
+
+	for i := 0; i < span; i++ {
+		c := sampleSpan(logical, i, span)
+		index := i
+		if v.config.Reverse {
+			index = physicalLEDs - 1 - i
+		}
+		v.physical.SetPixel(index, c)
+		if v.config.Mirror {
+			v.physical.SetPixel(physicalLEDs-1-index, c)
+		}
+	}
+}
+
+// sampleSpan returns the color of pixel i of span pixels covering colors:
+// the average of the colors it covers, or the nearest one when stretching
+func sampleSpan(colors []color.RGBA, i, span int) color.RGBA {
+	start := i * len(colors) / span
+	end := max((i+1)*len(colors)/span, start+1)
+
+	var r, g, b, a int
+	for _, c := range colors[start:end] {
+		r += int(c.R)
+		g += int(c.G)
+		b += int(c.B)
+		a += int(c.A)
+	}
+	n := end - start
+	return color.RGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(b / n), A: uint8(a / n)}
+}
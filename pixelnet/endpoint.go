@@ -0,0 +1,251 @@
+// Package pixelnet turns the strip into a network pixel endpoint, so a venue's
+// lighting desk or pixel-mapping software can drive it over Art-Net or sACN.
+// While data is arriving it overrides the panel and any local pattern; once the
+// desk goes quiet the prop takes the strip back.
+package pixelnet
+
+import (
+	"context"
+	"image/color"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/panel"
+	"github.com/christophergm/tinyspacewalk/patterns"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+)
+
+// PixelsPerUniverse is the number of RGB pixels in each universe; the last two channels are unused
+const PixelsPerUniverse = 170
+
+// Config configures an Endpoint
+type Config struct {
+	Protocol  Protocol
+	Addr      string        // Address to listen on, the protocol's standard port if empty
+	Universe  int           // Universe holding the first pixel; following pixels continue into the next universes
+	Timeout   time.Duration // How long without data before the prop takes the strip back
+	FrameRate time.Duration // Interval between frames shown on the strip
+}
+
+// DefaultConfig returns an endpoint starting at the protocol's first universe
+func DefaultConfig(protocol Protocol) Config {
+	universe := 0
+	if protocol == SACN {
+		universe = 1 // sACN universes are numbered from 1
+	}
+	return Config{
+		Protocol:  protocol,
+		Universe:  universe,
+		Timeout:   3 * time.Second,
+		FrameRate: patterns.DefaultFrameRate,
+	}
+}
+
+// Endpoint receives pixel data from the network and shows it on the strip,
+// pausing the panel and interrupting local patterns while it is live
+type Endpoint struct {
+	mu       sync.Mutex
+	config   Config
+	panel    *panel.Panel
+	manager  *patterns.PatternManager
+	pixels   []color.RGBA
+	live     bool
+	lastData time.Time
+	packets  uint32
+	resume   patterns.Pattern // Local pattern interrupted by the desk, nil if the panel was showing
+
+	// OnLive is called when the desk takes over the strip (true) and when it hands it back (false)
+	OnLive func(live bool)
+}
+
+// NewEndpoint creates an endpoint for a strip of numLEDs driven through manager
+func NewEndpoint(p *panel.Panel, manager *patterns.PatternManager, numLEDs int, config Config) *Endpoint {
+	if config.Addr == "" {
+		config.Addr = ":" + strconv.Itoa(config.Protocol.Port())
+	}
+	if config.FrameRate <= 0 {
+		config.FrameRate = patterns.DefaultFrameRate
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultConfig(config.Protocol).Timeout
+	}
+	return &Endpoint{
+		config:  config,
+		panel:   p,
+		manager: manager,
+		pixels:  make([]color.RGBA, numLEDs),
+	}
+}
+
+// Run listens for packets until the context is cancelled or the socket fails
+func (e *Endpoint) Run(ctx context.Context) error {
+	conn, err := net.ListenPacket("udp", e.config.Addr)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	// Hand the strip back once the desk goes quiet
+	go func() {
+		ticker := time.NewTicker(e.config.Timeout / 4)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				e.release()
+				return
+			case now := <-ticker.C:
+				e.mu.Lock()
+				expired := e.live && now.Sub(e.lastData) >= e.config.Timeout
+				e.mu.Unlock()
+				if expired {
+					e.release()
+				}
+			}
+		}
+	}()
+
+	buf := make([]byte, 1024)
+	for {
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		e.Handle(buf[:n])
+	}
+}
+
+// Handle decodes a packet and, if it carries one of the endpoint's universes,
+// updates the pixels, taking over the strip if the endpoint isn't live yet
+func (e *Endpoint) Handle(b []byte) {
+	var packet dmxPacket
+	var ok bool
+	if e.config.Protocol == SACN {
+		packet, ok = parseSACN(b)
+	} else {
+		packet, ok = parseArtNet(b)
+	}
+	if !ok {
+		return
+	}
+
+	e.mu.Lock()
+	first := (packet.universe - e.config.Universe) * PixelsPerUniverse
+	if first < 0 || first >= len(e.pixels) {
+		e.mu.Unlock()
+		return
+	}
+	if packet.terminated {
+		e.mu.Unlock()
+		e.release()
+		return
+	}
+	for i := 0; i+2 < len(packet.data) && first+i/3 < len(e.pixels); i += 3 {
+		e.pixels[first+i/3] = color.RGBA{R: packet.data[i], G: packet.data[i+1], B: packet.data[i+2], A: 255}
+	}
+	e.packets++
+	e.lastData = time.Now()
+	takeOver := !e.live
+	e.live = true
+	e.mu.Unlock()
+
+	if takeOver {
+		e.takeOver()
+	}
+}
+
+// IsLive returns whether the network is driving the strip
+func (e *Endpoint) IsLive() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.live
+}
+
+// Packets returns the number of packets shown since the endpoint was created
+func (e *Endpoint) Packets() uint32 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.packets
+}
+
+// takeOver pauses the panel and replaces any local pattern with the network's pixels
+func (e *Endpoint) takeOver() {
+	var resume patterns.Pattern
+	if e.manager.IsRunning() {
+		resume = e.manager.CurrentPattern()
+	}
+	e.panel.Pause()
+	e.manager.StartPattern(&livePattern{endpoint: e})
+
+	e.mu.Lock()
+	e.resume = resume
+	onLive := e.OnLive
+	e.mu.Unlock()
+
+	if onLive != nil {
+		onLive(true)
+	}
+}
+
+// release hands the strip back to the local pattern the desk interrupted, or to the panel
+func (e *Endpoint) release() {
+	e.mu.Lock()
+	if !e.live {
+		e.mu.Unlock()
+		return
+	}
+	e.live = false
+	resume := e.resume
+	e.resume = nil
+	clear(e.pixels)
+	onLive := e.OnLive
+	e.mu.Unlock()
+
+	if resume != nil {
+		e.manager.StartPattern(resume)
+	} else {
+		e.manager.StopPattern()
+		e.panel.Resume()
+	}
+
+	if onLive != nil {
+		onLive(false)
+	}
+}
+
+// livePattern shows the endpoint's latest pixels
+type livePattern struct {
+	endpoint *Endpoint
+}
+
+func (l *livePattern) Name() string {
+	return "network"
+}
+
+func (l *livePattern) Start(strip peripheral.LedStrip, done <-chan struct{}) error {
+	e := l.endpoint
+	ticker := time.NewTicker(e.config.FrameRate)
+	defer ticker.Stop()
+
+	buf := make([]color.RGBA, strip.NumLEDs())
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-ticker.C:
+			e.mu.Lock()
+			copy(buf, e.pixels)
+			e.mu.Unlock()
+			strip.SetBuffer(buf)
+			strip.ShowIfDirty()
+		}
+	}
+}
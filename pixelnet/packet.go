@@ -0,0 +1,107 @@
+package pixelnet
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Protocol is the network lighting protocol an Endpoint listens for
+type Protocol int
+
+const (
+	ArtNet Protocol = iota // Art-Net ArtDmx on UDP 6454
+	SACN                   // sACN (ANSI E1.31) on UDP 5568
+)
+
+// String returns the protocol's name
+func (p Protocol) String() string {
+	switch p {
+	case ArtNet:
+		return "Art-Net"
+	case SACN:
+		return "sACN"
+	default:
+		return "Unknown"
+	}
+}
+
+// Port returns the protocol's standard UDP port
+func (p Protocol) Port() int {
+	if p == SACN {
+		return 5568
+	}
+	return 6454
+}
+
+// dmxPacket is the DMX data of a universe carried by one network packet
+type dmxPacket struct {
+	universe   int
+	data       []byte // Channel values, channel 1 first
+	terminated bool   // The source has stopped sending, so the universe can be released at once
+}
+
+var artNetID = []byte("Art-Net\x00")
+
+const (
+	artOpDmx        = 0x5000
+	artDmxHeaderLen = 18
+)
+
+// parseArtNet decodes an ArtDmx packet, ignoring every other Art-Net opcode
+func parseArtNet(b []byte) (dmxPacket, bool) {
+	if len(b) < artDmxHeaderLen || !bytes.Equal(b[:8], artNetID) {
+		return dmxPacket{}, false
+	}
+	if binary.LittleEndian.Uint16(b[8:10]) != artOpDmx {
+		return dmxPacket{}, false
+	}
+
+	universe := int(b[15]&0x7f)<<8 | int(b[14]) // Net, then Sub-Net and Universe
+	length := int(binary.BigEndian.Uint16(b[16:18]))
+	data := b[artDmxHeaderLen:]
+	if length < len(data) {
+		data = data[:length]
+	}
+	return dmxPacket{universe: universe, data: data}, true
+}
+
+var acnID = []byte("ASC-E1.17\x00\x00\x00")
+
+const (
+	sacnRootVector    = 0x00000004 // VECTOR_ROOT_E131_DATA
+	sacnFramingVector = 0x00000002 // VECTOR_E131_DATA_PACKET
+	sacnDMPVector     = 0x02       // VECTOR_DMP_SET_PROPERTY
+	sacnHeaderLen     = 126        // Up to and including the DMX start code
+
+	sacnOptionPreview    = 0x80
+	sacnOptionTerminated = 0x40
+)
+
+// parseSACN decodes an E1.31 data packet carrying null start code DMX, ignoring
+// preview data meant only for the desk's visualiser
+func parseSACN(b []byte) (dmxPacket, bool) {
+	if len(b) < sacnHeaderLen || !bytes.Equal(b[4:16], acnID) {
+		return dmxPacket{}, false
+	}
+	if binary.BigEndian.Uint32(b[18:22]) != sacnRootVector ||
+		binary.BigEndian.Uint32(b[40:44]) != sacnFramingVector ||
+		b[117] != sacnDMPVector || b[125] != 0 {
+		return dmxPacket{}, false
+	}
+
+	options := b[112]
+	if options&sacnOptionPreview != 0 {
+		return dmxPacket{}, false
+	}
+
+	count := int(binary.BigEndian.Uint16(b[123:125])) - 1 // Less the start code
+	data := b[sacnHeaderLen:]
+	if count >= 0 && count < len(data) {
+		data = data[:count]
+	}
+	return dmxPacket{
+		universe:   int(binary.BigEndian.Uint16(b[113:115])),
+		data:       data,
+		terminated: options&sacnOptionTerminated != 0,
+	}, true
+}
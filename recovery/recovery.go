@@ -0,0 +1,63 @@
+// Package recovery keeps long-running loops alive when a step panics, reporting
+// the panic instead of freezing the prop. On targets where TinyGo cannot recover
+// from panics the board's watchdog resets it instead.
+package recovery
+
+import (
+	"fmt"
+	"sync"
+)
+
+// PanicError describes a recovered panic
+type PanicError struct {
+	Name  string // Name of the loop that panicked
+	Value any    // Value passed to panic
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%s panicked: %v", e.Name, e.Value)
+}
+
+var (
+	mu      sync.Mutex
+	handler func(err error)
+	panics  int
+)
+
+// SetHandler sets the function told about every recovered panic, e.g. a logger
+func SetHandler(h func(err error)) {
+	mu.Lock()
+	defer mu.Unlock()
+	handler = h
+}
+
+// Call runs fn, returning a *PanicError (and notifying the handler) if it panics
+func Call(name string, fn func()) (err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Name: name, Value: value}
+			report(err)
+		}
+	}()
+	fn()
+	return nil
+}
+
+// Panics returns the number of panics recovered since the board started
+func Panics() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return panics
+}
+
+// report counts the panic and passes err to the handler, if one is set
+func report(err error) {
+	mu.Lock()
+	panics++
+	h := handler
+	mu.Unlock()
+
+	if h != nil {
+		h(err)
+	}
+}
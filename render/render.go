@@ -0,0 +1,132 @@
+// Package render draws level bars into runs of pixels, shared by the panel's
+// battery and airlock sections
+package render
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+)
+
+// Target is a run of pixels a primitive draws into, indexed from its bottom
+type Target interface {
+	Len() int
+	Set(index int, c color.RGBA)
+}
+
+// Buffer is a frame buffer as a Target, e.g. the one a FramePattern renders into
+type Buffer []color.RGBA
+
+// Len returns the number of pixels in the buffer
+func (b Buffer) Len() int {
+	return len(b)
+}
+
+// Set sets a pixel, ignoring out of range indices
+func (b Buffer) Set(index int, c color.RGBA) {
+	if index >= 0 && index < len(b) {
+		b[index] = c
+	}
+}
+
+// Stop is a color at a position (0.0 to 1.0) along a gradient
+type Stop struct {
+	At    float64
+	Color color.RGBA
+}
+
+// Fill sets every pixel of t to c
+func Fill(t Target, c color.RGBA) {
+	for i := 0; i < t.Len(); i++ {
+		t.Set(i, c)
+	}
+}
+
+// Level converts a percentage (0-100) of length pixels into the number of fully
+// lit pixels and the fractional coverage of the next one
+func Level(length int, percentage float64) (whole int, fraction float64) {
+	exact := min(max(float64(length)*percentage/100, 0), float64(length))
+	whole = int(exact)
+	return whole, exact - float64(whole)
+}
+
+// Covered returns the number of pixels a percentage touches, counting a partly covered tip
+func Covered(length int, percentage float64) int {
+	return min(max(int(math.Ceil(float64(length)*percentage/100)), 0), length)
+}
+
+// Bar lights the bottom percentage of t in c, with the tip pixel dimmed by how
+// much of it the level covers. It returns the fully lit pixels and the tip coverage.
+func Bar(t Target, percentage float64, c color.RGBA) (whole int, fraction float64) {
+	whole, fraction = Level(t.Len(), percentage)
+	for i := 0; i < whole; i++ {
+		t.Set(i, c)
+	}
+	if whole < t.Len() {
+		t.Set(whole, colorutil.Scale(c, fraction))
+	}
+	return whole, fraction
+}
+
+// CenteredBar lights percentage of t in c growing out from its middle towards
+// both ends, with anti-aliased tips
+func CenteredBar(t Target, percentage float64, c color.RGBA) {
+	length := t.Len()
+	center := float64(length) / 2
+	reach := min(max(float64(length)*percentage/100, 0), float64(length)) / 2
+
+	for i := 0; i < length; i++ {
+		// How much of the pixel lies within reach of the center
+		inner := math.Abs(float64(i)+0.5-center) - 0.5
+		coverage := min(max(reach-inner, 0), 1)
+		switch {
+		case coverage >= 1:
+			t.Set(i, c)
+		case coverage > 0:
+			t.Set(i, colorutil.Scale(c, coverage))
+		}
+	}
+}
+
+// GradientBar lights the bottom percentage of t, coloring each pixel by its
+// position along the whole length, so the bar's tip changes color as it shrinks
+func GradientBar(t Target, percentage float64, stops []Stop) (whole int, fraction float64) {
+	length := t.Len()
+	whole, fraction = Level(length, percentage)
+	for i := 0; i < whole; i++ {
+		t.Set(i, Gradient(stops, position(i, length)))
+	}
+	if whole < length {
+		t.Set(whole, colorutil.Scale(Gradient(stops, position(whole, length)), fraction))
+	}
+	return whole, fraction
+}
+
+// Gradient returns the color at position (0.0 to 1.0) between stops sorted by At
+func Gradient(stops []Stop, at float64) color.RGBA {
+	if len(stops) == 0 {
+		return color.RGBA{A: 255}
+	}
+	if at <= stops[0].At {
+		return stops[0].Color
+	}
+	for i := 1; i < len(stops); i++ {
+		if at <= stops[i].At {
+			span := stops[i].At - stops[i-1].At
+			if span <= 0 {
+				return stops[i].Color
+			}
+			return colorutil.Lerp(stops[i-1].Color, stops[i].Color, (at-stops[i-1].At)/span)
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+// position is the center of pixel i along length pixels, 0.0 to 1.0
+func position(i, length int) float64 {
+	if length <= 1 {
+		return 0
+	}
+	return float64(i) / float64(length-1)
+}
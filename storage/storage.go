@@ -0,0 +1,268 @@
+// Package storage persists battery levels and states to non-volatile memory so a
+// show can resume where it left off after a power cycle
+package storage
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// magic identifies a saved snapshot, the last byte is the format version
+var magic = [4]byte{'T', 'S', 'W', 2}
+
+// rotationBlocks is how many erase blocks snapshots rotate through, spreading
+// flash wear across them; the device needs at least this many
+const rotationBlocks = 4
+
+// headerSize is the magic, sequence number and battery count at the start of a snapshot
+const headerSize = 10
+
+var (
+	ErrNoSnapshot = errors.New("storage: no saved snapshot")
+	ErrCorrupt    = errors.New("storage: snapshot checksum mismatch")
+)
+
+// BlockDevice is the non-volatile memory snapshots are written to.
+// machine.Flash satisfies it on boards with a flash data area; an I2C FRAM
+// wrapper can be used instead where flash wear is a concern. Snapshots rotate
+// across its first rotationBlocks erase blocks.
+type BlockDevice interface {
+	ReadAt(p []byte, off int64) (n int, err error)
+	WriteAt(p []byte, off int64) (n int, err error)
+	EraseBlockSize() int64
+	EraseBlocks(start, length int64) error
+}
+
+// BatterySnapshot is the saved state of a single battery
+type BatterySnapshot struct {
+	Level float32
+	State battery.SystemState
+}
+
+// Store periodically saves battery state to a BlockDevice. Each save goes to the
+// block after the previous one, numbered so the newest is found on load.
+type Store struct {
+	mu        sync.Mutex
+	device    BlockDevice
+	batteries []*battery.Battery
+	last      []BatterySnapshot // Last snapshot written, to avoid needless flash wear
+	located   bool              // Whether block and sequence have been read from the device
+	block     int               // Block holding the newest snapshot, -1 if none
+	sequence  uint32            // Sequence number of the newest snapshot
+
+	// Ticker for periodic saves
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewStore creates a store that saves the given batteries to device
+func NewStore(device BlockDevice, batteries []*battery.Battery) *Store {
+	return &Store{
+		device:     device,
+		batteries:  batteries,
+		stopTicker: make(chan struct{}),
+	}
+}
+
+// Restore loads the saved snapshot and applies it to the batteries.
+// Returns ErrNoSnapshot if nothing has been saved for this battery count yet.
+func (s *Store) Restore() error {
+	snapshots, err := s.Load()
+	if err != nil {
+		return err
+	}
+	if len(snapshots) != len(s.batteries) {
+		return ErrNoSnapshot
+	}
+
+	for i, snapshot := range snapshots {
+		s.batteries[i].Restore(snapshot.Level, snapshot.State)
+	}
+
+	s.mu.Lock()
+	s.last = snapshots
+	s.mu.Unlock()
+	return nil
+}
+
+// Load reads the newest saved snapshot from the device
+func (s *Store) Load() ([]BatterySnapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshots, err := s.locate()
+	return snapshots, err
+}
+
+// locate scans the rotation blocks for the newest valid snapshot, remembering
+// where it is for the next save. A corrupt block, e.g. from power lost mid-write,
+// is skipped in favour of an older one. (must be called with mutex locked)
+func (s *Store) locate() ([]BatterySnapshot, error) {
+	var newest []BatterySnapshot
+	err := ErrNoSnapshot
+	s.block, s.sequence = -1, 0
+	for block := 0; block < rotationBlocks; block++ {
+		snapshots, sequence, readErr := s.readBlock(block)
+		if readErr != nil {
+			if s.block < 0 && !errors.Is(readErr, ErrNoSnapshot) {
+				err = readErr
+			}
+			continue
+		}
+		// Sequence numbers compare modulo 2^32 so wrapping around keeps working
+		if s.block < 0 || int32(sequence-s.sequence) > 0 {
+			newest, s.block, s.sequence, err = snapshots, block, sequence, nil
+		}
+	}
+	s.located = true
+	return newest, err
+}
+
+// readBlock reads the snapshot in one rotation block
+func (s *Store) readBlock(block int) ([]BatterySnapshot, uint32, error) {
+	offset := int64(block) * s.device.EraseBlockSize()
+
+	header := make([]byte, headerSize)
+	if _, err := s.device.ReadAt(header, offset); err != nil {
+		return nil, 0, err
+	}
+	if [4]byte(header[:4]) != magic {
+		return nil, 0, ErrNoSnapshot
+	}
+
+	sequence := binary.LittleEndian.Uint32(header[4:8])
+	count := int(binary.LittleEndian.Uint16(header[8:10]))
+	size := int64(headerSize + count*5 + 4)
+	if size > s.device.EraseBlockSize() {
+		return nil, 0, ErrCorrupt
+	}
+	data := make([]byte, size)
+	if _, err := s.device.ReadAt(data, offset); err != nil {
+		return nil, 0, err
+	}
+
+	body := data[:len(data)-4]
+	if crc32.ChecksumIEEE(body) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return nil, 0, ErrCorrupt
+	}
+
+	snapshots := make([]BatterySnapshot, count)
+	for i := range snapshots {
+		entry := body[headerSize+i*5:]
+		snapshots[i] = BatterySnapshot{
+			Level: math.Float32frombits(binary.LittleEndian.Uint32(entry[0:4])),
+			State: battery.SystemState(entry[4]),
+		}
+	}
+	return snapshots, sequence, nil
+}
+
+// Save writes the current battery state to the next rotation block, if it changed
+// noticeably since the last save
+func (s *Store) Save() error {
+	snapshots := make([]BatterySnapshot, len(s.batteries))
+	for i, bat := range s.batteries {
+		info := bat.GetInfo()
+		snapshots[i] = BatterySnapshot{Level: info.BatteryLevel, State: info.State}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !changed(s.last, snapshots) {
+		return nil
+	}
+
+	if !s.located {
+		s.locate()
+	}
+	block := (s.block + 1) % rotationBlocks
+	sequence := s.sequence + 1
+
+	// Layout: magic, sequence, count, (level, state) per battery, CRC32
+	data := make([]byte, headerSize, headerSize+len(snapshots)*5+4)
+	copy(data, magic[:])
+	binary.LittleEndian.PutUint32(data[4:8], sequence)
+	binary.LittleEndian.PutUint16(data[8:10], uint16(len(snapshots)))
+	for _, snapshot := range snapshots {
+		data = binary.LittleEndian.AppendUint32(data, math.Float32bits(snapshot.Level))
+		data = append(data, byte(snapshot.State))
+	}
+	data = binary.LittleEndian.AppendUint32(data, crc32.ChecksumIEEE(data))
+
+	// The previous block stays intact until the next save, so losing power
+	// mid-write falls back to it
+	if err := s.device.EraseBlocks(int64(block), 1); err != nil {
+		return err
+	}
+	if _, err := s.device.WriteAt(data, int64(block)*s.device.EraseBlockSize()); err != nil {
+		return err
+	}
+
+	s.block, s.sequence = block, sequence
+	s.last = snapshots
+	return nil
+}
+
+// Start begins saving battery state at the given interval
+func (s *Store) Start(interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		return
+	}
+
+	s.running = true
+	s.ticker = time.NewTicker(interval)
+	s.stopTicker = make(chan struct{})
+	ticker, stop := s.ticker, s.stopTicker
+
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				s.Save()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic saving
+func (s *Store) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.running {
+		close(s.stopTicker)
+		if s.ticker != nil {
+			s.ticker.Stop()
+		}
+		s.running = false
+	}
+}
+
+// changed returns true if a state changed or a level moved by at least 1%
+func changed(previous, current []BatterySnapshot) bool {
+	if len(previous) != len(current) {
+		return true
+	}
+	for i := range current {
+		if previous[i].State != current[i].State {
+			return true
+		}
+		if math.Abs(float64(previous[i].Level-current[i].Level)) >= 1 {
+			return true
+		}
+	}
+	return false
+}
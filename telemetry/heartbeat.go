@@ -0,0 +1,172 @@
+package telemetry
+
+import (
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/recovery"
+)
+
+// Health is the prop's health at one heartbeat. Room control alerts the game
+// master if heartbeats stop arriving or PanelAlive goes false.
+//
+// As a line on the serial port:
+//
+//	{"heartbeat":42,"uptime":210.0,"reset":"power-on","heapFree":40960,"goroutines":14,"panics":0,"panelAlive":true}
+type Health struct {
+	Seq        uint32        // Counts up from 1 at boot, so a supervisor can spot missed or restarted beats
+	Uptime     time.Duration // Time since the panel started
+	ResetCause string        // Why the board last reset, e.g. "watchdog"
+	HeapFree   uint64        // Free heap in bytes
+	Goroutines int           // Running goroutines
+	Panics     int           // Panics recovered since boot
+	PanelAlive bool          // The panel's update loop ticked since the previous heartbeat, even if paused
+}
+
+// AppendJSON appends h as a one-line JSON object, without a line ending
+func (h Health) AppendJSON(buf []byte) []byte {
+	buf = append(buf, `{"heartbeat":`...)
+	buf = strconv.AppendUint(buf, uint64(h.Seq), 10)
+	buf = append(buf, `,"uptime":`...)
+	buf = strconv.AppendFloat(buf, h.Uptime.Seconds(), 'f', 1, 64)
+	buf = append(buf, `,"reset":`...)
+	buf = strconv.AppendQuote(buf, h.ResetCause)
+	buf = append(buf, `,"heapFree":`...)
+	buf = strconv.AppendUint(buf, h.HeapFree, 10)
+	buf = append(buf, `,"goroutines":`...)
+	buf = strconv.AppendInt(buf, int64(h.Goroutines), 10)
+	buf = append(buf, `,"panics":`...)
+	buf = strconv.AppendInt(buf, int64(h.Panics), 10)
+	buf = append(buf, `,"panelAlive":`...)
+	buf = strconv.AppendBool(buf, h.PanelAlive)
+	return append(buf, '}')
+}
+
+// HeartbeatConfig configures a Heartbeat
+type HeartbeatConfig struct {
+	Interval   time.Duration // Time between heartbeats
+	ResetCause string        // Reported in every heartbeat, e.g. peripheral.ResetCause()
+}
+
+// DefaultHeartbeatConfig returns a heartbeat every five seconds
+func DefaultHeartbeatConfig(resetCause string) HeartbeatConfig {
+	return HeartbeatConfig{
+		Interval:   5 * time.Second,
+		ResetCause: resetCause,
+	}
+}
+
+// Heartbeat periodically samples the prop's health and hands it to its publishers
+type Heartbeat struct {
+	mu         sync.Mutex
+	source     Source
+	config     HeartbeatConfig
+	seq        uint32
+	lastTicks  uint32
+	publishers []func(Health)
+
+	// Ticker for heartbeats
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewHeartbeat creates a heartbeat reporting on source
+func NewHeartbeat(source Source, config HeartbeatConfig) *Heartbeat {
+	if config.Interval <= 0 {
+		config.Interval = DefaultHeartbeatConfig(config.ResetCause).Interval
+	}
+	return &Heartbeat{
+		source:    source,
+		config:    config,
+		lastTicks: source.TickCount(),
+	}
+}
+
+// OnBeat registers a publisher called with every heartbeat, e.g. WriteHeartbeat
+// for the serial port or an MQTT bridge's PublishHeartbeat
+func (h *Heartbeat) OnBeat(publish func(Health)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publishers = append(h.publishers, publish)
+}
+
+// Start begins sending heartbeats at the configured interval
+func (h *Heartbeat) Start() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		return
+	}
+	h.running = true
+	h.ticker = time.NewTicker(h.config.Interval)
+	h.stopTicker = make(chan struct{})
+
+	ticker, stop := h.ticker, h.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				h.Beat()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops sending heartbeats
+func (h *Heartbeat) Stop() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.running {
+		close(h.stopTicker)
+		h.ticker.Stop()
+		h.running = false
+	}
+}
+
+// Beat samples the prop's health now and publishes it
+func (h *Heartbeat) Beat() Health {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	ticks := h.source.TickCount()
+
+	h.mu.Lock()
+	h.seq++
+	health := Health{
+		Seq:        h.seq,
+		Uptime:     h.source.Uptime(),
+		ResetCause: h.config.ResetCause,
+		HeapFree:   mem.HeapSys - mem.HeapInuse,
+		Goroutines: runtime.NumGoroutine(),
+		Panics:     recovery.Panics(),
+		PanelAlive: ticks != h.lastTicks,
+	}
+	h.lastTicks = ticks
+	publishers := make([]func(Health), len(h.publishers))
+	copy(publishers, h.publishers)
+	h.mu.Unlock()
+
+	for _, publish := range publishers {
+		publish(health)
+	}
+	return health
+}
+
+// WriteHeartbeat returns a publisher writing each heartbeat to out as a JSON line
+func WriteHeartbeat(out io.Writer) func(Health) {
+	var mu sync.Mutex
+	buf := make([]byte, 0, 160)
+	return func(health Health) {
+		mu.Lock()
+		defer mu.Unlock()
+		buf = append(health.AppendJSON(buf[:0]), "\r\n"...)
+		out.Write(buf)
+	}
+}
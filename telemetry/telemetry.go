@@ -0,0 +1,146 @@
+// Package telemetry periodically writes a one-line JSON snapshot of the show
+// state, so a laptop on the USB serial port can chart it live. Lines start with
+// '{' so they can be picked out from console and log output on the same port.
+//
+// Example line:
+//
+//	{"uptime":12.3,"fps":19.9,"heapFree":40960,"batteries":[{"state":"Draining","level":42.0,"draining":true,"eta":272}]}
+//
+// eta is the seconds until a draining battery is empty or a charging one is full.
+package telemetry
+
+import (
+	"io"
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/battery"
+)
+
+// Source is what telemetry reports on; *panel.Panel satisfies it
+type Source interface {
+	GetAllBatteryInfo() []battery.BatteryInfo
+	Uptime() time.Duration
+	FrameCount() uint32
+	TickCount() uint32
+}
+
+// Reporter writes telemetry snapshots to an output at a fixed interval
+type Reporter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	source Source
+	buf    []byte // Reused between snapshots to avoid allocations
+
+	// Frame rate measurement
+	lastFrames uint32
+	lastAt     time.Time
+
+	// Ticker for reports
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewReporter creates a reporter writing snapshots of source to out
+func NewReporter(out io.Writer, source Source) *Reporter {
+	return &Reporter{
+		out:        out,
+		source:     source,
+		buf:        make([]byte, 0, 512),
+		lastFrames: source.FrameCount(),
+		lastAt:     time.Now(),
+	}
+}
+
+// Start begins writing a snapshot every interval
+func (r *Reporter) Start(interval time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+
+	r.running = true
+	r.ticker = time.NewTicker(interval)
+	r.stopTicker = make(chan struct{})
+
+	ticker, stop := r.ticker, r.stopTicker
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				r.Report()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the periodic reports
+func (r *Reporter) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		close(r.stopTicker)
+		if r.ticker != nil {
+			r.ticker.Stop()
+		}
+		r.running = false
+	}
+}
+
+// Report writes one snapshot now
+func (r *Reporter) Report() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.buf = r.appendSnapshot(r.buf[:0])
+	_, err := r.out.Write(r.buf)
+	return err
+}
+
+// appendSnapshot appends a JSON snapshot line to buf (must be called with mutex locked)
+func (r *Reporter) appendSnapshot(buf []byte) []byte {
+	now := time.Now()
+	frames := r.source.FrameCount()
+	fps := 0.0
+	if elapsed := now.Sub(r.lastAt).Seconds(); elapsed > 0 {
+		fps = float64(frames-r.lastFrames) / elapsed
+	}
+	r.lastFrames, r.lastAt = frames, now
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	buf = append(buf, `{"uptime":`...)
+	buf = strconv.AppendFloat(buf, r.source.Uptime().Seconds(), 'f', 1, 64)
+	buf = append(buf, `,"fps":`...)
+	buf = strconv.AppendFloat(buf, fps, 'f', 1, 64)
+	buf = append(buf, `,"heapFree":`...)
+	buf = strconv.AppendUint(buf, mem.HeapSys-mem.HeapInuse, 10)
+	buf = append(buf, `,"batteries":[`...)
+	for i, info := range r.source.GetAllBatteryInfo() {
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, `{"state":`...)
+		buf = strconv.AppendQuote(buf, info.State.String())
+		buf = append(buf, `,"level":`...)
+		buf = strconv.AppendFloat(buf, float64(info.BatteryLevel), 'f', 1, 32)
+		buf = append(buf, `,"draining":`...)
+		buf = strconv.AppendBool(buf, info.IsDraining)
+		if eta, ok := info.ETA(); ok {
+			buf = append(buf, `,"eta":`...)
+			buf = strconv.AppendFloat(buf, eta.Seconds(), 'f', 0, 64)
+		}
+		buf = append(buf, '}')
+	}
+	buf = append(buf, "]}\r\n"...)
+	return buf
+}
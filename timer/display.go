@@ -0,0 +1,126 @@
+package timer
+
+import (
+	"image/color"
+	"sync"
+	"time"
+
+	"github.com/christophergm/tinyspacewalk/colorutil"
+	"github.com/christophergm/tinyspacewalk/peripheral"
+	"github.com/christophergm/tinyspacewalk/render"
+)
+
+// Clock colors, matching the panel's so a reserved segment matches the battery sections
+var (
+	clockGreen  = color.RGBA{G: 255, A: 255}
+	clockYellow = color.RGBA{R: 255, G: 255, A: 255}
+	clockRed    = color.RGBA{R: 255, A: 255}
+	clockOff    = color.RGBA{A: 255}
+)
+
+// clockStops shift the bar from green through yellow to red as time runs out
+var clockStops = []render.Stop{
+	{At: 0, Color: clockRed},
+	{At: 0.25, Color: clockYellow},
+	{At: 0.5, Color: clockGreen},
+}
+
+// Draw shows the time left on t as a bar that empties towards its first pixel,
+// turning from green to red as it runs low. A paused clock is drawn at half
+// brightness and an expired one flashes red while flash is true.
+func Draw(t render.Target, info Info, flash bool) {
+	render.Fill(t, clockOff)
+
+	switch info.State {
+	case Expired:
+		if flash {
+			render.Fill(t, clockRed)
+		}
+	case Paused:
+		render.Bar(t, info.Fraction()*100, colorutil.Scale(render.Gradient(clockStops, info.Fraction()), 0.5))
+	default:
+		render.Bar(t, info.Fraction()*100, render.Gradient(clockStops, info.Fraction()))
+	}
+}
+
+// stripTarget draws on a whole strip, e.g. a dedicated LED ring
+type stripTarget struct {
+	strip peripheral.LedStrip
+}
+
+func (s stripTarget) Len() int {
+	return s.strip.NumLEDs()
+}
+
+func (s stripTarget) Set(index int, c color.RGBA) {
+	s.strip.SetPixel(index, c)
+}
+
+// Ring shows a timer on a dedicated LED ring, sweeping round from its first
+// pixel. For a segment of a strip the panel drives, use PanelConfig.Timer instead.
+type Ring struct {
+	mu    sync.Mutex
+	timer *Timer
+	strip peripheral.LedStrip
+
+	// Ticker for refreshing
+	ticker     *time.Ticker
+	stopTicker chan struct{}
+	running    bool
+}
+
+// NewRing creates a ring showing timer on strip
+func NewRing(timer *Timer, strip peripheral.LedStrip) *Ring {
+	return &Ring{
+		timer: timer,
+		strip: strip,
+	}
+}
+
+// Start begins redrawing the ring at the given rate
+func (r *Ring) Start(refreshRate time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		return
+	}
+	r.running = true
+	r.ticker = time.NewTicker(refreshRate)
+	r.stopTicker = make(chan struct{})
+
+	ticker, stop := r.ticker, r.stopTicker
+	go func() {
+		for {
+			select {
+			case now := <-ticker.C:
+				r.Draw(now)
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops refreshing and turns the ring off
+func (r *Ring) Stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.running {
+		close(r.stopTicker)
+		r.ticker.Stop()
+		r.running = false
+		r.strip.Clear()
+		r.strip.Show()
+	}
+}
+
+// Draw redraws the ring for now, flashing twice a second once expired
+func (r *Ring) Draw(now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	Draw(stripTarget{r.strip}, r.timer.GetInfo(), now.UnixMilli()%1000 < 500)
+	r.strip.ShowIfDirty()
+}
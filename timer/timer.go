@@ -0,0 +1,334 @@
+// Package timer is the mission clock: a countdown that can be started, paused,
+// given extra time and reset, announcing each change and its expiry to subscribers
+package timer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is where the countdown is
+type State int
+
+const (
+	Stopped State = iota // Not started, showing the full duration
+	Running
+	Paused
+	Expired // Reached zero, stays here until started or reset
+)
+
+// String returns a string representation of the State
+func (s State) String() string {
+	switch s {
+	case Stopped:
+		return "Stopped"
+	case Running:
+		return "Running"
+	case Paused:
+		return "Paused"
+	case Expired:
+		return "Expired"
+	default:
+		return "Unknown"
+	}
+}
+
+// EventKind is what happened to the countdown
+type EventKind int
+
+const (
+	Started EventKind = iota
+	PausedEvent
+	Resumed
+	TimeAdded // Time was added or taken away
+	ExpiredEvent
+	ResetEvent
+)
+
+// String returns a string representation of the EventKind
+func (k EventKind) String() string {
+	switch k {
+	case Started:
+		return "started"
+	case PausedEvent:
+		return "paused"
+	case Resumed:
+		return "resumed"
+	case TimeAdded:
+		return "time added"
+	case ExpiredEvent:
+		return "expired"
+	case ResetEvent:
+		return "reset"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change to the countdown and the time left after it
+type Event struct {
+	Kind      EventKind
+	Remaining time.Duration
+	Added     time.Duration // Time added (negative when taken away), for TimeAdded
+}
+
+// Config holds configuration parameters for the timer
+type Config struct {
+	Duration time.Duration // Time on the clock when started or reset
+}
+
+// DefaultConfig returns an hour on the clock, the usual escape room session
+func DefaultConfig() Config {
+	return Config{
+		Duration: time.Hour,
+	}
+}
+
+// Info holds the current timer properties
+type Info struct {
+	State     State
+	Remaining time.Duration
+	Duration  time.Duration // Time on the clock at the start, for drawing the remaining fraction
+}
+
+// Fraction returns the time left as 0.0 to 1.0 of the duration
+func (i Info) Fraction() float64 {
+	if i.Duration <= 0 {
+		return 0
+	}
+	return min(max(float64(i.Remaining)/float64(i.Duration), 0), 1)
+}
+
+// String returns the remaining time as minutes and seconds, e.g. "42:07"
+func (i Info) String() string {
+	return FormatRemaining(i.Remaining)
+}
+
+// FormatRemaining formats a time left as minutes and seconds, rounding up so
+// the clock only reads 00:00 once expired
+func FormatRemaining(d time.Duration) string {
+	seconds := int((max(d, 0) + time.Second - 1) / time.Second)
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// Timer is the countdown state machine
+type Timer struct {
+	mu       sync.Mutex
+	duration time.Duration
+	state    State
+
+	// Time left when not running; while running it is measured to deadline
+	remaining time.Duration
+	deadline  time.Time
+
+	// Expiry check for the current run, replaced whenever the deadline moves
+	expiry     *time.Timer
+	generation int
+
+	// Subscribers, called and sent to outside the lock
+	channels  []chan<- Event
+	callbacks []func(Event)
+}
+
+// NewTimer creates a stopped timer with the configured duration on the clock
+func NewTimer(config Config) *Timer {
+	if config.Duration < 0 {
+		config.Duration = 0
+	}
+	return &Timer{
+		duration:  config.Duration,
+		remaining: config.Duration,
+	}
+}
+
+// Start runs the countdown, from the full duration when stopped or expired
+// and from where it was left when paused
+func (t *Timer) Start() {
+	t.mu.Lock()
+	kind := Started
+	switch t.state {
+	case Running:
+		t.mu.Unlock()
+		return
+	case Paused:
+		kind = Resumed
+	default:
+		t.remaining = t.duration
+	}
+	t.run(time.Now())
+	event := Event{Kind: kind, Remaining: t.remaining}
+	t.mu.Unlock()
+
+	t.notify(event)
+}
+
+// Pause freezes the countdown
+func (t *Timer) Pause() {
+	t.mu.Lock()
+	if t.state != Running {
+		t.mu.Unlock()
+		return
+	}
+	t.remaining = t.remainingAt(time.Now())
+	t.state = Paused
+	t.cancelExpiry()
+	event := Event{Kind: PausedEvent, Remaining: t.remaining}
+	t.mu.Unlock()
+
+	t.notify(event)
+}
+
+// Resume continues a paused countdown
+func (t *Timer) Resume() {
+	t.mu.Lock()
+	if t.state != Paused {
+		t.mu.Unlock()
+		return
+	}
+	t.run(time.Now())
+	event := Event{Kind: Resumed, Remaining: t.remaining}
+	t.mu.Unlock()
+
+	t.notify(event)
+}
+
+// AddTime puts d more on the clock, or takes it away when negative. Taking away
+// more than is left expires a running countdown. The added time counts towards
+// the duration when it takes the clock past it, so a bar drawn from Fraction is full again.
+func (t *Timer) AddTime(d time.Duration) {
+	t.mu.Lock()
+	now := time.Now()
+	t.remaining = max(t.remainingAt(now)+d, 0)
+	t.duration = max(t.duration, t.remaining)
+	if t.state == Running {
+		t.run(now)
+	}
+	event := Event{Kind: TimeAdded, Remaining: t.remaining, Added: d}
+	t.mu.Unlock()
+
+	t.notify(event)
+}
+
+// Reset stops the countdown and puts duration on the clock; 0 keeps the current duration
+func (t *Timer) Reset(duration time.Duration) {
+	t.mu.Lock()
+	if duration > 0 {
+		t.duration = duration
+	}
+	t.cancelExpiry()
+	t.state = Stopped
+	t.remaining = t.duration
+	event := Event{Kind: ResetEvent, Remaining: t.remaining}
+	t.mu.Unlock()
+
+	t.notify(event)
+}
+
+// Remaining returns the time left on the clock
+func (t *Timer) Remaining() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.remainingAt(time.Now())
+}
+
+// State returns where the countdown is
+func (t *Timer) State() State {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.state
+}
+
+// GetInfo returns a summary of the current timer state
+func (t *Timer) GetInfo() Info {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Info{
+		State:     t.state,
+		Remaining: t.remainingAt(time.Now()),
+		Duration:  t.duration,
+	}
+}
+
+// Subscribe registers a channel that receives every event.
+// Sends never block the timer; events are dropped if the channel is full,
+// so use a buffered channel.
+func (t *Timer) Subscribe(ch chan<- Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.channels = append(t.channels, ch)
+}
+
+// OnEvent registers a callback run for every event. Callbacks run after the
+// timer's lock is released, so they may call back into the timer.
+func (t *Timer) OnEvent(callback func(Event)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.callbacks = append(t.callbacks, callback)
+}
+
+// remainingAt returns the time left at now (must be called with mutex locked)
+func (t *Timer) remainingAt(now time.Time) time.Duration {
+	if t.state != Running {
+		return t.remaining
+	}
+	return max(t.deadline.Sub(now), 0)
+}
+
+// run starts counting down t.remaining from now and schedules the expiry check
+// (must be called with mutex locked)
+func (t *Timer) run(now time.Time) {
+	t.cancelExpiry()
+	t.state = Running
+	t.deadline = now.Add(t.remaining)
+
+	generation := t.generation
+	t.expiry = time.AfterFunc(t.remaining, func() {
+		t.expire(generation)
+	})
+}
+
+// cancelExpiry stops the scheduled expiry check (must be called with mutex locked)
+func (t *Timer) cancelExpiry() {
+	t.generation++
+	if t.expiry != nil {
+		t.expiry.Stop()
+		t.expiry = nil
+	}
+}
+
+// expire moves a running countdown to Expired, ignoring checks scheduled
+// before the deadline last moved
+func (t *Timer) expire(generation int) {
+	t.mu.Lock()
+	if generation != t.generation || t.state != Running {
+		t.mu.Unlock()
+		return
+	}
+	t.expiry = nil
+	t.state = Expired
+	t.remaining = 0
+	t.mu.Unlock()
+
+	t.notify(Event{Kind: ExpiredEvent})
+}
+
+// notify delivers an event to all subscribers, must be called without the mutex held
+func (t *Timer) notify(event Event) {
+	t.mu.Lock()
+	channels := append([]chan<- Event(nil), t.channels...)
+	callbacks := make([]func(Event), len(t.callbacks))
+	copy(callbacks, t.callbacks)
+	t.mu.Unlock()
+
+	for _, ch := range channels {
+		select {
+		case ch <- event:
+		default:
+			// Subscriber isn't keeping up, drop the event
+		}
+	}
+	for _, callback := range callbacks {
+		callback(event)
+	}
+}